@@ -0,0 +1,265 @@
+package gotext
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// Version of the cache format written by SaveCache.
+const cacheVersion = 1
+
+// poCache is the structure encoded on caches.
+type poCache struct {
+	Version     int
+	Fingerprint string
+	Collapse    bool
+	Fuzzy       bool
+	Entries     []*Translation
+	Obsolete    []*Translation
+
+	// Duplicate entries reported by CheckPluralConsistency.
+	PluralConflicts []Warning
+}
+
+// SourceFingerprint returns the fingerprint of the given PO formatted content (str),
+// matching the Fingerprint of a Po object that parsed it.
+func SourceFingerprint(str string) string {
+	sum := sha256.Sum256([]byte(str))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Fingerprint identifies the content parsed by this Po object, so it can be compared with SourceFingerprint
+// to know if a cache is stale. Parsing more content changes the fingerprint.
+// It's empty for Po objects that haven't parsed anything.
+func (po *Po) Fingerprint() string {
+	po.RLock()
+	defer po.RUnlock()
+
+	return po.fingerprint
+}
+
+/*
+SaveCache writes the parsed catalog to w in a compact binary format (encoding/gob),
+to be loaded with LoadCache on subsequent runs instead of parsing the PO content again.
+
+The cache keeps every entry, in its parsed order, the obsolete entries, the duplicate entries reported by CheckPluralConsistency,
+the whitespace normalization and fuzzy entries settings and the Fingerprint of the parsed content, used to detect stale caches:
+
+    data, _ := ioutil.ReadFile("default.po")
+
+    po, err := gotext.LoadCache(cache)
+    if err != nil || po.Fingerprint() != gotext.SourceFingerprint(string(data)) {
+        // Parse and save a new cache
+        po = new(gotext.Po)
+        po.Parse(string(data))
+        po.SaveCache(newCache)
+    }
+
+The load warning handler isn't saved.
+*/
+func (po *Po) SaveCache(w io.Writer) error {
+	entries := po.snapshot()
+
+	c := poCache{
+		Version: cacheVersion,
+		Entries: make([]*Translation, 0, len(entries)),
+	}
+
+	po.RLock()
+	c.Fingerprint = po.fingerprint
+	c.Collapse = po.collapse
+	c.Fuzzy = po.fuzzy
+	c.PluralConflicts = append([]Warning(nil), po.pluralConflicts...)
+	po.RUnlock()
+
+	for _, k := range seqOrder(entries) {
+		c.Entries = append(c.Entries, entries[k])
+	}
+	c.Obsolete = po.Obsolete()
+
+	return gob.NewEncoder(w).Encode(c)
+}
+
+// LoadCache reads a catalog written by SaveCache from r.
+// It returns an error if the cache can't be decoded or it was written by an incompatible version.
+func LoadCache(r io.Reader) (*Po, error) {
+	var c poCache
+	if err := gob.NewDecoder(r).Decode(&c); err != nil {
+		return nil, err
+	}
+
+	if c.Version != cacheVersion {
+		return nil, fmt.Errorf("unsupported cache version %d", c.Version)
+	}
+
+	po := new(Po)
+	po.collapse = c.Collapse
+	po.fuzzy = c.Fuzzy
+	po.fingerprint = c.Fingerprint
+	po.pluralConflicts = c.PluralConflicts
+	po.init()
+
+	for _, t := range c.Entries {
+		if t.Trs == nil {
+			t.Trs = make(map[int]string)
+		}
+		po.store(t.Context, t)
+	}
+
+	for _, t := range c.Obsolete {
+		if t.Trs == nil {
+			t.Trs = make(map[int]string)
+		}
+		po.obsolete = append(po.obsolete, t)
+	}
+
+	return po, nil
+}
+
+// MarshalBinary returns the parsed catalog in the binary format written by SaveCache,
+// so it can be used wherever an encoding.BinaryMarshaler is accepted.
+func (po *Po) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := po.SaveCache(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary replaces the content of this catalog with the one written by MarshalBinary or SaveCache (data),
+// like LoadCache, so it can be used wherever an encoding.BinaryUnmarshaler is accepted.
+// The content is swapped in a single step, and it's left unchanged when the cache can't be loaded.
+// The load warning handler and the default context of this catalog are kept.
+func (po *Po) UnmarshalBinary(data []byte) error {
+	cached, err := LoadCache(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	po.Lock()
+	defer po.Unlock()
+
+	po.translations = cached.translations
+	po.contexts = cached.contexts
+	po.collapse = cached.collapse
+	po.seq = cached.seq
+	po.fingerprint = cached.fingerprint
+	po.obsolete = cached.obsolete
+	po.pluralConflicts = cached.pluralConflicts
+	po.plural = cached.plural
+	po.fuzzy = cached.fuzzy
+	po.changed()
+
+	return nil
+}
+
+// localeCache is the structure encoded on Locale caches.
+type localeCache struct {
+	Version int
+	Lang    string
+	Domain  string
+
+	// Domain caches written by Po.MarshalBinary, and the files they were loaded from.
+	Domains map[string][]byte
+	Files   map[string]string
+}
+
+/*
+MarshalBinary returns every domain loaded on this Locale in a compact binary format, along with its default domain,
+so parsed catalogs can be cached to disk or shipped pre-parsed and loaded with UnmarshalBinary on subsequent runs:
+
+    l := gotext.NewLocale("/path/to/i18n/dir", "es")
+
+    if data, err := ioutil.ReadFile(cache); err != nil || l.UnmarshalBinary(data) != nil {
+        // Parse and save a new cache
+        l.AddAllDomains()
+        data, _ = l.MarshalBinary()
+        ioutil.WriteFile(cache, data, 0644)
+    }
+
+Each domain is written like Po.MarshalBinary. Attached domains are included as well,
+but the fallback chain isn't, so each Locale of the chain needs its own cache.
+Domains registered on lazy mode are loaded first (see Preload), so they're included.
+*/
+func (l *Locale) MarshalBinary() ([]byte, error) {
+	l.preload()
+
+	l.RLock()
+	c := localeCache{
+		Version: cacheVersion,
+		Lang:    l.lang,
+		Domain:  l.domain,
+		Domains: make(map[string][]byte, len(l.domains)),
+		Files:   make(map[string]string, len(l.files)),
+	}
+
+	domains := make(map[string]*Po, len(l.domains))
+	for dom, po := range l.domains {
+		domains[dom] = po
+	}
+	for dom, filename := range l.files {
+		c.Files[dom] = filename
+	}
+	l.RUnlock()
+
+	for dom, po := range domains {
+		if po == nil {
+			continue
+		}
+
+		data, err := po.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		c.Domains[dom] = data
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(c); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary loads the domains and the default domain written by MarshalBinary (data) on this Locale,
+// replacing the existing domains with the same names. The domains loaded from files are still reloaded from them
+// by Reload and Watch. It returns an error without changes if the cache can't be decoded,
+// it was written by an incompatible version or it belongs to another language.
+func (l *Locale) UnmarshalBinary(data []byte) error {
+	var c localeCache
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&c); err != nil {
+		return err
+	}
+
+	if c.Version != cacheVersion {
+		return fmt.Errorf("unsupported cache version %d", c.Version)
+	}
+
+	if c.Lang != l.lang {
+		return fmt.Errorf("cache for language %q can't be loaded on %q", c.Lang, l.lang)
+	}
+
+	// Load every domain before changing this Locale
+	domains := make(map[string]*Po, len(c.Domains))
+	for dom, data := range c.Domains {
+		po, err := LoadCache(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("%s: %s", dom, err.Error())
+		}
+		domains[dom] = po
+	}
+
+	for dom, po := range domains {
+		l.setDomain(dom, c.Files[dom], po)
+	}
+	l.SetDomain(c.Domain)
+
+	return nil
+}
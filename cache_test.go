@@ -0,0 +1,241 @@
+package gotext
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"testing"
+)
+
+func TestPoCache(t *testing.T) {
+	// Set PO content
+	str := `
+msgid ""
+msgstr "Language: es\n"
+
+#, fuzzy
+msgid "My  text"
+msgstr "Mi texto"
+
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Un archivo"
+msgstr[1] "%d archivos"
+
+msgctxt "Ctx"
+msgid "Some random in a context"
+msgstr "Algo aleatorio en un contexto"
+
+msgid "Untranslated"
+msgstr ""
+
+#~ msgid "Old text"
+#~ msgstr "Texto viejo"
+`
+
+	po := new(Po)
+	if po.Fingerprint() != "" {
+		t.Errorf("Expected empty fingerprint but got '%s'", po.Fingerprint())
+	}
+
+	po.SetCollapseWhitespace(true)
+	po.SetFuzzyEnabled(true)
+	po.Parse(str)
+
+	if po.Fingerprint() != SourceFingerprint(str) {
+		t.Errorf("Expected fingerprint '%s' but got '%s'", SourceFingerprint(str), po.Fingerprint())
+	}
+
+	// Save and load cache
+	var buf bytes.Buffer
+	if err := po.SaveCache(&buf); err != nil {
+		t.Fatalf("Unexpected error saving cache: %s", err.Error())
+	}
+
+	cached, err := LoadCache(&buf)
+	if err != nil {
+		t.Fatalf("Unexpected error loading cache: %s", err.Error())
+	}
+
+	if cached.Fingerprint() != po.Fingerprint() {
+		t.Errorf("Expected fingerprint '%s' but got '%s'", po.Fingerprint(), cached.Fingerprint())
+	}
+
+	if d := Diff(po, cached); d != "" {
+		t.Errorf("Expected equivalent catalogs but got:\n%s", d)
+	}
+
+	if obsolete := cached.Obsolete(); len(obsolete) != 1 || obsolete[0].Trs[0] != "Texto viejo" {
+		t.Errorf("Expected obsolete entries to be cached but got %v", obsolete)
+	}
+
+	if tr := cached.Get("My text"); tr != "Mi texto" {
+		t.Errorf("Expected 'Mi texto' but got '%s'", tr)
+	}
+
+	if tr := cached.GetN("One file", "%d files", 5, 5); tr != "5 archivos" {
+		t.Errorf("Expected '5 archivos' but got '%s'", tr)
+	}
+
+	if tr := cached.GetC("Some random in a context", "Ctx"); tr != "Algo aleatorio en un contexto" {
+		t.Errorf("Expected 'Algo aleatorio en un contexto' but got '%s'", tr)
+	}
+
+	if e := cached.GetEntry("My text"); e == nil || !e.HasFlag("fuzzy") || e.Line != 6 {
+		t.Errorf("Expected entry flags and line to be cached but got %v", e)
+	}
+
+	// Entries order
+	var original, loaded bytes.Buffer
+	po.Write(&original, WriteOptions{})
+	cached.Write(&loaded, WriteOptions{})
+
+	if original.String() != loaded.String() {
+		t.Errorf("Expected same written catalog but got:\n%s\n\n%s", original.String(), loaded.String())
+	}
+
+	// Stale cache
+	po.Parse("msgid \"More\"\nmsgstr \"Más\"\n")
+	if po.Fingerprint() == cached.Fingerprint() {
+		t.Error("Expected fingerprint to change after parsing more content")
+	}
+
+	// Broken cache
+	if _, err := LoadCache(bytes.NewBufferString("not a cache")); err == nil {
+		t.Error("Expected error loading a broken cache")
+	}
+
+	// Unsupported version
+	buf.Reset()
+	gob.NewEncoder(&buf).Encode(poCache{Version: cacheVersion + 1})
+
+	if _, err := LoadCache(&buf); err == nil {
+		t.Error("Expected error loading an unsupported cache version")
+	}
+}
+
+func TestPoMarshalBinary(t *testing.T) {
+	// Set PO content
+	str := `
+msgid ""
+msgstr "Plural-Forms: nplurals=3; plural=(n==1 ? 0 : n==2 ? 1 : 2);\n"
+
+msgid "My text"
+msgstr "Mi texto"
+
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Un archivo"
+msgstr[1] "Dos archivos"
+msgstr[2] "%d archivos"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	// Check interfaces
+	var _ encoding.BinaryMarshaler = po
+	var _ encoding.BinaryUnmarshaler = po
+
+	data, err := po.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	// Replace existing content
+	cached := new(Po)
+	cached.Parse("msgid \"Old text\"\nmsgstr \"Texto viejo\"\n")
+
+	if err := cached.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	if d := Diff(po, cached); d != "" {
+		t.Errorf("Expected equivalent catalogs but got:\n%s", d)
+	}
+
+	if tr := cached.Get("Old text"); tr != "Old text" {
+		t.Errorf("Expected 'Old text' but got '%s'", tr)
+	}
+
+	// Plural rule from the cached header
+	if tr := cached.GetN("One file", "%d files", 2); tr != "Dos archivos" {
+		t.Errorf("Expected 'Dos archivos' but got '%s'", tr)
+	}
+
+	// Broken caches keep the content
+	if err := cached.UnmarshalBinary([]byte("not a cache")); err == nil {
+		t.Error("Expected error loading a broken cache")
+	}
+
+	if tr := cached.Get("My text"); tr != "Mi texto" {
+		t.Errorf("Expected 'Mi texto' but got '%s'", tr)
+	}
+}
+
+func TestLocaleMarshalBinary(t *testing.T) {
+	// Set PO content
+	po := new(Po)
+	po.Parse("msgid \"My text\"\nmsgstr \"Mi texto\"\n")
+
+	extras := new(Po)
+	extras.Parse("msgid \"Extra text\"\nmsgstr \"Texto extra\"\n")
+
+	l := NewLocale("/tmp", "es")
+	l.AttachDomain("app", po)
+	l.AttachDomain("extras", extras)
+	l.SetDomain("app")
+
+	data, err := l.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	cached := NewLocale("/tmp", "es")
+	if err := cached.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	if doms := cached.GetDomains(); len(doms) != 2 || doms[0] != "app" || doms[1] != "extras" {
+		t.Errorf("Unexpected domains: %v", doms)
+	}
+
+	if tr := cached.Get("My text"); tr != "Mi texto" {
+		t.Errorf("Expected 'Mi texto' but got '%s'", tr)
+	}
+
+	if tr := cached.GetD("extras", "Extra text"); tr != "Texto extra" {
+		t.Errorf("Expected 'Texto extra' but got '%s'", tr)
+	}
+
+	// Other language
+	other := NewLocale("/tmp", "fr")
+	if err := other.UnmarshalBinary(data); err == nil {
+		t.Error("Expected error loading a cache for another language")
+	}
+	if len(other.GetDomains()) != 0 {
+		t.Errorf("Unexpected domains: %v", other.GetDomains())
+	}
+
+	// Broken and unsupported caches
+	if err := cached.UnmarshalBinary([]byte("not a cache")); err == nil {
+		t.Error("Expected error loading a broken cache")
+	}
+
+	var buf bytes.Buffer
+	gob.NewEncoder(&buf).Encode(localeCache{Version: cacheVersion + 1, Lang: "es"})
+
+	if err := cached.UnmarshalBinary(buf.Bytes()); err == nil {
+		t.Error("Expected error loading an unsupported cache version")
+	}
+
+	buf.Reset()
+	gob.NewEncoder(&buf).Encode(localeCache{Version: cacheVersion, Lang: "es", Domains: map[string][]byte{"broken": []byte("not a cache")}})
+
+	if err := cached.UnmarshalBinary(buf.Bytes()); err == nil {
+		t.Error("Expected error loading a broken domain cache")
+	}
+	if _, ok := cached.domains["broken"]; ok {
+		t.Error("Unexpected domain 'broken'")
+	}
+}
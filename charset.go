@@ -0,0 +1,339 @@
+package gotext
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Maximum amount of bytes read from the input to detect its charset.
+const charsetDetectSize = 64 * 1024
+
+// Byte order marks.
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+/*
+DetectCharset reads the beginning of a PO file from the given reader (r) and returns the name of its charset,
+without parsing the translations.
+
+The detection follows these steps:
+
+  - A byte order mark at the beginning of the content is reported as "UTF-8", "UTF-16LE" or "UTF-16BE".
+  - Otherwise, the charset declared on the Content-Type field of the header entry is returned as declared.
+  - When the header doesn't declare a charset (or it's the "CHARSET" template placeholder),
+    the content is scanned: "UTF-16LE" or "UTF-16BE" is reported when there are NUL bytes on
+    even or odd positions, "UTF-8" when it's valid UTF-8 (which includes plain ASCII),
+    and "ISO-8859-1" otherwise.
+
+Only the first 64 KiB of the content are read. An error is returned only if the reader fails.
+*/
+func DetectCharset(r io.Reader) (string, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, charsetDetectSize))
+	if err != nil {
+		return "", err
+	}
+
+	// Check byte order marks
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return "UTF-8", nil
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return "UTF-16LE", nil
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return "UTF-16BE", nil
+	}
+
+	// Check header
+	if charset := headerCharset(parseHeader(firstHeader(string(data)))); charset != "" {
+		return charset, nil
+	}
+
+	return scanCharset(data, len(data) == charsetDetectSize), nil
+}
+
+// firstHeader returns the content of the header entry of the PO formatted string (str),
+// which has to be the first entry on the catalog. It returns an empty string if there is no header.
+func firstHeader(str string) string {
+	lines := strings.Split(str, "\n")
+
+	for i, l := range lines {
+		l = strings.TrimSpace(l)
+
+		// Skip empty lines and comments
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+
+		// Check for an empty msgid as first entry
+		if l == `msgid ""` {
+			// A continuation line means a multiline msgid
+			if i+1 < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i+1]), "\"") {
+				return ""
+			}
+
+			return headerContent(lines[i+1:])
+		}
+
+		return ""
+	}
+
+	return ""
+}
+
+// scanCharset guesses the charset of the given content by looking at its bytes.
+// If truncated is true, an incomplete UTF-8 sequence at the end of the content is ignored.
+func scanCharset(data []byte, truncated bool) string {
+	// Look for NUL bytes, which are common on UTF-16 but never found on text files with other encodings
+	even, odd := 0, 0
+	for i, b := range data {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			even++
+		} else {
+			odd++
+		}
+	}
+
+	if even+odd > 0 {
+		if odd > even {
+			return "UTF-16LE"
+		}
+		return "UTF-16BE"
+	}
+
+	// Remove incomplete UTF-8 sequence at the end
+	if truncated {
+		for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+			if utf8.RuneStart(data[len(data)-i]) {
+				if !utf8.FullRune(data[len(data)-i:]) {
+					data = data[:len(data)-i]
+				}
+				break
+			}
+		}
+	}
+
+	if utf8.Valid(data) {
+		return "UTF-8"
+	}
+
+	return "ISO-8859-1"
+}
+
+// Decoders of the charsets converted to UTF-8 when parsing, keyed by normalized name (see charsetKey).
+var (
+	charsetDecoders = map[string]func([]byte) ([]byte, error){
+		"usascii":     decodeASCII,
+		"ascii":       decodeASCII,
+		"iso88591":    decodeSingleByte(nil),
+		"latin1":      decodeSingleByte(nil),
+		"iso885915":   decodeSingleByte(iso885915),
+		"latin9":      decodeSingleByte(iso885915),
+		"windows1252": decodeSingleByte(windows1252),
+		"cp1252":      decodeSingleByte(windows1252),
+		"utf16le":     decodeUTF16(false),
+		"utf16be":     decodeUTF16(true),
+	}
+
+	charsetMutex sync.RWMutex
+)
+
+// Characters of ISO-8859-15 differing from ISO-8859-1.
+var iso885915 = map[byte]rune{
+	0xA4: '€', 0xA6: 'Š', 0xA8: 'š', 0xB4: 'Ž', 0xB8: 'ž', 0xBC: 'Œ', 0xBD: 'œ', 0xBE: 'Ÿ',
+}
+
+// Characters of Windows-1252 differing from ISO-8859-1. Bytes 0x81, 0x8D, 0x8F, 0x90 and 0x9D are undefined.
+var windows1252 = map[byte]rune{
+	0x80: '€', 0x82: '‚', 0x83: 'ƒ', 0x84: '„', 0x85: '…', 0x86: '†', 0x87: '‡', 0x88: 'ˆ',
+	0x89: '‰', 0x8A: 'Š', 0x8B: '‹', 0x8C: 'Œ', 0x8E: 'Ž', 0x91: '‘', 0x92: '’', 0x93: '“',
+	0x94: '”', 0x95: '•', 0x96: '–', 0x97: '—', 0x98: '˜', 0x99: '™', 0x9A: 'š', 0x9B: '›',
+	0x9C: 'œ', 0x9E: 'ž', 0x9F: 'Ÿ',
+	0x81: utf8.RuneError, 0x8D: utf8.RuneError, 0x8F: utf8.RuneError, 0x90: utf8.RuneError, 0x9D: utf8.RuneError,
+}
+
+/*
+RegisterCharset sets the function (decode) converting content encoded with the given charset (name) to UTF-8,
+used when parsing PO content declaring that charset on its header. Names are matched ignoring case,
+dashes and underscores, so "ISO-8859-1" and "iso8859_1" are the same charset.
+It allows to support any charset without adding dependencies to this package, like the ones of golang.org/x/text:
+
+    import "golang.org/x/text/encoding/simplifiedchinese"
+
+    gotext.RegisterCharset("GBK", simplifiedchinese.GBK.NewDecoder().Bytes)
+
+The function receives the whole content and returns an error if it can't be decoded.
+Registering a charset again replaces its decoder, built-in ones included. Use a nil function to remove it.
+*/
+func RegisterCharset(name string, decode func([]byte) ([]byte, error)) {
+	charsetMutex.Lock()
+	defer charsetMutex.Unlock()
+
+	if decode == nil {
+		delete(charsetDecoders, charsetKey(name))
+		return
+	}
+
+	charsetDecoders[charsetKey(name)] = decode
+}
+
+// charsetKey returns the normalized name of the given charset (name): lowercase, without dashes and underscores.
+func charsetKey(name string) string {
+	return strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+// utf8Header returns the given header entry content (content) declaring the UTF-8 charset on its Content-Type field,
+// adding the charset parameter if it's missing. Content without a Content-Type field is returned unchanged.
+func utf8Header(content string) string {
+	lines := strings.SplitAfter(content, "\n")
+	for i, l := range lines {
+		colon := strings.Index(l, ":")
+		if colon == -1 || !strings.EqualFold(strings.TrimSpace(l[:colon]), "Content-Type") {
+			continue
+		}
+
+		value, newline := strings.TrimSuffix(l[colon+1:], "\n"), strings.HasSuffix(l, "\n")
+
+		params := strings.Split(value, ";")
+		found := false
+		for j, param := range params {
+			p := strings.TrimSpace(param)
+			if len(p) > 8 && strings.EqualFold(p[:8], "charset=") {
+				params[j] = " charset=UTF-8"
+				found = true
+			}
+		}
+		if !found {
+			params = append(params, " charset=UTF-8")
+		}
+
+		lines[i] = l[:colon+1] + strings.Join(params, ";")
+		if newline {
+			lines[i] += "\n"
+		}
+	}
+
+	return strings.Join(lines, "")
+}
+
+// toUTF8 converts the given PO formatted content (str) to UTF-8 from its charset, detected like DetectCharset,
+// removing the byte order mark, if any. UTF-8 content is returned unchanged.
+// It returns an error if the charset isn't supported or the content isn't valid for it.
+func toUTF8(str string) (string, error) {
+	charset := ""
+
+	switch {
+	case strings.HasPrefix(str, string(bomUTF8)):
+		str = str[len(bomUTF8):]
+		charset = "UTF-8"
+	case strings.HasPrefix(str, string(bomUTF16LE)):
+		return decodeCharset("UTF-16LE", str[len(bomUTF16LE):])
+	case strings.HasPrefix(str, string(bomUTF16BE)):
+		return decodeCharset("UTF-16BE", str[len(bomUTF16BE):])
+	default:
+		charset = headerCharset(parseHeader(firstHeader(str)))
+	}
+
+	if charset == "" {
+		// Avoid scanning the common case
+		if utf8.ValidString(str) && !strings.Contains(str, "\x00") {
+			return str, nil
+		}
+
+		charset = scanCharset([]byte(str), false)
+	}
+
+	return decodeCharset(charset, str)
+}
+
+// decodeCharset converts the given content (str) encoded with the given charset to UTF-8.
+func decodeCharset(charset, str string) (string, error) {
+	key := charsetKey(charset)
+	if key == "utf8" {
+		if !utf8.ValidString(str) {
+			return "", errors.New("charset: invalid UTF-8 content")
+		}
+
+		return str, nil
+	}
+
+	charsetMutex.RLock()
+	decode := charsetDecoders[key]
+	charsetMutex.RUnlock()
+
+	if decode == nil {
+		return "", fmt.Errorf("charset: unsupported charset %q", charset)
+	}
+
+	data, err := decode([]byte(str))
+	if err != nil {
+		return "", fmt.Errorf("charset: %s: %s", charset, err.Error())
+	}
+
+	return string(data), nil
+}
+
+// decodeASCII returns the given US-ASCII content (data), checking it has no bytes above 0x7F.
+func decodeASCII(data []byte) ([]byte, error) {
+	for i, b := range data {
+		if b >= utf8.RuneSelf {
+			return nil, fmt.Errorf("invalid byte 0x%02X at offset %d", b, i)
+		}
+	}
+
+	return data, nil
+}
+
+// decodeSingleByte returns a decoder for a charset mapping each byte to the same ISO-8859-1 code point,
+// except the ones on the given table. Bytes mapped to utf8.RuneError are undefined on the charset.
+func decodeSingleByte(table map[byte]rune) func([]byte) ([]byte, error) {
+	return func(data []byte) ([]byte, error) {
+		buf := make([]byte, 0, len(data))
+
+		for i, b := range data {
+			r, ok := table[b]
+			if !ok {
+				r = rune(b)
+			}
+			if r == utf8.RuneError {
+				return nil, fmt.Errorf("invalid byte 0x%02X at offset %d", b, i)
+			}
+
+			buf = utf8.AppendRune(buf, r)
+		}
+
+		return buf, nil
+	}
+}
+
+// decodeUTF16 returns a decoder for UTF-16 content without byte order mark, in big endian order if bigEndian is true.
+func decodeUTF16(bigEndian bool) func([]byte) ([]byte, error) {
+	return func(data []byte) ([]byte, error) {
+		if len(data)%2 != 0 {
+			return nil, errors.New("odd content length")
+		}
+
+		units := make([]uint16, len(data)/2)
+		for i := range units {
+			if bigEndian {
+				units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+			} else {
+				units[i] = uint16(data[2*i+1])<<8 | uint16(data[2*i])
+			}
+		}
+
+		return []byte(string(utf16.Decode(units))), nil
+	}
+}
@@ -0,0 +1,287 @@
+package gotext
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestDetectCharset(t *testing.T) {
+	tests := map[string]string{
+		// Declared on header
+		`# Translator comment
+msgid ""
+msgstr ""
+"Project-Id-Version: test\n"
+"Content-Type: text/plain; charset=ISO-8859-2\n"
+
+msgid "My text"
+msgstr "Translated text"
+`: "ISO-8859-2",
+
+		// Template placeholder, valid UTF-8
+		`msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=CHARSET\n"
+
+msgid "My text"
+msgstr "Texto traducido ñ"
+`: "UTF-8",
+
+		// No header, invalid UTF-8
+		"msgid \"My text\"\nmsgstr \"Traducci\xf3n\"\n": "ISO-8859-1",
+
+		// Header not being the first entry
+		`msgid "My text"
+msgstr "Translated text"
+
+msgid ""
+msgstr "Content-Type: text/plain; charset=GBK\n"
+`: "UTF-8",
+
+		// Byte order marks
+		"\xEF\xBB\xBFmsgid \"\"\nmsgstr \"Content-Type: text/plain; charset=ISO-8859-1\\n\"": "UTF-8",
+		"\xFF\xFEm\x00s\x00g\x00": "UTF-16LE",
+		"\xFE\xFF\x00m\x00s\x00g": "UTF-16BE",
+
+		// UTF-16 without byte order mark
+		"m\x00s\x00g\x00": "UTF-16LE",
+		"\x00m\x00s\x00g": "UTF-16BE",
+
+		// Empty content
+		"": "UTF-8",
+	}
+
+	for content, expected := range tests {
+		charset, err := DetectCharset(strings.NewReader(content))
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err.Error())
+		}
+		if charset != expected {
+			t.Errorf("Expected charset '%s' but got '%s' for content %q", expected, charset, content)
+		}
+	}
+
+	// Test truncated multi-byte sequence at the detection limit
+	data := bytes.Repeat([]byte("ñ"), charsetDetectSize)
+	charset, err := DetectCharset(bytes.NewReader(data[1:]))
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if charset != "ISO-8859-1" {
+		t.Errorf("Expected charset 'ISO-8859-1' but got '%s'", charset)
+	}
+
+	charset, err = DetectCharset(bytes.NewReader(data))
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if charset != "UTF-8" {
+		t.Errorf("Expected charset 'UTF-8' but got '%s'", charset)
+	}
+
+	// Test reader errors
+	_, err = DetectCharset(failingReader{})
+	if err == nil {
+		t.Error("Expected an error from a failing reader")
+	}
+}
+
+func TestPoCharsetConversion(t *testing.T) {
+	header := "msgid \"\"\nmsgstr \"Content-Type: text/plain; charset=%s\\n\"\n\n"
+
+	tests := []struct {
+		charset  string
+		content  string
+		expected string
+	}{
+		{"ISO-8859-1", "msgid \"Translation\"\nmsgstr \"Traducci\xf3n\"\n", "Traducción"},
+		{"latin1", "msgid \"Translation\"\nmsgstr \"Traducci\xf3n\"\n", "Traducción"},
+		{"ISO-8859-15", "msgid \"Price\"\nmsgstr \"Precio en \xa4\"\n", "Precio en €"},
+		{"Windows-1252", "msgid \"Quote\"\nmsgstr \"\x93Cita\x94\"\n", "“Cita”"},
+		{"UTF-8", "msgid \"Translation\"\nmsgstr \"Traducción\"\n", "Traducción"},
+		{"CHARSET", "msgid \"Translation\"\nmsgstr \"Traducción\"\n", "Traducción"},
+	}
+
+	for _, test := range tests {
+		po := new(Po)
+		po.SetStrictCharset(true)
+
+		str := strings.Replace(header, "%s", test.charset, 1) + test.content
+		if err := po.ParseReader(strings.NewReader(str)); err != nil {
+			t.Errorf("Unexpected error for %s: %s", test.charset, err.Error())
+		}
+
+		if entries := po.GetTranslations(); len(entries) != 1 {
+			t.Errorf("Unexpected entries for %s: %v", test.charset, entries)
+		}
+		for _, tr := range po.GetTranslations() {
+			if tr != test.expected {
+				t.Errorf("Expected '%s' for %s but got '%s'", test.expected, test.charset, tr)
+			}
+		}
+
+		// The header declares the charset of the converted content
+		if test.charset != "CHARSET" && po.Header("Content-Type") != "text/plain; charset=UTF-8" {
+			t.Errorf("Unexpected Content-Type: %s", po.Header("Content-Type"))
+		}
+	}
+
+	// Undeclared charsets are detected
+	po := new(Po)
+	po.Parse("msgid \"Translation\"\nmsgstr \"Traducci\xf3n\"\n")
+	if tr := po.Get("Translation"); tr != "Traducción" {
+		t.Errorf("Expected 'Traducción' but got '%s'", tr)
+	}
+
+	// Byte order marks
+	po = new(Po)
+	po.Parse("\xef\xbb\xbfmsgid \"My text\"\nmsgstr \"Mi texto\"\n")
+	if tr := po.Get("My text"); tr != "Mi texto" {
+		t.Errorf("Expected 'Mi texto' but got '%s'", tr)
+	}
+
+	var utf16 bytes.Buffer
+	utf16.Write(bomUTF16LE)
+	for _, r := range "msgid \"My text\"\nmsgstr \"Mi texto ñ\"\n" {
+		utf16.WriteByte(byte(r))
+		utf16.WriteByte(byte(r >> 8))
+	}
+
+	po = new(Po)
+	po.Parse(utf16.String())
+	if tr := po.Get("My text"); tr != "Mi texto ñ" {
+		t.Errorf("Expected 'Mi texto ñ' but got '%s'", tr)
+	}
+
+	// Content that can't be converted is parsed as is, unless strict
+	invalid := map[string]string{
+		"GBK":          "msgid \"My text\"\nmsgstr \"\xce\xd2\"\n",
+		"UTF-8":        "msgid \"My text\"\nmsgstr \"Mi texto\xff\"\n",
+		"US-ASCII":     "msgid \"My text\"\nmsgstr \"Traducci\xf3n\"\n",
+		"Windows-1252": "msgid \"My text\"\nmsgstr \"Mi texto\x81\"\n",
+	}
+
+	for charset, content := range invalid {
+		str := strings.Replace(header, "%s", charset, 1) + content
+
+		po := new(Po)
+		po.Parse(str)
+		if po.GetEntry("My text") == nil {
+			t.Errorf("Expected entry to be parsed for %s", charset)
+		}
+
+		po = new(Po)
+		po.SetStrictCharset(true)
+		if err := po.ParseReader(strings.NewReader(str)); err == nil {
+			t.Errorf("Expected error for %s", charset)
+		}
+		if po.GetEntry("My text") != nil {
+			t.Errorf("Unexpected entry for %s", charset)
+		}
+	}
+
+	// Registered charsets
+	RegisterCharset("x_upper", func(data []byte) ([]byte, error) {
+		return bytes.Replace(data, []byte("@"), []byte("ñ"), -1), nil
+	})
+
+	po = new(Po)
+	po.Parse(strings.Replace(header, "%s", "X-Upper", 1) + "msgid \"My text\"\nmsgstr \"Mi texto @\"\n")
+	if tr := po.Get("My text"); tr != "Mi texto ñ" {
+		t.Errorf("Expected 'Mi texto ñ' but got '%s'", tr)
+	}
+
+	RegisterCharset("X-UPPER", nil)
+
+	po = new(Po)
+	po.SetStrictCharset(true)
+	if err := po.ParseReader(strings.NewReader(strings.Replace(header, "%s", "X-Upper", 1))); err == nil {
+		t.Error("Expected error for removed charset")
+	}
+}
+
+func TestPoCharsetWrite(t *testing.T) {
+	// Set ISO-8859-1 content
+	str := "msgid \"\"\nmsgstr \"\"\n\"Language: es\\n\"\n\"Content-Type: text/plain; charset=ISO-8859-1\\n\"\n\n" +
+		"msgid \"Yes\"\nmsgstr \"S\xed\"\n"
+
+	po := new(Po)
+	po.Parse(str)
+
+	if charset := po.Charset(); charset != "UTF-8" {
+		t.Errorf("Expected 'UTF-8' but got '%s'", charset)
+	}
+
+	// Written content is read back as UTF-8
+	var buf bytes.Buffer
+	if err := po.Write(&buf, WriteOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if !strings.Contains(buf.String(), "charset=UTF-8") {
+		t.Errorf("Expected UTF-8 charset on the written header:\n%s", buf.String())
+	}
+
+	written := new(Po)
+	written.Parse(buf.String())
+	if tr := written.Get("Yes"); tr != "Sí" {
+		t.Errorf("Expected 'Sí' but got '%s'", tr)
+	}
+	if lang := written.Language(); lang != "es" {
+		t.Errorf("Expected 'es' but got '%s'", lang)
+	}
+
+	if err := AssertRoundTrip(po); err != nil {
+		t.Errorf("Unexpected round trip error: %s", err.Error())
+	}
+}
+
+func TestLocaleStrictCharset(t *testing.T) {
+	// Create Locale directory
+	dirname := path.Clean("/tmp" + string(os.PathSeparator) + "zh_CN")
+	if err := os.MkdirAll(dirname, os.ModePerm); err != nil {
+		t.Fatalf("Can't create test directory: %s", err.Error())
+	}
+
+	str := "msgid \"\"\nmsgstr \"Content-Type: text/plain; charset=GBK\\n\"\n\nmsgid \"My text\"\nmsgstr \"\xce\xd2\"\n"
+	if err := ioutil.WriteFile(path.Join(dirname, "legacy.po"), []byte(str), 0644); err != nil {
+		t.Fatalf("Can't write to test file: %s", err.Error())
+	}
+
+	l := NewLocale("/tmp", "zh_CN")
+	if errs := l.AddDomainsGlob("legacy.po"); len(errs) != 0 {
+		t.Errorf("Unexpected errors: %v", errs)
+	}
+
+	l = NewLocale("/tmp", "zh_CN")
+	l.SetStrictCharset(true)
+
+	errs := l.AddDomainsGlob("legacy.po")
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), `unsupported charset "GBK"`) {
+		t.Errorf("Unexpected errors: %v", errs)
+	}
+
+	// Supported once registered
+	RegisterCharset("GBK", func(data []byte) ([]byte, error) {
+		return bytes.Replace(data, []byte("\xce\xd2"), []byte("我"), -1), nil
+	})
+	defer RegisterCharset("GBK", nil)
+
+	if errs := l.AddDomainsGlob("legacy.po"); len(errs) != 0 {
+		t.Errorf("Unexpected errors: %v", errs)
+	}
+
+	if tr := l.GetD("legacy", "My text"); tr != "我" {
+		t.Errorf("Expected '我' but got '%s'", tr)
+	}
+}
@@ -0,0 +1,213 @@
+package gotext
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ContextKey identifies a catalog entry by its context and msgid, like the ones requested by the source code.
+// An empty Context refers to a context-less entry.
+type ContextKey struct {
+	Context string
+	MsgID   string
+}
+
+// String returns the key in PO format.
+func (k ContextKey) String() string {
+	return entryKey{ctx: k.Context, id: k.MsgID}.String()
+}
+
+/*
+CheckContexts looks for each of the given (used) context and msgid pairs in the catalog
+and returns an error for each pair that doesn't exist, in the same order they were received.
+
+It's intended to catch typos on contexts requested by the source code, which otherwise silently fall back
+to the untranslated string. When the msgid exists in the catalog under other contexts,
+they are listed on the error message to help spotting the typo:
+
+    msgctxt "Menú" msgid "Open": missing entry, the msgid exists in contexts "" and "Menu"
+
+Entries are matched as they are looked up by GetC and Get, so the whitespace normalization setting applies.
+It returns nil if every pair exists.
+*/
+func (po *Po) CheckContexts(used []ContextKey) []error {
+	var errs []error
+
+	for _, k := range used {
+		var t *Translation
+		if k.Context == "" {
+			t = po.find(k.MsgID)
+		} else {
+			t = po.findC(k.MsgID, k.Context)
+		}
+
+		if t != nil {
+			continue
+		}
+
+		msg := k.String() + ": missing entry"
+		if ctxs := po.msgidContexts(k.MsgID); len(ctxs) > 0 {
+			msg += ", the msgid exists in " + contextList(ctxs)
+		}
+
+		errs = append(errs, errors.New(msg))
+	}
+
+	return errs
+}
+
+// msgidContexts returns the sorted list of contexts having an entry for the given msgid (str),
+// including the empty one for the context-less entry.
+func (po *Po) msgidContexts(str string) []string {
+	po.RLock()
+	defer po.RUnlock()
+
+	str = po.key(str)
+	ctxs := make([]string, 0)
+
+	if _, ok := po.translations[str]; ok {
+		ctxs = append(ctxs, "")
+	}
+	for ctx := range po.contexts {
+		if _, ok := po.contexts[ctx][str]; ok {
+			ctxs = append(ctxs, ctx)
+		}
+	}
+	sort.Strings(ctxs)
+
+	return ctxs
+}
+
+// contextList returns the given contexts (list) quoted and joined in a human readable form,
+// like `context "a"` or `contexts "a", "b" and "c"`.
+func contextList(list []string) string {
+	quoted := make([]string, len(list))
+	for i, s := range list {
+		quoted[i] = strconv.Quote(s)
+	}
+
+	if len(quoted) == 1 {
+		return "context " + quoted[0]
+	}
+
+	return "contexts " + strings.Join(quoted[:len(quoted)-1], ", ") + " and " + quoted[len(quoted)-1]
+}
+
+/*
+CheckPluralFormats looks for plural entries whose format verbs don't agree
+and returns an error for each problem found, sorted by context, msgid and msgstr index.
+
+The msgid_plural verbs are used as the reference for the entry:
+
+  - The msgid and the msgstr[0] form may omit arguments, like the usual "One file" / "%d files" pair,
+    but the verbs they use must be the same ones used by the msgid_plural for those arguments.
+  - The other plural forms must use exactly the same verbs, for the same arguments, as the msgid_plural.
+
+Arguments are matched by number, so translations reordering them with explicit indexes (%[2]s) are accepted.
+Untranslated forms, singular entries and the header are ignored.
+The returned errors are Warning values, including the entry line on the parsed content.
+*/
+func (po *Po) CheckPluralFormats() []error {
+	entries := po.snapshot()
+
+	// Sort entries
+	keys := make([]entryKey, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sortEntryKeys(keys)
+
+	var errs []error
+
+	for _, k := range keys {
+		t := entries[k]
+		if t.ID == "" || t.PluralID == "" {
+			continue
+		}
+
+		warning := func(i int, msg string) Warning {
+			return Warning{Context: k.ctx, MsgID: k.id, Index: i, Line: t.Line, Message: msg}
+		}
+
+		ref := formatArgs(t.PluralID)
+
+		if args := formatArgs(t.ID); !argsMatch(args, ref, false) {
+			errs = append(errs, warning(-1, "msgid verbs "+verbList(args)+" don't match msgid_plural verbs "+verbList(ref)))
+		}
+
+		for _, i := range sortedIndexes(t.Trs) {
+			if t.Trs[i] == "" {
+				continue
+			}
+
+			if args := formatArgs(t.Trs[i]); !argsMatch(args, ref, i > 0) {
+				errs = append(errs, warning(i, "msgstr verbs "+verbList(args)+" don't match msgid_plural verbs "+verbList(ref)))
+			}
+		}
+	}
+
+	return errs
+}
+
+// argsMatch returns true if the format arguments (args) use the same verbs as the reference ones (ref).
+// When exact is false, args can omit some of the reference arguments.
+func argsMatch(args, ref map[int]byte, exact bool) bool {
+	if exact && len(args) != len(ref) {
+		return false
+	}
+
+	for n, c := range args {
+		if ref[n] != c {
+			return false
+		}
+	}
+
+	return true
+}
+
+// verbList returns the given format arguments (args) in a human readable form, like "%d, %s", or "none" if empty.
+func verbList(args map[int]byte) string {
+	if len(args) == 0 {
+		return "none"
+	}
+
+	nums := make([]int, 0, len(args))
+	for n := range args {
+		nums = append(nums, n)
+	}
+	sort.Ints(nums)
+
+	verbs := make([]string, len(nums))
+	for i, n := range nums {
+		verbs[i] = "%" + string(args[n])
+		if n != i+1 {
+			verbs[i] = "%[" + strconv.Itoa(n) + "]" + string(args[n])
+		}
+	}
+
+	return strings.Join(verbs, ", ")
+}
+
+/*
+CheckPluralConsistency returns an error for each duplicate entry found while parsing the catalog
+that declares a different msgid_plural than the entry it replaced, in the order they were found.
+
+Duplicate entries, with the same context and msgid, are ambiguous: only the last one parsed is kept,
+so the plural forms used depend on the order of the entries in the file.
+The returned errors are Warning values, including the line of the duplicate entry,
+and the same warnings are reported to the load warning handler while parsing.
+Use Merge with the KeepPluralID option to fix the catalog with the canonical msgid_plural.
+*/
+func (po *Po) CheckPluralConsistency() []error {
+	po.RLock()
+	defer po.RUnlock()
+
+	var errs []error
+	for _, w := range po.pluralConflicts {
+		errs = append(errs, w)
+	}
+
+	return errs
+}
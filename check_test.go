@@ -0,0 +1,202 @@
+package gotext
+
+import (
+	"testing"
+)
+
+func TestPoCheckContexts(t *testing.T) {
+	// Set PO content
+	str := `
+msgid "Open"
+msgstr "Abrir"
+
+msgctxt "Menu"
+msgid "Open"
+msgstr "Abrir"
+
+msgctxt "Dialog"
+msgid "Close"
+msgstr "Cerrar"
+`
+
+	// Parse po content
+	po := new(Po)
+	po.Parse(str)
+
+	errs := po.CheckContexts([]ContextKey{
+		{MsgID: "Open"},
+		{Context: "Menu", MsgID: "Open"},
+		{Context: "Menú", MsgID: "Open"},
+		{MsgID: "Close"},
+		{Context: "Dialog", MsgID: "Close"},
+		{Context: "Dialog", MsgID: "Missing"},
+	})
+
+	expected := []string{
+		`msgctxt "Menú" msgid "Open": missing entry, the msgid exists in contexts "" and "Menu"`,
+		`msgid "Close": missing entry, the msgid exists in context "Dialog"`,
+		`msgctxt "Dialog" msgid "Missing": missing entry`,
+	}
+
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors but got %d: %v", len(expected), len(errs), errs)
+	}
+
+	for i, err := range errs {
+		if err.Error() != expected[i] {
+			t.Errorf("Expected error '%s' but got '%s'", expected[i], err.Error())
+		}
+	}
+
+	// No errors
+	if errs := po.CheckContexts([]ContextKey{{Context: "Menu", MsgID: "Open"}}); errs != nil {
+		t.Errorf("Expected nil but got %v", errs)
+	}
+}
+
+func TestPoCheckPluralFormats(t *testing.T) {
+	// Set PO content
+	str := `
+msgid "Not plural %s"
+msgstr "No plural %d"
+
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Un archivo"
+msgstr[1] "%d archivos"
+
+msgid "One folder"
+msgid_plural "%d folders"
+msgstr[0] "%d carpeta"
+msgstr[1] "Carpetas"
+msgstr[2] ""
+
+msgid "%s has one file"
+msgid_plural "%s has %d files"
+msgstr[0] "%[1]d archivo de %[2]s"
+msgstr[1] "%[2]d archivos de %[1]s"
+
+msgctxt "Ctx"
+msgid "%d item"
+msgid_plural "%s items"
+msgstr[0] "%d elemento"
+msgstr[1] "%d elementos"
+`
+
+	// Parse po content
+	po := new(Po)
+	po.Parse(str)
+
+	expected := []string{
+		`line 16: msgid "%s has one file" (msgstr[0]): msgstr verbs %d, %s don't match msgid_plural verbs %s, %d`,
+		`line 10: msgid "One folder" (msgstr[1]): msgstr verbs none don't match msgid_plural verbs %d`,
+		`line 22: msgctxt "Ctx" msgid "%d item": msgid verbs %d don't match msgid_plural verbs %s`,
+		`line 22: msgctxt "Ctx" msgid "%d item" (msgstr[0]): msgstr verbs %d don't match msgid_plural verbs %s`,
+		`line 22: msgctxt "Ctx" msgid "%d item" (msgstr[1]): msgstr verbs %d don't match msgid_plural verbs %s`,
+	}
+
+	errs := po.CheckPluralFormats()
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors but got %d: %v", len(expected), len(errs), errs)
+	}
+
+	for i, err := range errs {
+		if err.Error() != expected[i] {
+			t.Errorf("Expected error '%s' but got '%s'", expected[i], err.Error())
+		}
+	}
+}
+
+func TestPoCheckPluralConsistency(t *testing.T) {
+	// Set PO content
+	str := `
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Un archivo"
+msgstr[1] "%d archivos"
+
+msgid "My text"
+msgstr "Mi texto"
+
+msgid "One file"
+msgid_plural "%d file(s)"
+msgstr[0] "Un archivo"
+msgstr[1] "%d archivo(s)"
+
+msgid "My text"
+msgstr "Mi texto"
+
+msgctxt "Ctx"
+msgid "One file"
+msgid_plural "%d files in context"
+msgstr[0] "Un archivo"
+msgstr[1] "%d archivos"
+`
+
+	// Parse po content
+	var warnings []string
+	po := new(Po)
+	po.SetLoadWarnHandler(func(w Warning) {
+		warnings = append(warnings, w.String())
+	})
+	po.Parse(str)
+
+	expected := `line 10: msgid "One file": msgid_plural "%d file(s)" doesn't match "%d files" declared by the duplicate entry on line 2`
+
+	if len(warnings) != 1 || warnings[0] != expected {
+		t.Errorf("Expected warning '%s' but got %v", expected, warnings)
+	}
+
+	errs := po.CheckPluralConsistency()
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 error but got %d: %v", len(errs), errs)
+	}
+	if errs[0].Error() != expected {
+		t.Errorf("Expected error '%s' but got '%s'", expected, errs[0].Error())
+	}
+
+	// Parsing the content again doesn't report the conflicts twice
+	warnings = nil
+	po.Parse(str)
+	if len(warnings) != 1 || warnings[0] != expected {
+		t.Errorf("Expected warning '%s' but got %v", expected, warnings)
+	}
+	if errs := po.CheckPluralConsistency(); len(errs) != 1 {
+		t.Errorf("Expected 1 error but got %d: %v", len(errs), errs)
+	}
+
+	// Cached catalogs keep them once
+	data, err := po.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	cached := new(Po)
+	if err := cached.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if errs := cached.CheckPluralConsistency(); len(errs) != 1 {
+		t.Errorf("Expected 1 error but got %d: %v", len(errs), errs)
+	}
+
+	// Consistent catalog
+	po = new(Po)
+	po.Parse("msgid \"One file\"\nmsgid_plural \"%d files\"\nmsgstr[0] \"Un archivo\"\nmsgstr[1] \"%d archivos\"\n")
+
+	if errs := po.CheckPluralConsistency(); len(errs) != 0 {
+		t.Errorf("Expected no errors but got %v", errs)
+	}
+}
+
+func TestVerbList(t *testing.T) {
+	for format, expected := range map[string]string{
+		"No verbs %%":       "none",
+		"%s and %d":         "%s, %d",
+		"%[2]s then %[1]d":  "%d, %s",
+		"Only second %[2]v": "%[2]v",
+		"Width %*d":         "%*, %d",
+	} {
+		if list := verbList(formatArgs(format)); list != expected {
+			t.Errorf("Expected '%s' for '%s' but got '%s'", expected, format, list)
+		}
+	}
+}
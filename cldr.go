@@ -0,0 +1,374 @@
+package gotext
+
+import (
+	"strings"
+)
+
+// CLDR plural categories.
+const (
+	PluralZero  = "zero"
+	PluralOne   = "one"
+	PluralTwo   = "two"
+	PluralFew   = "few"
+	PluralMany  = "many"
+	PluralOther = "other"
+)
+
+// cldrRule returns the CLDR plural category for the non-negative integer n.
+type cldrRule func(n int) string
+
+// CLDR cardinal plural rules for integers.
+// See http://www.unicode.org/cldr/charts/latest/supplemental/language_plural_rules.html
+var (
+	// Languages without plural forms.
+	cldrOther cldrRule = func(n int) string {
+		return PluralOther
+	}
+
+	// one: 1
+	cldrOne cldrRule = func(n int) string {
+		if n == 1 {
+			return PluralOne
+		}
+		return PluralOther
+	}
+
+	// one: 0, 1
+	cldrZeroOne cldrRule = func(n int) string {
+		if n == 0 || n == 1 {
+			return PluralOne
+		}
+		return PluralOther
+	}
+
+	// one: 0, 1; many: multiples of a million
+	cldrZeroOneMany cldrRule = func(n int) string {
+		if n == 0 || n == 1 {
+			return PluralOne
+		}
+		if n%1000000 == 0 {
+			return PluralMany
+		}
+		return PluralOther
+	}
+
+	// one: 1; many: multiples of a million
+	cldrOneMany cldrRule = func(n int) string {
+		if n == 1 {
+			return PluralOne
+		}
+		if n != 0 && n%1000000 == 0 {
+			return PluralMany
+		}
+		return PluralOther
+	}
+
+	// East Slavic
+	cldrSlavic cldrRule = func(n int) string {
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return PluralOne
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return PluralFew
+		}
+		return PluralMany
+	}
+
+	// Bosnian, Croatian, Serbian
+	cldrSerboCroatian cldrRule = func(n int) string {
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return PluralOne
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return PluralFew
+		}
+		return PluralOther
+	}
+
+	// Polish
+	cldrPolish cldrRule = func(n int) string {
+		switch {
+		case n == 1:
+			return PluralOne
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return PluralFew
+		}
+		return PluralMany
+	}
+
+	// Czech, Slovak
+	cldrCzech cldrRule = func(n int) string {
+		switch {
+		case n == 1:
+			return PluralOne
+		case n >= 2 && n <= 4:
+			return PluralFew
+		}
+		return PluralOther
+	}
+
+	// Slovenian
+	cldrSlovenian cldrRule = func(n int) string {
+		switch n % 100 {
+		case 1:
+			return PluralOne
+		case 2:
+			return PluralTwo
+		case 3, 4:
+			return PluralFew
+		}
+		return PluralOther
+	}
+
+	// Lithuanian
+	cldrLithuanian cldrRule = func(n int) string {
+		if n%100 >= 11 && n%100 <= 19 {
+			return PluralOther
+		}
+		switch {
+		case n%10 == 1:
+			return PluralOne
+		case n%10 >= 2:
+			return PluralFew
+		}
+		return PluralOther
+	}
+
+	// Latvian
+	cldrLatvian cldrRule = func(n int) string {
+		switch {
+		case n%10 == 0 || (n%100 >= 11 && n%100 <= 19):
+			return PluralZero
+		case n%10 == 1:
+			return PluralOne
+		}
+		return PluralOther
+	}
+
+	// Romanian
+	cldrRomanian cldrRule = func(n int) string {
+		switch {
+		case n == 1:
+			return PluralOne
+		case n == 0 || (n%100 >= 1 && n%100 <= 19):
+			return PluralFew
+		}
+		return PluralOther
+	}
+
+	// Arabic
+	cldrArabic cldrRule = func(n int) string {
+		switch {
+		case n == 0:
+			return PluralZero
+		case n == 1:
+			return PluralOne
+		case n == 2:
+			return PluralTwo
+		case n%100 >= 3 && n%100 <= 10:
+			return PluralFew
+		case n%100 >= 11:
+			return PluralMany
+		}
+		return PluralOther
+	}
+
+	// Hebrew
+	cldrHebrew cldrRule = func(n int) string {
+		switch n {
+		case 1:
+			return PluralOne
+		case 2:
+			return PluralTwo
+		}
+		return PluralOther
+	}
+
+	// Irish
+	cldrIrish cldrRule = func(n int) string {
+		switch {
+		case n == 1:
+			return PluralOne
+		case n == 2:
+			return PluralTwo
+		case n >= 3 && n <= 6:
+			return PluralFew
+		case n >= 7 && n <= 10:
+			return PluralMany
+		}
+		return PluralOther
+	}
+
+	// Scottish Gaelic
+	cldrGaelic cldrRule = func(n int) string {
+		switch {
+		case n == 1 || n == 11:
+			return PluralOne
+		case n == 2 || n == 12:
+			return PluralTwo
+		case (n >= 3 && n <= 10) || (n >= 13 && n <= 19):
+			return PluralFew
+		}
+		return PluralOther
+	}
+
+	// Welsh
+	cldrWelsh cldrRule = func(n int) string {
+		switch n {
+		case 0:
+			return PluralZero
+		case 1:
+			return PluralOne
+		case 2:
+			return PluralTwo
+		case 3:
+			return PluralFew
+		case 6:
+			return PluralMany
+		}
+		return PluralOther
+	}
+
+	// Maltese
+	cldrMaltese cldrRule = func(n int) string {
+		switch {
+		case n == 1:
+			return PluralOne
+		case n == 2:
+			return PluralTwo
+		case n == 0 || (n%100 >= 3 && n%100 <= 10):
+			return PluralFew
+		case n%100 >= 11 && n%100 <= 19:
+			return PluralMany
+		}
+		return PluralOther
+	}
+
+	// Icelandic, Macedonian
+	cldrIcelandic cldrRule = func(n int) string {
+		if n%10 == 1 && n%100 != 11 {
+			return PluralOne
+		}
+		return PluralOther
+	}
+
+	// Filipino, Tagalog
+	cldrFilipino cldrRule = func(n int) string {
+		switch n % 10 {
+		case 4, 6, 9:
+			return PluralOther
+		}
+		return PluralOne
+	}
+)
+
+// CLDR plural rules by language code.
+var cldrRules = map[string]cldrRule{
+	// No plural forms
+	"bo": cldrOther, "dz": cldrOther, "id": cldrOther, "ig": cldrOther, "ja": cldrOther, "jv": cldrOther,
+	"km": cldrOther, "ko": cldrOther, "lo": cldrOther, "ms": cldrOther, "my": cldrOther, "th": cldrOther,
+	"vi": cldrOther, "yo": cldrOther, "zh": cldrOther,
+
+	// one: 1
+	"af": cldrOne, "az": cldrOne, "bg": cldrOne, "da": cldrOne, "de": cldrOne, "el": cldrOne,
+	"en": cldrOne, "eo": cldrOne, "et": cldrOne, "eu": cldrOne, "fi": cldrOne, "fy": cldrOne,
+	"gl": cldrOne, "hu": cldrOne, "ka": cldrOne, "kk": cldrOne, "ky": cldrOne, "lb": cldrOne,
+	"ml": cldrOne, "mn": cldrOne, "mr": cldrOne, "nb": cldrOne, "ne": cldrOne, "nl": cldrOne,
+	"nn": cldrOne, "no": cldrOne, "ps": cldrOne, "sq": cldrOne, "sv": cldrOne, "sw": cldrOne,
+	"ta": cldrOne, "te": cldrOne, "tk": cldrOne, "tr": cldrOne, "ur": cldrOne, "uz": cldrOne,
+
+	// one: 0, 1
+	"am": cldrZeroOne, "as": cldrZeroOne, "bn": cldrZeroOne, "fa": cldrZeroOne, "gu": cldrZeroOne,
+	"hi": cldrZeroOne, "hy": cldrZeroOne, "kn": cldrZeroOne, "pa": cldrZeroOne, "si": cldrZeroOne,
+	"zu": cldrZeroOne,
+
+	// Romance languages with a many form for millions
+	"ca": cldrOneMany, "es": cldrOneMany, "it": cldrOneMany, "pt_PT": cldrOneMany,
+	"fr": cldrZeroOneMany, "pt": cldrZeroOneMany,
+
+	// one, few, many/other
+	"be": cldrSlavic, "ru": cldrSlavic, "uk": cldrSlavic,
+	"bs": cldrSerboCroatian, "hr": cldrSerboCroatian, "sh": cldrSerboCroatian, "sr": cldrSerboCroatian,
+	"pl": cldrPolish,
+	"cs": cldrCzech, "sk": cldrCzech,
+	"lt": cldrLithuanian,
+	"ro": cldrRomanian, "mo": cldrRomanian,
+	"is": cldrIcelandic, "mk": cldrIcelandic,
+	"fil": cldrFilipino, "tl": cldrFilipino,
+
+	// zero, one, other
+	"lv": cldrLatvian,
+
+	// one, two and more
+	"sl": cldrSlovenian,
+	"he": cldrHebrew, "iw": cldrHebrew,
+	"ar": cldrArabic,
+	"ga": cldrIrish,
+	"gd": cldrGaelic,
+	"cy": cldrWelsh,
+	"mt": cldrMaltese,
+}
+
+// cldrRuleFor returns the CLDR plural rule for the given language code.
+// Full codes ("pt_PT") are looked up before the simplified ones ("pt"). Both "_" and "-" separators are accepted.
+// Unknown languages use the CLDR root rule, which only has the "other" category.
+func cldrRuleFor(lang string) cldrRule {
+	lang = strings.Replace(lang, "-", "_", -1)
+
+	// Remove encoding and modifier (es_ES.UTF-8, sr_RS@latin)
+	if i := strings.IndexAny(lang, ".@"); i != -1 {
+		lang = lang[:i]
+	}
+
+	parts := strings.Split(lang, "_")
+	parts[0] = strings.ToLower(parts[0])
+	if len(parts) > 1 {
+		parts[len(parts)-1] = strings.ToUpper(parts[len(parts)-1])
+		if rule, ok := cldrRules[parts[0]+"_"+parts[len(parts)-1]]; ok {
+			return rule
+		}
+	}
+
+	if rule, ok := cldrRules[parts[0]]; ok {
+		return rule
+	}
+
+	return cldrOther
+}
+
+// pluralCategory returns the CLDR plural category name for the count (n) in the given language (lang).
+func pluralCategory(lang string, n int) string {
+	if n < 0 {
+		n = -n
+	}
+
+	return cldrRuleFor(lang)(n)
+}
+
+// Plural returns the string from the given forms map corresponding to the CLDR plural category
+// of the count (n) in the given language (lang).
+// The forms map is keyed by category name: "zero", "one", "two", "few", "many" and "other".
+// If the category isn't present on the map, the "other" form is returned.
+//
+// It's intended for ad-hoc pluralization of strings generated in code, without a PO catalog:
+//
+//	gotext.Plural("ru", 3, map[string]string{
+//	    "one":   "файл",
+//	    "few":   "файла",
+//	    "other": "файлов",
+//	})
+func Plural(lang string, n int, forms map[string]string) string {
+	if str, ok := forms[pluralCategory(lang, n)]; ok {
+		return str
+	}
+
+	return forms[PluralOther]
+}
+
+// PluralCategory returns the CLDR plural category name ("zero", "one", "two", "few", "many" or "other")
+// of the count (n) for the language of this Locale.
+// Unlike the plural form index used by GetN, the category doesn't depend on the catalog Plural-Forms header,
+// so it has the same meaning across languages.
+func (l *Locale) PluralCategory(n int) string {
+	return pluralCategory(l.lang, n)
+}
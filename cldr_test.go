@@ -0,0 +1,83 @@
+package gotext
+
+import (
+	"testing"
+)
+
+func TestPluralCategory(t *testing.T) {
+	tests := map[string]map[int]string{
+		"en":             {0: "other", 1: "one", 2: "other", 11: "other", -1: "one"},
+		"en_US":          {1: "one", 5: "other"},
+		"ja":             {0: "other", 1: "other", 2: "other"},
+		"fr":             {0: "one", 1: "one", 2: "other", 1000000: "many"},
+		"pt_BR":          {0: "one", 1: "one", 2: "other"},
+		"pt_PT":          {0: "other", 1: "one", 2: "other"},
+		"pt-pt":          {0: "other", 1: "one"},
+		"es":             {0: "other", 1: "one", 2: "other", 1000000: "many"},
+		"ru":             {1: "one", 2: "few", 5: "many", 11: "many", 21: "one", 22: "few", 112: "many"},
+		"pl":             {1: "one", 2: "few", 5: "many", 21: "many", 22: "few"},
+		"cs":             {1: "one", 3: "few", 5: "other"},
+		"sl":             {1: "one", 2: "two", 3: "few", 5: "other", 101: "one", 102: "two"},
+		"lt":             {1: "one", 2: "few", 10: "other", 11: "other", 21: "one"},
+		"lv":             {0: "zero", 1: "one", 2: "other", 11: "zero", 21: "one"},
+		"ro":             {0: "few", 1: "one", 2: "few", 20: "other", 101: "few"},
+		"ar":             {0: "zero", 1: "one", 2: "two", 3: "few", 11: "many", 100: "other"},
+		"he":             {1: "one", 2: "two", 3: "other"},
+		"ga":             {1: "one", 2: "two", 3: "few", 7: "many", 11: "other"},
+		"gd":             {1: "one", 11: "one", 12: "two", 13: "few", 20: "other"},
+		"cy":             {0: "zero", 1: "one", 2: "two", 3: "few", 6: "many", 7: "other"},
+		"mt":             {0: "few", 1: "one", 2: "two", 11: "many", 20: "other"},
+		"is":             {1: "one", 11: "other", 21: "one"},
+		"fil":            {1: "one", 4: "other", 5: "one", 14: "other"},
+		"sr.UTF-8@latin": {1: "one", 2: "few", 5: "other"},
+		"xx":             {1: "other"},
+	}
+
+	for lang, cases := range tests {
+		for n, want := range cases {
+			if got := pluralCategory(lang, n); got != want {
+				t.Errorf("Expected category '%s' for %d in '%s' but got '%s'", want, n, lang, got)
+			}
+		}
+	}
+}
+
+func TestPlural(t *testing.T) {
+	forms := map[string]string{
+		"one":   "файл",
+		"few":   "файла",
+		"other": "файлов",
+	}
+
+	str := Plural("ru", 1, forms)
+	if str != "файл" {
+		t.Errorf("Expected 'файл' but got '%s'", str)
+	}
+
+	str = Plural("ru", 3, forms)
+	if str != "файла" {
+		t.Errorf("Expected 'файла' but got '%s'", str)
+	}
+
+	// Test missing category falls back to other
+	str = Plural("ru", 5, forms)
+	if str != "файлов" {
+		t.Errorf("Expected 'файлов' but got '%s'", str)
+	}
+
+	// Test missing other
+	str = Plural("en", 2, map[string]string{"one": "file"})
+	if str != "" {
+		t.Errorf("Expected '' but got '%s'", str)
+	}
+}
+
+func TestLocalePluralCategory(t *testing.T) {
+	l := NewLocale("/tmp", "ru_RU")
+
+	for n, want := range map[int]string{1: "one", 3: "few", 5: "many", 21: "one"} {
+		if got := l.PluralCategory(n); got != want {
+			t.Errorf("Expected category '%s' for %d but got '%s'", want, n, got)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Command gotext-extract finds the translatable strings used on Go source files
+and writes a PO template (.pot file) for each domain found, named after the domain.
+
+Usage:
+
+    gotext-extract [-d default-domain] [-o output-dir] [dir ...]
+
+The given directories, or the current one if none is given, are parsed recursively.
+Package patterns like "./..." are accepted as well, parsing the directory they start with.
+Strings are found on the calls to the gotext translation functions and methods, as described on the extract package.
+Calls without a domain argument, like Get, use the domain set with -d, "default" if not set.
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/leonelquinteros/gotext/extract"
+)
+
+func main() {
+	dom := flag.String("d", "default", "domain used by the calls without a domain argument")
+	out := flag.String("o", ".", "directory to write the templates to")
+	flag.Parse()
+
+	dirs := flag.Args()
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+
+	e := extract.New()
+	e.SetDomain(*dom)
+
+	for _, dir := range dirs {
+		// Accept package patterns
+		if dir = strings.TrimSuffix(dir, "..."); dir == "" {
+			dir = "."
+		}
+
+		if err := e.ParseDir(dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	catalogs := e.Catalogs()
+	for _, d := range e.Domains() {
+		filename := filepath.Join(*out, d+".pot")
+
+		if err := catalogs[d].DumpFile(filename); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+}
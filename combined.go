@@ -0,0 +1,109 @@
+package gotext
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+/*
+ParseCombined splits a PO formatted string (str) that packs several languages into a single catalog
+and returns a Po object for each language found, keyed by language code.
+
+The supported convention is a header entry (an entry with an empty msgid) starting each language block.
+The header has to declare the block language with an "X-Language" field:
+
+    msgid ""
+    msgstr ""
+    "X-Language: es\n"
+
+    msgid "My text"
+    msgstr "Mi texto"
+
+    msgid ""
+    msgstr ""
+    "X-Language: fr\n"
+
+    msgid "My text"
+    msgstr "Mon texte"
+
+Every entry following a language header belongs to that language until the next language header is found.
+Entries found before the first language header are ignored.
+If the same language is declared more than once, all its blocks are parsed into the same Po object.
+
+This is a separate entry point from Po.Parse, which keeps handling regular single language catalogs.
+*/
+func ParseCombined(str string) map[string]*Po {
+	// Language blocks buffer
+	blocks := make(map[string][]string)
+	order := make([]string, 0)
+
+	// Current language
+	lang := ""
+
+	// Get lines
+	lines := strings.Split(str, "\n")
+
+	for i := 0; i < len(lines); i++ {
+		l := strings.TrimSpace(lines[i])
+
+		// Look for language headers
+		if strings.HasPrefix(l, "msgid") && !strings.HasPrefix(l, "msgid_plural") {
+			if id, _ := strconv.Unquote(strings.TrimSpace(strings.TrimPrefix(l, "msgid"))); id == "" {
+				if hl := headerLanguage(lines[i+1:]); hl != "" {
+					lang = hl
+					if _, ok := blocks[lang]; !ok {
+						order = append(order, lang)
+					}
+				}
+			}
+		}
+
+		// Skip content outside language blocks
+		if lang == "" {
+			continue
+		}
+
+		blocks[lang] = append(blocks[lang], lines[i])
+	}
+
+	// Parse each language block
+	pos := make(map[string]*Po)
+	for _, lang := range order {
+		po := new(Po)
+		po.Parse(strings.Join(blocks[lang], "\n"))
+		pos[lang] = po
+	}
+
+	return pos
+}
+
+// ParseCombinedFile tries to read the file by its provided path (f) and split its content using ParseCombined.
+// It returns nil if the file can't be read.
+func ParseCombinedFile(f string) map[string]*Po {
+	// Check if file exists
+	info, err := os.Stat(f)
+	if err != nil {
+		return nil
+	}
+
+	// Check that isn't a directory
+	if info.IsDir() {
+		return nil
+	}
+
+	// Parse file content
+	data, err := ioutil.ReadFile(f)
+	if err != nil {
+		return nil
+	}
+
+	return ParseCombined(string(data))
+}
+
+// headerLanguage looks for the "X-Language" field in the lines following a header msgid.
+// It returns an empty string if the field isn't declared.
+func headerLanguage(lines []string) string {
+	return parseHeader(headerContent(lines))["X-Language"]
+}
@@ -0,0 +1,108 @@
+package gotext
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+func TestParseCombined(t *testing.T) {
+	// Set combined PO content
+	str := `# Entries before any language header are ignored
+msgid "Ignored"
+msgstr "Not used"
+
+msgid ""
+msgstr ""
+"Project-Id-Version: vendor\n"
+"X-Language: es\n"
+
+msgid "My text"
+msgstr "Mi texto"
+
+msgctxt "Ctx"
+msgid "Some random in a context"
+msgstr "Algo aleatorio en un contexto"
+
+msgid ""
+msgstr "X-Language: fr\n"
+
+msgid "My text"
+msgstr "Mon texte"
+
+msgid ""
+msgstr ""
+"X-Language: es\n"
+
+msgid "More"
+msgstr "Más"
+    `
+
+	// Write content to file
+	filename := path.Clean(os.TempDir() + string(os.PathSeparator) + "combined.po")
+
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatalf("Can't create test file: %s", err.Error())
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(str)
+	if err != nil {
+		t.Fatalf("Can't write to test file: %s", err.Error())
+	}
+
+	// Try to parse a directory
+	if pos := ParseCombinedFile(path.Clean(os.TempDir())); pos != nil {
+		t.Errorf("Expected nil result when parsing a directory, but got %v", pos)
+	}
+
+	// Parse file
+	pos := ParseCombinedFile(filename)
+
+	if len(pos) != 2 {
+		t.Fatalf("Expected 2 languages but got %d", len(pos))
+	}
+
+	// Test spanish translations
+	es, ok := pos["es"]
+	if !ok {
+		t.Fatal("Expected 'es' language to be parsed")
+	}
+
+	tr := es.Get("My text")
+	if tr != "Mi texto" {
+		t.Errorf("Expected 'Mi texto' but got '%s'", tr)
+	}
+
+	tr = es.GetC("Some random in a context", "Ctx")
+	if tr != "Algo aleatorio en un contexto" {
+		t.Errorf("Expected 'Algo aleatorio en un contexto' but got '%s'", tr)
+	}
+
+	tr = es.Get("More")
+	if tr != "Más" {
+		t.Errorf("Expected 'Más' but got '%s'", tr)
+	}
+
+	tr = es.Get("Ignored")
+	if tr != "Ignored" {
+		t.Errorf("Expected 'Ignored' but got '%s'", tr)
+	}
+
+	// Test french translations
+	fr, ok := pos["fr"]
+	if !ok {
+		t.Fatal("Expected 'fr' language to be parsed")
+	}
+
+	tr = fr.Get("My text")
+	if tr != "Mon texte" {
+		t.Errorf("Expected 'Mon texte' but got '%s'", tr)
+	}
+
+	tr = fr.Get("More")
+	if tr != "More" {
+		t.Errorf("Expected 'More' but got '%s'", tr)
+	}
+}
@@ -0,0 +1,142 @@
+package gotext
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+)
+
+/*
+Diff compares two parsed catalogs and returns a human readable, per-entry description of the changes
+needed to go from the old catalog to the updated one.
+
+Entries are identified by their context and msgid, so the result doesn't depend on the order,
+wrapping or formatting of the original files.
+Each changed entry is described by a line starting with "+" (added), "-" (removed) or "~" (changed),
+followed by the msgid_plural and msgstr lines that differ, prefixed the same way:
+
+    + msgid "New text"
+    +     msgstr "Nuevo texto"
+    ~ msgctxt "Ctx" msgid "My text"
+    -     msgstr "Mi texto"
+    +     msgstr "Mi nuevo texto"
+    - msgid "Old text"
+    -     msgstr "Texto viejo"
+
+Entries are listed sorted by context and msgid. An empty string is returned when both catalogs are equivalent.
+*/
+func Diff(old, updated *Po) string {
+	a := old.snapshot()
+	b := updated.snapshot()
+
+	// Collect and sort entry keys from both catalogs
+	keys := make([]entryKey, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sortEntryKeys(keys)
+
+	var buf bytes.Buffer
+
+	for _, k := range keys {
+		ta, inA := a[k]
+		tb, inB := b[k]
+
+		switch {
+		case !inA:
+			buf.WriteString("+ " + k.String() + "\n")
+			writeDiffLines(&buf, "+", tb)
+
+		case !inB:
+			buf.WriteString("- " + k.String() + "\n")
+			writeDiffLines(&buf, "-", ta)
+
+		default:
+			lines := diffLines(ta, tb)
+			if len(lines) == 0 {
+				continue
+			}
+
+			buf.WriteString("~ " + k.String() + "\n")
+			for _, l := range lines {
+				buf.WriteString(l + "\n")
+			}
+		}
+	}
+
+	return buf.String()
+}
+
+// writeDiffLines writes all the msgid_plural and msgstr lines for the translation (t) prefixed by the given symbol.
+func writeDiffLines(buf *bytes.Buffer, prefix string, t *Translation) {
+	lines := t.lines()
+	for _, i := range sortedIndexes(lines) {
+		buf.WriteString(prefix + "     " + lines[i] + "\n")
+	}
+}
+
+// diffLines returns the msgid_plural and msgstr lines that differ between two translations of the same entry.
+func diffLines(a, b *Translation) []string {
+	la := a.lines()
+	lb := b.lines()
+
+	// Merge indexes from both sides
+	indexes := sortedIndexes(la)
+	for i := range lb {
+		if _, ok := la[i]; !ok {
+			indexes = append(indexes, i)
+		}
+	}
+	sort.Ints(indexes)
+
+	res := make([]string, 0)
+	for _, i := range indexes {
+		if la[i] == lb[i] {
+			continue
+		}
+		if _, ok := la[i]; ok {
+			res = append(res, "-     "+la[i])
+		}
+		if _, ok := lb[i]; ok {
+			res = append(res, "+     "+lb[i])
+		}
+	}
+
+	return res
+}
+
+// lines returns the PO formatted msgid_plural and msgstr lines for the translation, keyed by display order.
+// The msgid_plural line is keyed by -1 and each msgstr line by its plural index.
+func (t *Translation) lines() map[int]string {
+	lines := make(map[int]string)
+
+	if t.PluralID != "" {
+		lines[-1] = "msgid_plural " + strconv.Quote(t.PluralID)
+	}
+
+	for i, str := range t.Trs {
+		if t.PluralID == "" && i == 0 {
+			lines[i] = "msgstr " + strconv.Quote(str)
+		} else {
+			lines[i] = "msgstr[" + strconv.Itoa(i) + "] " + strconv.Quote(str)
+		}
+	}
+
+	return lines
+}
+
+// sortedIndexes returns the keys of the given lines map in ascending order.
+func sortedIndexes(lines map[int]string) []int {
+	indexes := make([]int, 0, len(lines))
+	for i := range lines {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	return indexes
+}
@@ -0,0 +1,86 @@
+package gotext
+
+import (
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	// Set old PO content
+	old := `
+msgid "My text"
+msgstr "Translated text"
+
+msgid "Removed text"
+msgstr "Removed translation"
+
+msgid "One with var: %s"
+msgid_plural "Several with vars: %s"
+msgstr[0] "This one is the singular: %s"
+msgstr[1] "This one is the plural: %s"
+
+msgctxt "Ctx"
+msgid "My text"
+msgstr "Translated text in a context"
+
+msgid "Unchanged"
+msgstr "Unchanged translation"
+    `
+
+	// Set updated PO content, reordered
+	updated := `
+msgid "Unchanged"
+msgstr "Unchanged translation"
+
+msgctxt "Ctx"
+msgid "My text"
+msgstr "Updated text in a context"
+
+msgid "One with var: %s"
+msgid_plural "Several with vars: %s"
+msgstr[0] "This one is the singular: %s"
+msgstr[1] "This one is the updated plural: %s"
+msgstr[2] "And this is the second plural form: %s"
+
+msgid "My text"
+msgstr "Translated text"
+
+msgid "Added text"
+msgstr "Added translation"
+    `
+
+	a := new(Po)
+	a.Parse(old)
+
+	b := new(Po)
+	b.Parse(updated)
+
+	expected := `+ msgid "Added text"
++     msgstr "Added translation"
+~ msgid "One with var: %s"
+-     msgstr[1] "This one is the plural: %s"
++     msgstr[1] "This one is the updated plural: %s"
++     msgstr[2] "And this is the second plural form: %s"
+- msgid "Removed text"
+-     msgstr "Removed translation"
+~ msgctxt "Ctx" msgid "My text"
+-     msgstr "Translated text in a context"
++     msgstr "Updated text in a context"
+`
+
+	d := Diff(a, b)
+	if d != expected {
+		t.Errorf("Expected diff:\n%s\nbut got:\n%s", expected, d)
+	}
+
+	// Test equivalent catalogs
+	d = Diff(a, a)
+	if d != "" {
+		t.Errorf("Expected empty diff but got:\n%s", d)
+	}
+
+	// Test against an empty catalog
+	d = Diff(new(Po), b)
+	if d == "" {
+		t.Error("Expected a diff against an empty catalog")
+	}
+}
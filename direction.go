@@ -0,0 +1,66 @@
+package gotext
+
+import (
+	"strings"
+)
+
+// Text directions returned by Locale.Direction.
+const (
+	DirectionLTR = "ltr"
+	DirectionRTL = "rtl"
+)
+
+// Languages written right-to-left by default, following the CLDR likely scripts.
+var rtlLanguages = map[string]bool{
+	"ar": true, "arc": true, "ckb": true, "dv": true, "fa": true, "glk": true, "he": true, "iw": true,
+	"ks": true, "lrc": true, "mzn": true, "nqo": true, "ps": true, "sd": true, "syr": true, "ug": true,
+	"ur": true, "yi": true,
+}
+
+// Scripts written right-to-left, by ISO 15924 code.
+var rtlScripts = map[string]bool{
+	"Adlm": true, "Arab": true, "Hebr": true, "Mand": true, "Nkoo": true, "Rohg": true, "Samr": true,
+	"Syrc": true, "Thaa": true,
+}
+
+// Direction returns the text direction of the language of this Locale: "rtl" for languages written right-to-left,
+// like Arabic, Hebrew or Persian, and "ltr" otherwise.
+// A script code in the language code (az_Arab, ks_Deva) takes precedence over the language default script.
+// The "@latin" modifier (sr@latin) selects the latin script.
+func (l *Locale) Direction() string {
+	return textDirection(l.lang)
+}
+
+// textDirection returns the text direction for the given language code (lang).
+func textDirection(lang string) string {
+	// Latin modifier
+	if i := strings.Index(lang, "@"); i != -1 {
+		if strings.EqualFold(lang[i+1:], "latin") {
+			return DirectionLTR
+		}
+		lang = lang[:i]
+	}
+
+	// Remove encoding
+	if i := strings.Index(lang, "."); i != -1 {
+		lang = lang[:i]
+	}
+
+	parts := strings.Split(strings.Replace(lang, "-", "_", -1), "_")
+
+	// Look for a script code
+	for _, p := range parts[1:] {
+		if len(p) == 4 {
+			if rtlScripts[strings.ToUpper(p[:1])+strings.ToLower(p[1:])] {
+				return DirectionRTL
+			}
+			return DirectionLTR
+		}
+	}
+
+	if rtlLanguages[strings.ToLower(parts[0])] {
+		return DirectionRTL
+	}
+
+	return DirectionLTR
+}
@@ -0,0 +1,28 @@
+package gotext
+
+import (
+	"testing"
+)
+
+func TestLocaleDirection(t *testing.T) {
+	for lang, expected := range map[string]string{
+		"en_US":       "ltr",
+		"es":          "ltr",
+		"ar":          "rtl",
+		"ar_EG.UTF-8": "rtl",
+		"he-IL":       "rtl",
+		"fa_IR":       "rtl",
+		"ur":          "rtl",
+		"az_Arab":     "rtl",
+		"pa-arab-PK":  "rtl",
+		"ks_Deva":     "ltr",
+		"uz_Latn_UZ":  "ltr",
+		"sd@latin":    "ltr",
+		"ZH_hant_TW":  "ltr",
+		"":            "ltr",
+	} {
+		if dir := NewLocale("/tmp", lang).Direction(); dir != expected {
+			t.Errorf("Expected '%s' for '%s' but got '%s'", expected, lang, dir)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package gotext
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+/*
+EmbedCatalogs looks for the PO files in the given library directory (dir) and writes to w the source of a Go file
+that registers all of them with the package level functions at init, so translations can be compiled into the binary.
+
+The directory is expected to use the same layout as NewLocale: one directory per language code
+containing one PO file per domain, like "es/default.po" or "pt_BR/extras.po".
+Only files with the ".po" extension found directly on each language directory are embedded.
+
+The generated file has a single init function calling RegisterCatalog for each catalog found,
+sorted by language and domain:
+
+    // Code generated by gotext.EmbedCatalogs. DO NOT EDIT.
+
+    package main
+
+    import "github.com/leonelquinteros/gotext"
+
+    func init() {
+        gotext.RegisterCatalog("es", "default", "msgid \"My text\"\nmsgstr \"Mi texto\"\n")
+    }
+
+The package name is taken from the GOPACKAGE environment variable set by go generate, defaulting to "main".
+Once the generated file is compiled, the package functions use the embedded catalogs for the configured language
+without reading the library directory. A small generator program can be run by go generate:
+
+    //go:generate go run gen_catalogs.go
+
+    // gen_catalogs.go
+    // +build ignore
+
+    package main
+
+    import (
+        "os"
+
+        "github.com/leonelquinteros/gotext"
+    )
+
+    func main() {
+        f, _ := os.Create("catalogs.go")
+        defer f.Close()
+
+        if err := gotext.EmbedCatalogs("locales", f); err != nil {
+            panic(err)
+        }
+    }
+
+It returns an error if the directory can't be read or writing to w fails.
+*/
+func EmbedCatalogs(dir string, w io.Writer) error {
+	// Get package name
+	pkg := os.Getenv("GOPACKAGE")
+	if pkg == "" {
+		pkg = "main"
+	}
+
+	// Get language directories
+	langs, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteString("// Code generated by gotext.EmbedCatalogs. DO NOT EDIT.\n\n")
+	buf.WriteString("package " + pkg + "\n\n")
+	buf.WriteString("import \"github.com/leonelquinteros/gotext\"\n\n")
+	buf.WriteString("func init() {\n")
+
+	for _, lang := range langs {
+		if !lang.IsDir() {
+			continue
+		}
+
+		// Get domain files
+		files, err := ioutil.ReadDir(filepath.Join(dir, lang.Name()))
+		if err != nil {
+			return err
+		}
+
+		for _, f := range files {
+			if f.IsDir() || filepath.Ext(f.Name()) != ".po" {
+				continue
+			}
+
+			data, err := ioutil.ReadFile(filepath.Join(dir, lang.Name(), f.Name()))
+			if err != nil {
+				return err
+			}
+
+			dom := strings.TrimSuffix(f.Name(), ".po")
+			buf.WriteString("\tgotext.RegisterCatalog(" + strconv.Quote(lang.Name()) + ", " +
+				strconv.Quote(dom) + ", " + strconv.Quote(string(data)) + ")\n")
+		}
+	}
+
+	buf.WriteString("}\n")
+
+	_, err = w.Write(buf.Bytes())
+
+	return err
+}
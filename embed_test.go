@@ -0,0 +1,104 @@
+package gotext
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestEmbedCatalogs(t *testing.T) {
+	// Set library content
+	files := map[string]string{
+		"embed/es/default.po":    "msgid \"My text\"\nmsgstr \"Mi texto\"\n",
+		"embed/es/notes.txt":     "Not a catalog",
+		"embed/fr/extras.po":     "msgid \"My text\"\nmsgstr \"Mon texte\"\n",
+		"embed/fr/sub/nested.po": "",
+		"embed/README":           "Not a language",
+	}
+
+	for name, str := range files {
+		filename := path.Clean("/tmp" + string(os.PathSeparator) + name)
+
+		err := os.MkdirAll(path.Dir(filename), os.ModePerm)
+		if err != nil {
+			t.Fatalf("Can't create test directory: %s", err.Error())
+		}
+
+		err = ioutil.WriteFile(filename, []byte(str), 0644)
+		if err != nil {
+			t.Fatalf("Can't write to test file: %s", err.Error())
+		}
+	}
+
+	os.Setenv("GOPACKAGE", "catalogs")
+	defer os.Unsetenv("GOPACKAGE")
+
+	var buf bytes.Buffer
+	err := EmbedCatalogs("/tmp/embed", &buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	expected := `// Code generated by gotext.EmbedCatalogs. DO NOT EDIT.
+
+package catalogs
+
+import "github.com/leonelquinteros/gotext"
+
+func init() {
+	gotext.RegisterCatalog("es", "default", "msgid \"My text\"\nmsgstr \"Mi texto\"\n")
+	gotext.RegisterCatalog("fr", "extras", "msgid \"My text\"\nmsgstr \"Mon texte\"\n")
+}
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected generated code:\n%s", buf.String())
+	}
+
+	// Missing directory
+	if err := EmbedCatalogs("/tmp/embed/missing", &buf); err == nil {
+		t.Error("Expected error for missing directory")
+	}
+}
+
+func TestRegisterCatalog(t *testing.T) {
+	defer Reset()
+	defer ResetCatalogs()
+
+	RegisterCatalog("xx", "default", "msgid \"My text\"\nmsgstr \"Generic text\"\n\nmsgid \"Other\"\nmsgstr \"Generic other\"\n")
+	RegisterCatalog("xx", "extras", "msgid \"My text\"\nmsgstr \"Extras text\"\n")
+	RegisterCatalog("xx_YY", "default", "msgid \"My text\"\nmsgstr \"Regional text\"\n")
+
+	Configure("/tmp/missing", "xx_YY", "default")
+
+	tr := Get("My text")
+	if tr != "Regional text" {
+		t.Errorf("Expected 'Regional text' but got '%s'", tr)
+	}
+
+	tr = Get("Other")
+	if tr != "Other" {
+		t.Errorf("Expected 'Other' but got '%s'", tr)
+	}
+
+	tr = GetD("extras", "My text")
+	if tr != "Extras text" {
+		t.Errorf("Expected 'Extras text' but got '%s'", tr)
+	}
+
+	SetLanguage("xx")
+
+	tr = Get("Other")
+	if tr != "Generic other" {
+		t.Errorf("Expected 'Generic other' but got '%s'", tr)
+	}
+
+	// Package domain is used by every function without a domain parameter
+	SetDomain("extras")
+
+	tr = GetN("My text", "My texts", 1)
+	if tr != "Extras text" {
+		t.Errorf("Expected 'Extras text' but got '%s'", tr)
+	}
+}
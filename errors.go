@@ -0,0 +1,26 @@
+package gotext
+
+// LocalizableError is implemented by errors that can be translated.
+// MsgID returns the msgid to look for on the catalog and Args the parameters to be inserted on it
+// using the fmt.Printf syntax.
+type LocalizableError interface {
+	error
+	MsgID() string
+	Args() []interface{}
+}
+
+// Error returns the translation of the given error (err) using the default domain ("default" unless changed with SetDomain).
+// If the error implements LocalizableError, its MsgID and Args are used to get the translation.
+// Otherwise, the original err.Error() message is returned.
+// It returns an empty string for nil errors.
+func (l *Locale) Error(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	if e, ok := err.(LocalizableError); ok {
+		return l.Get(e.MsgID(), e.Args()...)
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,81 @@
+package gotext
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+)
+
+type testError struct {
+	id   string
+	args []interface{}
+}
+
+func (e testError) Error() string {
+	return fmt.Sprintf(e.id, e.args...)
+}
+
+func (e testError) MsgID() string {
+	return e.id
+}
+
+func (e testError) Args() []interface{} {
+	return e.args
+}
+
+func TestLocaleError(t *testing.T) {
+	// Set PO content
+	str := `
+msgid "File %s not found"
+msgstr "Archivo %s no encontrado"
+    `
+
+	// Create Locales directory
+	dirname := path.Clean("/tmp" + string(os.PathSeparator) + "es")
+	err := os.MkdirAll(dirname, os.ModePerm)
+	if err != nil {
+		t.Fatalf("Can't create test directory: %s", err.Error())
+	}
+
+	// Write PO content to default domain file
+	filename := path.Clean(dirname + string(os.PathSeparator) + "default.po")
+
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatalf("Can't create test file: %s", err.Error())
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(str)
+	if err != nil {
+		t.Fatalf("Can't write to test file: %s", err.Error())
+	}
+
+	l := NewLocale("/tmp", "es")
+	l.AddDomain("default")
+
+	// Test localizable errors
+	tr := l.Error(testError{id: "File %s not found", args: []interface{}{"test.txt"}})
+	if tr != "Archivo test.txt no encontrado" {
+		t.Errorf("Expected 'Archivo test.txt no encontrado' but got '%s'", tr)
+	}
+
+	tr = l.Error(testError{id: "Permission denied"})
+	if tr != "Permission denied" {
+		t.Errorf("Expected 'Permission denied' but got '%s'", tr)
+	}
+
+	// Test regular errors
+	tr = l.Error(errors.New("File %s not found"))
+	if tr != "File %s not found" {
+		t.Errorf("Expected 'File %%s not found' but got '%s'", tr)
+	}
+
+	// Test nil errors
+	tr = l.Error(nil)
+	if tr != "" {
+		t.Errorf("Expected '' but got '%s'", tr)
+	}
+}
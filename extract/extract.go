@@ -0,0 +1,630 @@
+/*
+Package extract finds the translatable strings used on Go source files and builds the PO templates (.pot files)
+to be translated, like xgettext does for other languages.
+
+Strings are found on the calls to the translation functions and methods of the gotext package:
+Get, GetN, GetC, GetNC, GetD, GetND, GetDC and GetNDC, their slice vars variants GetNDv and GetNDCv,
+and their named vars variants like GetNamed or GetNDCNamed,
+either as package level functions, called through the name the file imports the gotext package with,
+or as methods of the gotext values of the same file, like a Locale or a Po object.
+Only string literals are extracted, so calls using variables are skipped.
+
+Source files aren't type checked, so the values are known by their declarations on the same file:
+variables, parameters, struct fields and function results declared with a gotext type, like *gotext.Locale,
+or assigned from a gotext constructor, like gotext.NewLocale, and the elements of maps and slices of gotext types.
+Calls on any other value, like the Get methods of http.Header or url.Values, aren't extracted.
+
+Example:
+
+    import "github.com/leonelquinteros/gotext/extract"
+
+    func main() {
+        e := extract.New()
+        if err := e.ParseDir("."); err != nil {
+            panic(err)
+        }
+
+        for dom, pot := range e.Catalogs() {
+            pot.DumpFile(dom + ".pot")
+        }
+    }
+
+Each entry includes the source references ("#:") of the calls using it, and the comments starting with "TRANSLATORS:"
+written on the line before the call, or at the end of the same line, as extracted comments ("#.").
+*/
+package extract
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/leonelquinteros/gotext"
+)
+
+// Comment prefix of the comments extracted for translators.
+const commentPrefix = "TRANSLATORS:"
+
+// Import path of the gotext package.
+const importPath = "github.com/leonelquinteros/gotext"
+
+// Maximum amount of declarations followed to find the type of a value.
+const maxTypeDepth = 16
+
+// Arguments positions of the translation functions: domain, msgid, msgid_plural and context, or -1 if not used.
+type keyword struct {
+	dom, id, plural, ctx int
+}
+
+// Translation functions and their arguments positions.
+var keywords = map[string]keyword{
+	"Get":    {dom: -1, id: 0, plural: -1, ctx: -1},
+	"GetN":   {dom: -1, id: 0, plural: 1, ctx: -1},
+	"GetC":   {dom: -1, id: 0, plural: -1, ctx: 1},
+	"GetNC":  {dom: -1, id: 0, plural: 1, ctx: 3},
+	"GetD":   {dom: 0, id: 1, plural: -1, ctx: -1},
+	"GetND":  {dom: 0, id: 1, plural: 2, ctx: -1},
+	"GetDC":  {dom: 0, id: 1, plural: -1, ctx: 2},
+	"GetNDC": {dom: 0, id: 1, plural: 2, ctx: 4},
+
+	// Slice vars variants
+	"GetNDv":  {dom: 0, id: 1, plural: 2, ctx: -1},
+	"GetNDCv": {dom: 0, id: 1, plural: 2, ctx: 4},
+
+	// Named vars variants
+	"GetNamed":    {dom: -1, id: 0, plural: -1, ctx: -1},
+	"GetNNamed":   {dom: -1, id: 0, plural: 1, ctx: -1},
+	"GetCNamed":   {dom: -1, id: 0, plural: -1, ctx: 1},
+	"GetNCNamed":  {dom: -1, id: 0, plural: 1, ctx: 3},
+	"GetDNamed":   {dom: 0, id: 1, plural: -1, ctx: -1},
+	"GetNDNamed":  {dom: 0, id: 1, plural: 2, ctx: -1},
+	"GetDCNamed":  {dom: 0, id: 1, plural: -1, ctx: 2},
+	"GetNDCNamed": {dom: 0, id: 1, plural: 2, ctx: 4},
+}
+
+/*
+Extractor collects the translatable strings found on Go source files, by domain.
+Calls without a domain argument, like Get, use the default domain, which is "default" unless changed with SetDomain.
+*/
+type Extractor struct {
+	// Templates by domain.
+	catalogs map[string]*gotext.Po
+
+	// Domain used by the calls without a domain argument.
+	domain string
+
+	// Directory the source references are relative to.
+	base string
+}
+
+// New creates and initializes a new Extractor object.
+func New() *Extractor {
+	return &Extractor{
+		catalogs: make(map[string]*gotext.Po),
+		domain:   "default",
+	}
+}
+
+// SetDomain sets the domain (dom) used by the calls without a domain argument, like Get and GetN.
+func (e *Extractor) SetDomain(dom string) {
+	e.domain = dom
+}
+
+/*
+ParseDir parses every Go source file found on the given directory (root) and its subdirectories,
+skipping the "vendor" and "testdata" directories and the ones starting with "." or "_", like the go tool does.
+Source references are written relative to root.
+It returns the first error found reading or parsing a file.
+*/
+func (e *Extractor) ParseDir(root string) error {
+	e.base = root
+
+	return filepath.Walk(root, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		base := info.Name()
+
+		if info.IsDir() {
+			if name != root && (base == "vendor" || base == "testdata" || strings.HasPrefix(base, ".") || strings.HasPrefix(base, "_")) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if filepath.Ext(base) != ".go" {
+			return nil
+		}
+
+		return e.ParseFile(name, nil)
+	})
+}
+
+// ParseFile parses the Go source file at the given path (filename), or the given source (src) if it isn't nil,
+// collecting its translatable strings. The source can be a string, a []byte or an io.Reader, like on go/parser.
+// It returns the error if the file can't be read or parsed.
+func (e *Extractor) ParseFile(filename string, src interface{}) error {
+	fset := token.NewFileSet()
+
+	f, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+
+	// Get the column of the first node of each line, to tell apart the comments written at the end of a line
+	first := make(map[int]int)
+	ast.Inspect(f, func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+
+		pos := fset.Position(n.Pos())
+		if col, ok := first[pos.Line]; !ok || pos.Column < col {
+			first[pos.Line] = pos.Column
+		}
+
+		return true
+	})
+
+	// Index translator comments: the ones on their own lines by the line they end,
+	// and the ones at the end of a line by that line
+	c := comments{leading: make(map[int]string), trailing: make(map[int]string)}
+	for _, group := range f.Comments {
+		text := strings.TrimSpace(group.Text())
+		if !strings.HasPrefix(text, commentPrefix) {
+			continue
+		}
+		text = strings.Join(strings.Fields(text), " ")
+
+		pos := fset.Position(group.Pos())
+		if col, ok := first[pos.Line]; ok && col < pos.Column {
+			c.trailing[pos.Line] = text
+		} else {
+			c.leading[fset.Position(group.End()).Line] = text
+		}
+	}
+
+	s := &source{fset: fset, comments: c, pkg: importName(f), fields: structFields(f)}
+	if s.pkg == "" {
+		return nil
+	}
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		e.call(s, call)
+
+		return true
+	})
+
+	return nil
+}
+
+// comments holds the translator comments of a file by line.
+type comments struct {
+	leading, trailing map[int]string
+}
+
+// get returns the translator comment for the given line:
+// the comment written on the lines right before it, or at the end of it.
+func (c comments) get(line int) string {
+	if text, ok := c.leading[line-1]; ok {
+		return text
+	}
+
+	return c.trailing[line]
+}
+
+// source holds the parsed file whose calls are being collected.
+type source struct {
+	fset     *token.FileSet
+	comments comments
+
+	// Name the file imports the gotext package with, or "." for dot imports.
+	pkg string
+
+	// Types of the struct fields declared on the file, by field name.
+	fields map[string]ast.Expr
+}
+
+// importName returns the name the given file (f) imports the gotext package with, "." for dot imports,
+// or an empty string if it isn't imported.
+func importName(f *ast.File) string {
+	for _, spec := range f.Imports {
+		if p, err := strconv.Unquote(spec.Path.Value); err != nil || p != importPath {
+			continue
+		}
+
+		if spec.Name == nil {
+			return "gotext"
+		}
+		if spec.Name.Name != "_" {
+			return spec.Name.Name
+		}
+	}
+
+	return ""
+}
+
+// structFields returns the types of the struct fields declared on the given file (f), by field name.
+func structFields(f *ast.File) map[string]ast.Expr {
+	fields := make(map[string]ast.Expr)
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		st, ok := n.(*ast.StructType)
+		if !ok {
+			return true
+		}
+
+		for _, field := range st.Fields.List {
+			for _, name := range field.Names {
+				fields[name.Name] = field.Type
+			}
+		}
+
+		return true
+	})
+
+	return fields
+}
+
+// isPackage returns true if the given expression (x) is the gotext package name.
+// Package names aren't resolved by the parser, unlike the local declarations.
+func (s *source) isPackage(x ast.Expr) bool {
+	id, ok := x.(*ast.Ident)
+
+	return ok && id.Obj == nil && s.pkg != "." && id.Name == s.pkg
+}
+
+// isGotextType returns true if the given type expression (t) is a type of the gotext package or a pointer to it,
+// like *gotext.Locale, or a call to one of its constructors, standing for their result.
+func (s *source) isGotextType(t ast.Expr) bool {
+	switch t := t.(type) {
+	case *ast.StarExpr:
+		return s.isGotextType(t.X)
+	case *ast.ParenExpr:
+		return s.isGotextType(t.X)
+	case *ast.SelectorExpr:
+		return s.isPackage(t.X)
+	case *ast.Ident:
+		return s.pkg == "." && t.Obj == nil
+	}
+
+	return false
+}
+
+// typeOf returns the type expression of the given value expression (x), as declared on the file,
+// or nil if it can't be found. Calls to the gotext constructors return their function name,
+// which isGotextType accepts as a gotext type.
+func (s *source) typeOf(x ast.Expr, depth int) ast.Expr {
+	if depth > maxTypeDepth {
+		return nil
+	}
+	depth++
+
+	switch x := x.(type) {
+	case *ast.ParenExpr:
+		return s.typeOf(x.X, depth)
+
+	case *ast.StarExpr:
+		return s.typeOf(x.X, depth)
+
+	case *ast.UnaryExpr:
+		if x.Op == token.AND {
+			return s.typeOf(x.X, depth)
+		}
+
+	case *ast.CompositeLit:
+		return x.Type
+
+	case *ast.IndexExpr:
+		switch t := s.typeOf(x.X, depth).(type) {
+		case *ast.MapType:
+			return t.Value
+		case *ast.ArrayType:
+			return t.Elt
+		}
+
+	case *ast.SelectorExpr:
+		if !s.isPackage(x.X) {
+			return s.fields[x.Sel.Name]
+		}
+
+	case *ast.CallExpr:
+		return s.resultOf(x, depth)
+
+	case *ast.Ident:
+		if x.Obj != nil {
+			return s.declaredType(x, x.Obj.Decl, depth)
+		}
+	}
+
+	return nil
+}
+
+// resultOf returns the type expression of the result of the given call (call), as typeOf does.
+func (s *source) resultOf(call *ast.CallExpr, depth int) ast.Expr {
+	switch fun := call.Fun.(type) {
+	case *ast.SelectorExpr:
+		// Constructors
+		if s.isPackage(fun.X) && strings.HasPrefix(fun.Sel.Name, "New") {
+			return fun
+		}
+
+	case *ast.Ident:
+		if fun.Name == "new" && fun.Obj == nil && len(call.Args) == 1 {
+			return call.Args[0]
+		}
+
+		if fun.Obj == nil {
+			if s.pkg == "." && strings.HasPrefix(fun.Name, "New") {
+				return fun
+			}
+			return nil
+		}
+
+		if d, ok := fun.Obj.Decl.(*ast.FuncDecl); ok && d.Type.Results != nil && len(d.Type.Results.List) > 0 {
+			return d.Type.Results.List[0].Type
+		}
+	}
+
+	return nil
+}
+
+// declaredType returns the type expression of the given identifier (id) from its declaration (decl), as typeOf does.
+func (s *source) declaredType(id *ast.Ident, decl interface{}, depth int) ast.Expr {
+	switch d := decl.(type) {
+	case *ast.Field:
+		return d.Type
+
+	case *ast.ValueSpec:
+		if d.Type != nil {
+			return d.Type
+		}
+
+		for i, name := range d.Names {
+			if name.Name == id.Name && i < len(d.Values) {
+				return s.typeOf(d.Values[i], depth)
+			}
+		}
+
+	case *ast.AssignStmt:
+		for i, lhs := range d.Lhs {
+			if name, ok := lhs.(*ast.Ident); !ok || name.Name != id.Name {
+				continue
+			}
+
+			if len(d.Rhs) == len(d.Lhs) {
+				return s.typeOf(d.Rhs[i], depth)
+			}
+
+			if len(d.Rhs) != 1 {
+				return nil
+			}
+
+			// Range values
+			if r, ok := d.Rhs[0].(*ast.UnaryExpr); ok && r.Op == token.RANGE {
+				switch t := s.typeOf(r.X, depth).(type) {
+				case *ast.MapType:
+					if i == 0 {
+						return t.Key
+					}
+					return t.Value
+				case *ast.ArrayType:
+					if i == 1 {
+						return t.Elt
+					}
+				}
+				return nil
+			}
+
+			// First result of a call
+			if i == 0 {
+				return s.typeOf(d.Rhs[0], depth)
+			}
+		}
+	}
+
+	return nil
+}
+
+// translationCall returns the name of the translation function or method called by the given call (call),
+// or an empty string if it isn't a call to the gotext package or to a gotext value.
+func (s *source) translationCall(call *ast.CallExpr) string {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		// Dot imports
+		if s.pkg == "." && fun.Obj == nil {
+			return fun.Name
+		}
+
+	case *ast.SelectorExpr:
+		if s.isPackage(fun.X) || s.isGotextType(s.typeOf(fun.X, 0)) {
+			return fun.Sel.Name
+		}
+	}
+
+	return ""
+}
+
+// call collects the strings used by the given call (call), if it's a translation function call.
+func (e *Extractor) call(s *source, call *ast.CallExpr) {
+	name := s.translationCall(call)
+	if name == "" {
+		return
+	}
+
+	kw, ok := keywords[name]
+	if !ok {
+		return
+	}
+
+	t := gotext.NewTranslation()
+	dom := e.domain
+
+	for _, arg := range []struct {
+		pos int
+		dst *string
+	}{
+		{kw.id, &t.ID},
+		{kw.plural, &t.PluralID},
+		{kw.ctx, &t.Context},
+		{kw.dom, &dom},
+	} {
+		if arg.pos < 0 {
+			continue
+		}
+
+		if arg.pos >= len(call.Args) {
+			return
+		}
+
+		str, ok := stringValue(call.Args[arg.pos])
+		if !ok {
+			// Skip calls using variables
+			return
+		}
+		*arg.dst = str
+	}
+
+	if t.ID == "" {
+		return
+	}
+
+	pos := s.fset.Position(call.Pos())
+
+	e.add(dom, t, e.reference(pos), s.comments.get(pos.Line))
+}
+
+// add saves the given translation (t) on the template of the given domain (dom),
+// along with its source reference (ref) and translator comment (comment), merging it with any previous occurrence.
+func (e *Extractor) add(dom string, t *gotext.Translation, ref, comment string) {
+	pot := e.catalogs[dom]
+	if pot == nil {
+		pot = gotext.NewPoTemplate()
+		e.catalogs[dom] = pot
+	}
+
+	prev := pot.GetEntry(t.ID)
+	if t.Context != "" {
+		prev = pot.GetEntryC(t.ID, t.Context)
+	}
+
+	if prev != nil {
+		// Keep the msgid_plural of the plural calls found after the singular ones
+		if prev.PluralID == "" && t.PluralID != "" {
+			prev.PluralID = t.PluralID
+			prev.Trs[1] = ""
+		}
+		t = prev
+	} else {
+		t.Trs[0] = ""
+		if t.PluralID != "" {
+			t.Trs[1] = ""
+		}
+	}
+
+	t.References = append(t.References, ref)
+
+	if comment != "" && !contains(t.ExtractedComments, comment) {
+		t.ExtractedComments = append(t.ExtractedComments, comment)
+	}
+
+	pot.SetEntry(t)
+}
+
+// reference returns the source reference for the given position (pos), relative to the parsed directory.
+func (e *Extractor) reference(pos token.Position) string {
+	filename := pos.Filename
+	if e.base != "" {
+		if rel, err := filepath.Rel(e.base, filename); err == nil {
+			filename = rel
+		}
+	}
+
+	return filepath.ToSlash(filename) + ":" + strconv.Itoa(pos.Line)
+}
+
+// Catalogs returns the templates of the strings found so far, keyed by domain.
+// Each template has a header entry and one untranslated entry for every string found, in the order they were found,
+// ready to be written with Po.Write or Po.DumpFile. The templates keep being updated by the following parsed files.
+func (e *Extractor) Catalogs() map[string]*gotext.Po {
+	res := make(map[string]*gotext.Po, len(e.catalogs))
+	for dom, pot := range e.catalogs {
+		res[dom] = pot
+	}
+
+	return res
+}
+
+// Domains returns the sorted names of the domains found so far.
+func (e *Extractor) Domains() []string {
+	doms := make([]string, 0, len(e.catalogs))
+	for dom := range e.catalogs {
+		doms = append(doms, dom)
+	}
+	sort.Strings(doms)
+
+	return doms
+}
+
+// stringValue returns the value of the given expression (expr) if it's a string literal,
+// or a concatenation of string literals.
+func stringValue(expr ast.Expr) (string, bool) {
+	switch x := expr.(type) {
+	case *ast.BasicLit:
+		if x.Kind != token.STRING {
+			return "", false
+		}
+
+		str, err := strconv.Unquote(x.Value)
+		if err != nil {
+			return "", false
+		}
+
+		return str, true
+
+	case *ast.BinaryExpr:
+		if x.Op != token.ADD {
+			return "", false
+		}
+
+		a, ok := stringValue(x.X)
+		if !ok {
+			return "", false
+		}
+
+		b, ok := stringValue(x.Y)
+		if !ok {
+			return "", false
+		}
+
+		return a + b, true
+
+	case *ast.ParenExpr:
+		return stringValue(x.X)
+	}
+
+	return "", false
+}
+
+// contains returns true if the given list contains the given string (str).
+func contains(list []string, str string) bool {
+	for _, s := range list {
+		if s == str {
+			return true
+		}
+	}
+
+	return false
+}
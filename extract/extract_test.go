@@ -0,0 +1,353 @@
+package extract
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestExtractor(t *testing.T) {
+	// Set Go source
+	src := `package main
+
+import "github.com/leonelquinteros/gotext"
+
+func main() {
+	l := gotext.NewLocale("/tmp", "es")
+
+	// TRANSLATORS: Shown on the
+	// welcome screen.
+	println(gotext.Get("My text"))
+	println(l.Get("My text"))
+	println(gotext.GetN("One file", "%d files", 2, 2))
+	println(l.GetC("Open", "Menu")) // TRANSLATORS: Menu entry
+	println(gotext.GetNC("One file", "%d files", 2, "Ctx", 2))
+	println(gotext.GetD("extras", "Extras "+"text"))
+	println(gotext.GetND("extras", "One item", "%d items", 2))
+	println(l.GetDC("extras", "Open", "Menu"))
+	println(l.GetNDC("extras", "One item", "%d items", 2, "Ctx"))
+
+	// Not extracted
+	str := "Variable"
+	println(gotext.Get(str))
+	println(gotext.GetD(str, "Variable domain"))
+	println(gotext.Get(""))
+	println(gotext.GetN("Missing plural"))
+	println(Other("Other function"))
+}
+`
+
+	e := New()
+	if err := e.ParseFile("main.go", src); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	if doms := e.Domains(); len(doms) != 2 || doms[0] != "default" || doms[1] != "extras" {
+		t.Fatalf("Unexpected domains: %v", doms)
+	}
+
+	data, err := e.Catalogs()["default"].MarshalText()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	expected := `#, fuzzy
+msgid ""
+msgstr ""
+"Project-Id-Version: PACKAGE VERSION\n"
+"MIME-Version: 1.0\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+"Content-Transfer-Encoding: 8bit\n"
+"Plural-Forms: nplurals=INTEGER; plural=EXPRESSION;\n"
+
+#. TRANSLATORS: Shown on the welcome screen.
+#: main.go:10 main.go:11
+msgid "My text"
+msgstr ""
+
+#: main.go:12
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] ""
+msgstr[1] ""
+
+#. TRANSLATORS: Menu entry
+#: main.go:13
+msgctxt "Menu"
+msgid "Open"
+msgstr ""
+
+#: main.go:14
+msgctxt "Ctx"
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] ""
+msgstr[1] ""
+`
+	if string(data) != expected {
+		t.Errorf("Unexpected content:\n%s", data)
+	}
+
+	data, err = e.Catalogs()["extras"].MarshalText()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	expected = `#, fuzzy
+msgid ""
+msgstr ""
+"Project-Id-Version: PACKAGE VERSION\n"
+"MIME-Version: 1.0\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+"Content-Transfer-Encoding: 8bit\n"
+"Plural-Forms: nplurals=INTEGER; plural=EXPRESSION;\n"
+
+#: main.go:15
+msgid "Extras text"
+msgstr ""
+
+#: main.go:16
+msgid "One item"
+msgid_plural "%d items"
+msgstr[0] ""
+msgstr[1] ""
+
+#: main.go:17
+msgctxt "Menu"
+msgid "Open"
+msgstr ""
+
+#: main.go:18
+msgctxt "Ctx"
+msgid "One item"
+msgid_plural "%d items"
+msgstr[0] ""
+msgstr[1] ""
+`
+	if string(data) != expected {
+		t.Errorf("Unexpected content:\n%s", data)
+	}
+
+	// Named vars variants
+	err = e.ParseFile("named.go", `package main
+
+import "github.com/leonelquinteros/gotext"
+
+func main(l *gotext.Locale, vars map[string]interface{}) {
+	println(l.GetNamed("Hello, {name}", vars))
+	println(l.GetNDCNamed("extras", "One {item}", "{count} {item}s", 2, "Named", vars))
+}
+`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	if info := e.Catalogs()["default"].GetTranslationInfo("Hello, {name}"); len(info.References) != 1 || info.References[0] != "named.go:6" {
+		t.Errorf("Unexpected references: %v", info.References)
+	}
+
+	if tr := e.Catalogs()["extras"].GetEntryC("One {item}", "Named"); tr == nil || tr.PluralID != "{count} {item}s" {
+		t.Errorf("Unexpected entry: %v", tr)
+	}
+
+	// Slice vars variants, and plural calls after the singular ones
+	err = e.ParseFile("slice.go", `package main
+
+import "github.com/leonelquinteros/gotext"
+
+func main(l *gotext.Locale, vars []interface{}) {
+	println(l.GetNDv("extras", "One slice", "%d slices", 2, vars))
+	println(l.GetNDCv("extras", "One slice", "%d slices", 2, "Slice", vars))
+	println(l.Get("One tab"))
+	println(l.GetN("One tab", "%d tabs", 2))
+}
+`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	if tr := e.Catalogs()["extras"].GetEntry("One slice"); tr == nil || tr.PluralID != "%d slices" {
+		t.Errorf("Unexpected entry: %v", tr)
+	}
+
+	if tr := e.Catalogs()["extras"].GetEntryC("One slice", "Slice"); tr == nil || tr.PluralID != "%d slices" {
+		t.Errorf("Unexpected entry: %v", tr)
+	}
+
+	if tr := e.Catalogs()["default"].GetEntry("One tab"); tr == nil || tr.PluralID != "%d tabs" || len(tr.References) != 2 {
+		t.Errorf("Unexpected entry: %v", tr)
+	} else if data, _ := e.Catalogs()["default"].MarshalText(); !strings.Contains(string(data), "msgid_plural \"%d tabs\"\nmsgstr[0] \"\"\nmsgstr[1] \"\"\n") {
+		t.Errorf("Unexpected content:\n%s", data)
+	}
+
+	// Invalid source
+	if err := e.ParseFile("invalid.go", "package"); err == nil {
+		t.Error("Expected error parsing invalid source")
+	}
+}
+
+func TestExtractorParseDir(t *testing.T) {
+	// Create source tree
+	files := map[string]string{
+		"main.go":             "package main\n\nimport \"github.com/leonelquinteros/gotext\"\n\nfunc main() { gotext.Get(\"Main text\") }\n",
+		"pkg/pkg.go":          "package pkg\n\nimport \"github.com/leonelquinteros/gotext\"\n\nfunc F() { gotext.Get(\"Package text\") }\n",
+		"pkg/README":          "gotext.Get(\"Not a Go file\")\n",
+		"vendor/dep/dep.go":   "package dep\n\nimport \"github.com/leonelquinteros/gotext\"\n\nfunc F() { gotext.Get(\"Vendored text\") }\n",
+		"testdata/data.go":    "package data\n\nimport \"github.com/leonelquinteros/gotext\"\n\nfunc F() { gotext.Get(\"Test data text\") }\n",
+		".hidden/hidden.go":   "package hidden\n\nimport \"github.com/leonelquinteros/gotext\"\n\nfunc F() { gotext.Get(\"Hidden text\") }\n",
+		"_ignored/ignored.go": "package ignored\n\nimport \"github.com/leonelquinteros/gotext\"\n\nfunc F() { gotext.Get(\"Ignored text\") }\n",
+	}
+
+	root := path.Clean("/tmp" + string(os.PathSeparator) + "extract")
+	os.RemoveAll(root)
+
+	for name, src := range files {
+		filename := path.Join(root, name)
+
+		err := os.MkdirAll(path.Dir(filename), os.ModePerm)
+		if err != nil {
+			t.Fatalf("Can't create test directory: %s", err.Error())
+		}
+
+		err = ioutil.WriteFile(filename, []byte(src), 0644)
+		if err != nil {
+			t.Fatalf("Can't write to test file: %s", err.Error())
+		}
+	}
+
+	e := New()
+	e.SetDomain("app")
+	if err := e.ParseDir(root); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	pot := e.Catalogs()["app"]
+	if pot == nil {
+		t.Fatal("Expected 'app' domain")
+	}
+
+	if info := pot.GetTranslationInfo("Main text"); len(info.References) != 1 || info.References[0] != "main.go:5" {
+		t.Errorf("Unexpected references: %v", info.References)
+	}
+
+	if info := pot.GetTranslationInfo("Package text"); len(info.References) != 1 || info.References[0] != "pkg/pkg.go:5" {
+		t.Errorf("Unexpected references: %v", info.References)
+	}
+
+	for _, str := range []string{"Not a Go file", "Vendored text", "Test data text", "Hidden text", "Ignored text"} {
+		if pot.GetEntry(str) != nil {
+			t.Errorf("Unexpected entry '%s'", str)
+		}
+	}
+
+	// Missing directory
+	if err := New().ParseDir(path.Join(root, "missing")); err == nil {
+		t.Error("Expected error for missing directory")
+	}
+}
+
+func TestExtractorReceivers(t *testing.T) {
+	// Set Go source
+	src := `package main
+
+import (
+	"net/http"
+	"net/url"
+
+	i18n "github.com/leonelquinteros/gotext"
+)
+
+type server struct {
+	locale  *i18n.Locale
+	locales map[string]*i18n.Locale
+	header  http.Header
+}
+
+func catalog() *i18n.Po {
+	return i18n.NewPo()
+}
+
+func (s *server) handle(w http.ResponseWriter, r *http.Request, q url.Values) {
+	po := new(i18n.Po)
+	var tr i18n.Translator = po
+	m := i18n.NewMo()
+
+	println(i18n.Get("Package"))
+	println(s.locale.Get("Field"))
+	println(s.locales["es"].Get("Map"))
+	println(catalog().Get("Result"))
+	println(po.Get("New"))
+	println(tr.Get("Interface"))
+	println(m.Get("Constructor"))
+	println(i18n.NewLocale("/tmp", "es").Get("Chain"))
+	for _, l := range s.locales {
+		println(l.Get("Range"))
+	}
+
+	// Not extracted
+	println(r.Header.Get("Accept-Language"))
+	println(r.URL.Query().Get("lang"))
+	println(q.Get("q"))
+	println(s.header.Get("Header"))
+	http.Get("https://example.com")
+	for po := range s.locales {
+		println(po.Get("Key"))
+	}
+	if po := q; po != nil {
+		println(po.Get("Shadowed"))
+	}
+}
+`
+
+	e := New()
+	if err := e.ParseFile("main.go", src); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	pot := e.Catalogs()["default"]
+	if pot == nil {
+		t.Fatal("Expected 'default' domain")
+	}
+
+	for _, str := range []string{"Package", "Field", "Map", "Result", "New", "Interface", "Constructor", "Chain", "Range"} {
+		if pot.GetEntry(str) == nil {
+			t.Errorf("Expected entry '%s'", str)
+		}
+	}
+
+	for _, str := range []string{"Accept-Language", "lang", "q", "Header", "https://example.com", "Key", "Shadowed"} {
+		if pot.GetEntry(str) != nil {
+			t.Errorf("Unexpected entry '%s'", str)
+		}
+	}
+
+	// Dot imports
+	e = New()
+	err := e.ParseFile("dot.go", `package main
+
+import . "github.com/leonelquinteros/gotext"
+
+func main() {
+	println(Get("Dot"))
+	println(NewLocale("/tmp", "es").Get("Dot chain"))
+}
+`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	if pot := e.Catalogs()["default"]; pot == nil || pot.GetEntry("Dot") == nil || pot.GetEntry("Dot chain") == nil {
+		t.Errorf("Expected dot import entries")
+	}
+
+	// Files not importing the gotext package
+	e = New()
+	if err := e.ParseFile("other.go", "package main\n\nfunc main() { Get(\"Other\") }\n"); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if doms := e.Domains(); len(doms) != 0 {
+		t.Errorf("Unexpected domains: %v", doms)
+	}
+}
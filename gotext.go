@@ -16,9 +16,13 @@ For quick/simple translations you can use the package level functions directly.
         println(gotext.GetD("domain2", "Another text on a different domain"))
     }
 
+The package level functions are safe for concurrent use, even while the configuration is being changed:
+translations are always looked up on a fully loaded Locale, either the previous or the new one.
 */
 package gotext
 
+import "sync"
+
 // Global environment variables
 var (
 	// Default domain to look at when no domain is specified. Used by package level functions.
@@ -32,52 +36,165 @@ var (
 
 	// Storage for package level methods
 	storage *Locale
+
+	// PO content registered with RegisterCatalog, by language and domain.
+	catalogs = make(map[string]map[string]string)
+
+	// Sync Mutex for the package configuration and storage.
+	globalMutex sync.RWMutex
 )
 
+// getStorage returns the Locale object used by the package level functions, loading it if needed,
+// so the configuration can be changed while other goroutines are translating.
+func getStorage() *Locale {
+	globalMutex.RLock()
+	s, dom := storage, domain
+	globalMutex.RUnlock()
+
+	if s != nil {
+		if po, _ := s.lookup(dom); po != nil {
+			return s
+		}
+	}
+
+	globalMutex.Lock()
+	defer globalMutex.Unlock()
+
+	loadStorage(false)
+
+	return storage
+}
+
 // loadStorage creates a new Locale object at package level based on the Global variables settings.
-// It's called automatically when trying to use Get or GetD methods.
+// It's called automatically when trying to use Get or GetD methods, with the package Mutex locked.
 func loadStorage(force bool) {
 	if storage == nil || force {
 		storage = NewLocale(library, language)
+
+		// Load registered catalogs
+		for dom, str := range registeredCatalogs(language) {
+			po := storage.newPo()
+			po.Parse(str)
+			storage.setDomain(dom, "", po)
+		}
 	}
 
-	if _, ok := storage.domains[domain]; !ok || force {
+	if po, _ := storage.lookup(domain); po == nil {
 		storage.AddDomain(domain)
 	}
+	storage.SetDomain(domain)
+}
+
+// Reset restores the package level configuration to its defaults, discarding the loaded translations.
+// It's intended to isolate tests using the package level functions, calling it on teardown or TestMain.
+// The catalogs registered with RegisterCatalog are kept, as the ones registered from init functions,
+// like the ones generated by EmbedCatalogs, can't be registered again. Use ResetCatalogs to discard them.
+func Reset() {
+	globalMutex.Lock()
+	defer globalMutex.Unlock()
+
+	domain = "default"
+	language = "en_US"
+	library = "/tmp"
+	storage = nil
+}
+
+// ResetCatalogs discards the catalogs registered with RegisterCatalog, along with the loaded translations,
+// keeping the package level configuration.
+// It's intended for tests registering their own catalogs, as the ones registered from init functions are discarded too.
+func ResetCatalogs() {
+	globalMutex.Lock()
+	defer globalMutex.Unlock()
+
+	storage = nil
+	catalogs = make(map[string]map[string]string)
+}
+
+// RegisterCatalog registers the PO formatted content (str) of a domain (dom) for the given language code (lang),
+// to be used by the package level functions instead of loading the domain file from the library directory.
+// It's intended to be called from init functions, like the ones generated by EmbedCatalogs,
+// before using the package level functions.
+//
+// Registered catalogs are loaded along with the package storage when the language matches,
+// either the full language code or the generic one ("en" catalogs are used for "en_US").
+// Registering the same language and domain again replaces the previous content.
+func RegisterCatalog(lang, dom, str string) {
+	globalMutex.Lock()
+	defer globalMutex.Unlock()
+
+	if catalogs[lang] == nil {
+		catalogs[lang] = make(map[string]string)
+	}
+	catalogs[lang][dom] = str
+}
+
+// registeredCatalogs returns the catalogs registered for the given language by domain,
+// using the full language code ones over the generic language ones.
+func registeredCatalogs(lang string) map[string]string {
+	res := make(map[string]string)
+
+	if len(lang) > 2 {
+		for dom, str := range catalogs[lang[:2]] {
+			res[dom] = str
+		}
+	}
+	for dom, str := range catalogs[lang] {
+		res[dom] = str
+	}
+
+	return res
 }
 
 // GetDomain is the domain getter for the package configuration
 func GetDomain() string {
+	globalMutex.RLock()
+	defer globalMutex.RUnlock()
+
 	return domain
 }
 
 // SetDomain sets the name for the domain to be used at package level.
 // It reloads the corresponding translation file.
 func SetDomain(dom string) {
+	globalMutex.Lock()
+	defer globalMutex.Unlock()
+
 	domain = dom
 	loadStorage(true)
 }
 
 // GetLanguage is the language getter for the package configuration
 func GetLanguage() string {
+	globalMutex.RLock()
+	defer globalMutex.RUnlock()
+
 	return language
 }
 
 // SetLanguage sets the language code to be used at package level.
 // It reloads the corresponding translation file.
 func SetLanguage(lang string) {
+	globalMutex.Lock()
+	defer globalMutex.Unlock()
+
 	language = lang
 	loadStorage(true)
 }
 
 // GetLibrary is the library getter for the package configuration
 func GetLibrary() string {
+	globalMutex.RLock()
+	defer globalMutex.RUnlock()
+
 	return library
 }
 
 // SetLibrary sets the root path for the loale directories and files to be used at package level.
 // It reloads the corresponding translation file.
 func SetLibrary(lib string) {
+	globalMutex.Lock()
+	defer globalMutex.Unlock()
+
 	library = lib
 	loadStorage(true)
 }
@@ -87,6 +204,9 @@ func SetLibrary(lib string) {
 // This function is recommended to be used when changing more than one setting,
 // as using each setter will introduce a I/O overhead because the translation file will be loaded after each set.
 func Configure(lib, lang, dom string) {
+	globalMutex.Lock()
+	defer globalMutex.Unlock()
+
 	library = lib
 	language = lang
 	domain = dom
@@ -97,57 +217,53 @@ func Configure(lib, lang, dom string) {
 // Get uses the default domain globally set to return the corresponding translation of a given string.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func Get(str string, vars ...interface{}) string {
-	return GetD(domain, str, vars...)
+	return getStorage().Get(str, vars...)
 }
 
-// GetN retrieves the (N)th plural form translation for the given string in the "default" domain.
-// If n == 0, usually the singular form of the string is returned as defined in the PO file.
+// GetN retrieves the plural form translation for the given string and count (n) in the default domain globally set.
+// The form is chosen by the Plural-Forms header of the domain catalog, like on Po.GetN.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func GetN(str, plural string, n int, vars ...interface{}) string {
-	return GetND("default", str, plural, n, vars...)
+	return getStorage().GetN(str, plural, n, vars...)
 }
 
 // GetD returns the corresponding translation in the given domain for a given string.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func GetD(dom, str string, vars ...interface{}) string {
-	return GetND(dom, str, str, 0, vars...)
+	// Return translation from the default package Locale storage
+	return getStorage().GetD(dom, str, vars...)
 }
 
-// GetND retrieves the (N)th plural form translation in the given domain for a given string.
+// GetND retrieves the plural form translation in the given domain for a given string and count (n).
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func GetND(dom, str, plural string, n int, vars ...interface{}) string {
-	// Try to load default package Locale storage
-	loadStorage(false)
-
-	// Return translation
-	return storage.GetND(dom, str, plural, n, vars...)
+	// Return translation from the default package Locale storage
+	return getStorage().GetND(dom, str, plural, n, vars...)
 }
 
 // GetC uses the default domain globally set to return the corresponding translation of the given string in the given context.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func GetC(str, ctx string, vars ...interface{}) string {
-	return GetDC(domain, str, ctx, vars...)
+	return getStorage().GetC(str, ctx, vars...)
 }
 
-// GetNC retrieves the (N)th plural form translation for the given string in the given context in the "default" domain.
-// If n == 0, usually the singular form of the string is returned as defined in the PO file.
+// GetNC retrieves the plural form translation for the given string and count (n) in the given context in the default domain globally set.
+// The form is chosen by the Plural-Forms header of the domain catalog, like on Po.GetN.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func GetNC(str, plural string, n int, ctx string, vars ...interface{}) string {
-	return GetNDC("default", str, plural, n, ctx, vars...)
+	return getStorage().GetNC(str, plural, n, ctx, vars...)
 }
 
 // GetDC returns the corresponding translation in the given domain for the given string in the given context.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func GetDC(dom, str, ctx string, vars ...interface{}) string {
-	return GetNDC(dom, str, str, 0, ctx, vars...)
+	// Return translation from the default package Locale storage
+	return getStorage().GetDC(dom, str, ctx, vars...)
 }
 
-// GetNDC retrieves the (N)th plural form translation in the given domain for a given string.
+// GetNDC retrieves the plural form translation in the given domain for a given string and count (n) in the given context.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func GetNDC(dom, str, plural string, n int, ctx string, vars ...interface{}) string {
-	// Try to load default package Locale storage
-	loadStorage(false)
-
-	// Return translation
-	return storage.GetNDC(dom, str, plural, n, ctx, vars...)
+	// Return translation from the default package Locale storage
+	return getStorage().GetNDC(dom, str, plural, n, ctx, vars...)
 }
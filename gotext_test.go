@@ -29,6 +29,44 @@ func TestGettersSetters(t *testing.T) {
 	}
 }
 
+func TestReset(t *testing.T) {
+	RegisterCatalog("es", "default", "msgid \"My text\"\nmsgstr \"Mi texto\"\n")
+	Configure("/tmp/test", "es", "test")
+
+	Reset()
+
+	if dom := GetDomain(); dom != "default" {
+		t.Errorf("Expected GetDomain to return 'default', but got '%s'", dom)
+	}
+
+	if lib := GetLibrary(); lib != "/tmp" {
+		t.Errorf("Expected GetLibrary to return '/tmp', but got '%s'", lib)
+	}
+
+	if lang := GetLanguage(); lang != "en_US" {
+		t.Errorf("Expected GetLanguage to return 'en_US', but got '%s'", lang)
+	}
+
+	// Registered catalogs are kept
+	SetLanguage("es")
+
+	if tr := Get("My text"); tr != "Mi texto" {
+		t.Errorf("Expected 'Mi texto' but got '%s'", tr)
+	}
+
+	// Until they're discarded
+	ResetCatalogs()
+
+	if tr := Get("My text"); tr != "My text" {
+		t.Errorf("Expected 'My text' but got '%s'", tr)
+	}
+	if lang := GetLanguage(); lang != "es" {
+		t.Errorf("Expected GetLanguage to return 'es', but got '%s'", lang)
+	}
+
+	Reset()
+}
+
 func TestPackageFunctions(t *testing.T) {
 	// Set PO content
 	str := `# Some comment
@@ -109,8 +147,8 @@ msgstr "More translation"
 
 	// Test plural
 	tr = GetN("One with var: %s", "Several with vars: %s", 2, v)
-	if tr != "And this is the second plural form: Variable" {
-		t.Errorf("Expected 'And this is the second plural form: Variable' but got '%s'", tr)
+	if tr != "This one is the plural: Variable" {
+		t.Errorf("Expected 'This one is the plural: Variable' but got '%s'", tr)
 	}
 
 	// Test context translations
@@ -125,7 +163,7 @@ msgstr "More translation"
 		t.Errorf("Expected 'This one is the singular in a Ctx context: Variable' but got '%s'", tr)
 	}
 
-	tr = GetNC("One with var: %s", "Several with vars: %s", 1, "Ctx", v)
+	tr = GetNC("One with var: %s", "Several with vars: %s", 2, "Ctx", v)
 	if tr != "This one is the plural in a Ctx context: Variable" {
 		t.Errorf("Expected 'This one is the plural in a Ctx context: Variable' but got '%s'", tr)
 	}
@@ -187,3 +225,38 @@ msgstr[2] "And this is the second plural form: %s"
 
 	Get("My text")
 }
+
+func TestPackageConfigureRace(t *testing.T) {
+	defer Reset()
+	defer ResetCatalogs()
+
+	RegisterCatalog("es", "default", "msgid \"My text\"\nmsgstr \"Mi texto\"\n")
+	RegisterCatalog("fr", "default", "msgid \"My text\"\nmsgstr \"Mon texte\"\n")
+
+	done := make(chan bool)
+	go func() {
+		defer close(done)
+
+		for i := 0; i < 100; i++ {
+			if tr := Get("My text"); tr != "Mi texto" && tr != "Mon texte" && tr != "My text" {
+				t.Errorf("Unexpected translation '%s'", tr)
+				return
+			}
+			GetN("One file", "%d files", i, i)
+			GetLanguage()
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		if i%2 == 0 {
+			SetLanguage("es")
+		} else {
+			Configure("/tmp", "fr", "default")
+		}
+	}
+	<-done
+
+	if tr := Get("My text"); tr != "Mon texte" {
+		t.Errorf("Expected 'Mon texte' but got '%s'", tr)
+	}
+}
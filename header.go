@@ -0,0 +1,271 @@
+package gotext
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// headerContent returns the msgstr content of the header entry whose msgstr starts on the
+// given lines (following its empty msgid), joining the continuation lines.
+// It stops at the end of the header entry.
+func headerContent(lines []string) string {
+	content := ""
+	started := false
+
+	for _, l := range lines {
+		l = strings.TrimSpace(l)
+
+		// Skip comments
+		if strings.HasPrefix(l, "#") {
+			continue
+		}
+
+		// End of header entry
+		if l == "" || strings.HasPrefix(l, "msgid") || strings.HasPrefix(l, "msgctxt") {
+			break
+		}
+
+		if strings.HasPrefix(l, "msgstr") {
+			l = strings.TrimSpace(strings.TrimPrefix(l, "msgstr"))
+			started = true
+		}
+
+		if !started {
+			continue
+		}
+
+		// Get quoted content from msgstr or continuation lines
+		if s, err := strconv.Unquote(l); err == nil {
+			content += s
+		}
+	}
+
+	return content
+}
+
+// parseHeader splits the content of a header entry into its "Name: Value" fields.
+// Lines without a colon are ignored.
+func parseHeader(content string) map[string]string {
+	fields := make(map[string]string)
+
+	for _, l := range strings.Split(content, "\n") {
+		i := strings.Index(l, ":")
+		if i == -1 {
+			continue
+		}
+
+		fields[strings.TrimSpace(l[:i])] = strings.TrimSpace(l[i+1:])
+	}
+
+	return fields
+}
+
+// headerCharset returns the charset declared on the Content-Type field of the given header fields,
+// or an empty string if it's not declared or it's the "CHARSET" placeholder used on templates.
+func headerCharset(fields map[string]string) string {
+	for name, value := range fields {
+		if !strings.EqualFold(name, "Content-Type") {
+			continue
+		}
+
+		for _, param := range strings.Split(value, ";") {
+			param = strings.TrimSpace(param)
+			if len(param) > 8 && strings.EqualFold(param[:8], "charset=") {
+				charset := strings.Trim(strings.TrimSpace(param[8:]), "\"")
+				if strings.EqualFold(charset, "CHARSET") {
+					return ""
+				}
+
+				return charset
+			}
+		}
+	}
+
+	return ""
+}
+
+// headerNPlurals returns the nplurals value declared on the Plural-Forms field of the given header fields,
+// or 0 if it's not declared or invalid.
+func headerNPlurals(fields map[string]string) int {
+	for _, param := range strings.Split(fields["Plural-Forms"], ";") {
+		param = strings.TrimSpace(param)
+		if !strings.HasPrefix(param, "nplurals") {
+			continue
+		}
+
+		value := strings.TrimSpace(strings.TrimPrefix(param, "nplurals"))
+		if !strings.HasPrefix(value, "=") {
+			continue
+		}
+
+		n, err := strconv.Atoi(strings.TrimSpace(value[1:]))
+		if err != nil || n < 1 {
+			return 0
+		}
+
+		return n
+	}
+
+	return 0
+}
+
+// Header holds the fields of a catalog header entry, keyed by field name.
+type Header map[string]string
+
+// Usual header fields, in the order they are written.
+var headerOrder = []string{
+	"Project-Id-Version",
+	"Report-Msgid-Bugs-To",
+	"POT-Creation-Date",
+	"PO-Revision-Date",
+	"Last-Translator",
+	"Language-Team",
+	"Language",
+	"MIME-Version",
+	"Content-Type",
+	"Content-Transfer-Encoding",
+	"Plural-Forms",
+}
+
+// String returns the header fields formatted as the msgstr of a header entry, one "Name: Value" field per line.
+// Usual gettext fields are written first, in their conventional order, followed by the other fields sorted by name.
+func (h Header) String() string {
+	names := make([]string, 0, len(h))
+	known := make(map[string]bool)
+
+	for _, name := range headerOrder {
+		known[name] = true
+		if _, ok := h[name]; ok {
+			names = append(names, name)
+		}
+	}
+
+	extra := make([]string, 0)
+	for name := range h {
+		if !known[name] {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+
+	content := ""
+	for _, name := range append(names, extra...) {
+		content += name + ": " + h[name] + "\n"
+	}
+
+	return content
+}
+
+// header returns the fields of the catalog header entry, or an empty map if the catalog doesn't have one.
+func (po *Po) header() map[string]string {
+	if t := po.find(""); t != nil {
+		return parseHeader(t.Trs[0])
+	}
+
+	return make(map[string]string)
+}
+
+// Headers returns a copy of the fields declared on the catalog header entry, keyed by field name,
+// or an empty Header if the catalog doesn't have one.
+func (po *Po) Headers() Header {
+	return po.header()
+}
+
+// Header returns the value of the given header field (name), matched ignoring case as on gettext,
+// or an empty string if it isn't declared.
+func (po *Po) Header(name string) string {
+	header := po.header()
+	if value, ok := header[name]; ok {
+		return value
+	}
+
+	for field, value := range header {
+		if strings.EqualFold(field, name) {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// Language returns the language code declared on the Language header field, like "pt_BR",
+// or an empty string if it isn't declared.
+func (po *Po) Language() string {
+	return po.Header("Language")
+}
+
+// PluralForms returns the value of the Plural-Forms header field, like "nplurals=2; plural=(n != 1);",
+// or an empty string if it isn't declared.
+func (po *Po) PluralForms() string {
+	return po.Header("Plural-Forms")
+}
+
+// Charset returns the charset declared on the Content-Type header field, like "UTF-8",
+// or an empty string if it isn't declared or it's the "CHARSET" placeholder used on templates.
+func (po *Po) Charset() string {
+	return headerCharset(po.header())
+}
+
+// Contact holds a person or team declared on a catalog header, like the Last-Translator field.
+type Contact struct {
+	// Header field the contact was read from: "Last-Translator" or "Language-Team".
+	Field string
+
+	// Name of the person or team.
+	Name string
+
+	// Address between angle brackets after the name, usually an email but it can be a URL for teams.
+	Address string
+}
+
+// String returns the contact in the header format: "Name <address>".
+func (c Contact) String() string {
+	if c.Address == "" {
+		return c.Name
+	}
+	if c.Name == "" {
+		return "<" + c.Address + ">"
+	}
+
+	return c.Name + " <" + c.Address + ">"
+}
+
+// Placeholders used by gettext templates on the contact fields.
+var contactPlaceholders = map[string]bool{
+	"FULL NAME <EMAIL@ADDRESS>": true,
+	"LANGUAGE <LL@li.org>":      true,
+}
+
+// Translators returns the people and teams declared on the Last-Translator and Language-Team header fields,
+// in that order, so they can be credited. Values are parsed with the common "Name <address>" format;
+// values without an address are returned as a name.
+// Missing and empty fields, and the placeholders left by gettext templates, are skipped.
+func (po *Po) Translators() []Contact {
+	header := po.header()
+	contacts := make([]Contact, 0, 2)
+
+	for _, field := range []string{"Last-Translator", "Language-Team"} {
+		value := header[field]
+		if value == "" || contactPlaceholders[value] {
+			continue
+		}
+
+		contacts = append(contacts, parseContact(field, value))
+	}
+
+	return contacts
+}
+
+// parseContact splits a contact header value with the "Name <address>" format.
+func parseContact(field, value string) Contact {
+	c := Contact{Field: field, Name: value}
+
+	start := strings.LastIndex(value, "<")
+	if start != -1 && strings.HasSuffix(value, ">") {
+		c.Name = strings.TrimSpace(value[:start])
+		c.Address = strings.TrimSpace(value[start+1 : len(value)-1])
+	}
+
+	return c
+}
@@ -0,0 +1,127 @@
+package gotext
+
+import (
+	"testing"
+)
+
+func TestPoTranslators(t *testing.T) {
+	// Set PO content
+	str := `
+msgid ""
+msgstr ""
+"Last-Translator: Ana Pérez <ana@example.com>\n"
+"Language-Team: Spanish <https://l10n.example.com/teams/es/>\n"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	expected := []Contact{
+		{Field: "Last-Translator", Name: "Ana Pérez", Address: "ana@example.com"},
+		{Field: "Language-Team", Name: "Spanish", Address: "https://l10n.example.com/teams/es/"},
+	}
+
+	contacts := po.Translators()
+	if len(contacts) != len(expected) {
+		t.Fatalf("Expected %v but got %v", expected, contacts)
+	}
+
+	for i, c := range contacts {
+		if c != expected[i] {
+			t.Errorf("Expected contact %v but got %v", expected[i], c)
+		}
+	}
+
+	if s := contacts[0].String(); s != "Ana Pérez <ana@example.com>" {
+		t.Errorf("Expected 'Ana Pérez <ana@example.com>' but got '%s'", s)
+	}
+
+	// Template placeholders and values without address
+	po = new(Po)
+	po.Parse(`
+msgid ""
+msgstr ""
+"Last-Translator: FULL NAME <EMAIL@ADDRESS>\n"
+"Language-Team: Spanish team\n"
+`)
+
+	contacts = po.Translators()
+	if len(contacts) != 1 || contacts[0] != (Contact{Field: "Language-Team", Name: "Spanish team"}) {
+		t.Errorf("Expected only the team name but got %v", contacts)
+	}
+
+	if s := contacts[0].String(); s != "Spanish team" {
+		t.Errorf("Expected 'Spanish team' but got '%s'", s)
+	}
+
+	// No header
+	po = new(Po)
+	po.Parse("msgid \"My text\"\nmsgstr \"Mi texto\"\n")
+
+	if contacts := po.Translators(); len(contacts) != 0 {
+		t.Errorf("Expected no contacts but got %v", contacts)
+	}
+}
+
+func TestPoHeaders(t *testing.T) {
+	// Set PO content
+	str := `
+msgid ""
+msgstr ""
+"Project-Id-Version: app 1.0\n"
+"PO-Revision-Date: 2024-01-15 10:30+0100\n"
+"Last-Translator: Ana P` + "\xe9" + `rez <ana@example.com>\n"
+"Language: pt_BR\n"
+"content-type: text/plain; charset=ISO-8859-1\n"
+"Plural-Forms: nplurals=2; plural=(n > 1);\n"
+"X-Generator: Poedit 3.4\n"
+
+msgid "My text"
+msgstr "Meu texto"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	headers := po.Headers()
+	if len(headers) != 7 || headers["X-Generator"] != "Poedit 3.4" {
+		t.Errorf("Unexpected headers: %v", headers)
+	}
+
+	// Changing the returned fields doesn't change the catalog
+	headers["Language"] = "es"
+
+	tests := map[string]string{
+		"Language":         "pt_BR",
+		"PO-Revision-Date": "2024-01-15 10:30+0100",
+		"last-translator":  "Ana Pérez <ana@example.com>",
+		"Content-Type":     "text/plain; charset=UTF-8",
+		"Missing":          "",
+	}
+	for name, expected := range tests {
+		if value := po.Header(name); value != expected {
+			t.Errorf("Expected '%s' for '%s' but got '%s'", expected, name, value)
+		}
+	}
+
+	if lang := po.Language(); lang != "pt_BR" {
+		t.Errorf("Expected 'pt_BR' but got '%s'", lang)
+	}
+
+	if pf := po.PluralForms(); pf != "nplurals=2; plural=(n > 1);" {
+		t.Errorf("Expected 'nplurals=2; plural=(n > 1);' but got '%s'", pf)
+	}
+
+	// The ISO-8859-1 content is converted to UTF-8
+	if charset := po.Charset(); charset != "UTF-8" {
+		t.Errorf("Expected 'UTF-8' but got '%s'", charset)
+	}
+
+	// Catalog without header
+	po = new(Po)
+	po.Parse("msgid \"My text\"\nmsgstr \"Meu texto\"\n")
+
+	if len(po.Headers()) != 0 || po.Language() != "" || po.PluralForms() != "" || po.Charset() != "" {
+		t.Errorf("Unexpected headers: %v", po.Headers())
+	}
+}
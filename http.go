@@ -0,0 +1,193 @@
+package gotext
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// contextKey is the type of the key used to store a Locale on a context.
+type contextKey struct{}
+
+// NewContext returns a copy of the given context (ctx) holding the given Locale (l), to be retrieved with FromContext.
+func NewContext(ctx context.Context, l *Locale) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the Locale stored on the given context (ctx) by NewContext or Middleware,
+// or nil if there isn't any.
+func FromContext(ctx context.Context) *Locale {
+	l, _ := ctx.Value(contextKey{}).(*Locale)
+	return l
+}
+
+// ParseAcceptLanguage returns the language codes of the given Accept-Language header value (header),
+// sorted by their quality values, from the most preferred one. Languages with the same quality keep their order.
+// Codes are returned using the underscore separator of the locale directories, like "en_US" for "en-US".
+// The "*" wildcard and languages with a zero or invalid quality are skipped.
+func ParseAcceptLanguage(header string) []string {
+	type language struct {
+		code string
+		q    float64
+	}
+
+	var langs []language
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+
+		code := strings.TrimSpace(params[0])
+		if code == "" || code == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+
+			v, err := strconv.ParseFloat(param[2:], 64)
+			if err != nil || v < 0 || v > 1 {
+				v = 0
+			}
+			q = v
+		}
+
+		if q > 0 {
+			langs = append(langs, language{code: strings.Replace(code, "-", "_", -1), q: q})
+		}
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].q > langs[j].q
+	})
+
+	codes := make([]string, len(langs))
+	for i, lang := range langs {
+		codes[i] = lang.code
+	}
+
+	return codes
+}
+
+// MiddlewareOptions configures the Locale chosen by Middleware for each request.
+type MiddlewareOptions struct {
+	// Domains loaded on each Locale, the first one being used by the methods without a domain parameter, like Get.
+	// Only the "default" domain is loaded if empty.
+	Domains []string
+
+	// Language used when none of the requested languages is available. "en_US" is used if empty,
+	// like on the package level functions.
+	Default string
+
+	// Name of the query parameter and cookie whose value selects the language over the Accept-Language header,
+	// in that order, so users can override their browser settings. They aren't used if empty.
+	Query  string
+	Cookie string
+}
+
+/*
+Middleware returns an HTTP middleware that chooses the Locale of each request from the languages available
+on the given library directory (lib) and stores it on the request context, to be retrieved with FromContext:
+
+    mw := gotext.Middleware("/path/to/locales", gotext.MiddlewareOptions{Query: "lang"})
+
+    http.Handle("/", mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        l := gotext.FromContext(r.Context())
+        fmt.Fprintln(w, l.Get("Welcome"))
+    })))
+
+The available languages are the directories found on the library directory when the middleware is created.
+The requested languages are read from the Accept-Language header, unless the query parameter or cookie set on the options
+are present, and matched with MatchLanguage. Each Locale is loaded on its first use and shared by the following requests.
+*/
+func Middleware(lib string, opts MiddlewareOptions) func(http.Handler) http.Handler {
+	n := &negotiator{
+		lib:     lib,
+		opts:    opts,
+		locales: make(map[string]*Locale),
+	}
+
+	if n.opts.Default == "" {
+		n.opts.Default = "en_US"
+	}
+	if len(n.opts.Domains) == 0 {
+		n.opts.Domains = []string{"default"}
+	}
+
+	// Get available languages
+	if dirs, err := ioutil.ReadDir(lib); err == nil {
+		for _, dir := range dirs {
+			if dir.IsDir() {
+				n.available = append(n.available, dir.Name())
+			}
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			l := n.locale(n.requested(r))
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), l)))
+		})
+	}
+}
+
+// negotiator chooses and caches the Locale objects used by Middleware.
+type negotiator struct {
+	lib       string
+	opts      MiddlewareOptions
+	available []string
+
+	// Loaded Locale objects by language.
+	locales map[string]*Locale
+
+	// Sync Mutex
+	sync.Mutex
+}
+
+// requested returns the languages requested by the given request (r), from the most preferred one.
+func (n *negotiator) requested(r *http.Request) []string {
+	if n.opts.Query != "" {
+		if lang := r.URL.Query().Get(n.opts.Query); lang != "" {
+			return []string{lang}
+		}
+	}
+
+	if n.opts.Cookie != "" {
+		if c, err := r.Cookie(n.opts.Cookie); err == nil && c.Value != "" {
+			return []string{c.Value}
+		}
+	}
+
+	return ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+}
+
+// locale returns the Locale for the best match of the requested languages, or the default language,
+// loading it on its first use.
+func (n *negotiator) locale(requested []string) *Locale {
+	lang := MatchLanguage(requested, n.available)
+	if lang == "" {
+		lang = n.opts.Default
+	}
+
+	n.Lock()
+	defer n.Unlock()
+
+	l, ok := n.locales[lang]
+	if !ok {
+		l = NewLocale(n.lib, lang)
+		for _, dom := range n.opts.Domains {
+			l.AddDomain(dom)
+		}
+		l.SetDomain(n.opts.Domains[0])
+
+		n.locales[lang] = l
+	}
+
+	return l
+}
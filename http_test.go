@@ -0,0 +1,94 @@
+package gotext
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	for header, expected := range map[string]string{
+		"":                                  "",
+		"es":                                "es",
+		"en-US,en;q=0.8,es;q=0.9":           "en_US,es,en",
+		"fr;q=0.5, de, *;q=0.1, it;q=0.5":   "de,fr,it",
+		"pt-BR;q=0, es;q=abc, en ; q=0.3 ":  "en",
+		"zh-Hant-TW;q=1.0,ja;q=2,ko;q=0.01": "zh_Hant_TW,ko",
+	} {
+		if langs := strings.Join(ParseAcceptLanguage(header), ","); langs != expected {
+			t.Errorf("Expected '%s' for '%s' but got '%s'", expected, header, langs)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	// Create library directory
+	lib := path.Clean("/tmp" + string(os.PathSeparator) + "http")
+	for lang, str := range map[string]string{
+		"en_US": "msgid \"Welcome\"\nmsgstr \"Welcome!\"\n",
+		"es":    "msgid \"Welcome\"\nmsgstr \"¡Bienvenido!\"\n",
+		"fr":    "msgid \"Welcome\"\nmsgstr \"Bienvenue !\"\n",
+	} {
+		dirname := path.Join(lib, lang)
+		err := os.MkdirAll(dirname, os.ModePerm)
+		if err != nil {
+			t.Fatalf("Can't create test directory: %s", err.Error())
+		}
+
+		err = ioutil.WriteFile(path.Join(dirname, "web.po"), []byte(str), 0644)
+		if err != nil {
+			t.Fatalf("Can't write to test file: %s", err.Error())
+		}
+	}
+
+	mw := Middleware(lib, MiddlewareOptions{Domains: []string{"web"}, Query: "lang", Cookie: "lang"})
+
+	var locales []*Locale
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l := FromContext(r.Context())
+		locales = append(locales, l)
+		w.Write([]byte(l.Get("Welcome")))
+	}))
+
+	for _, tc := range []struct {
+		url, header, cookie, expected string
+	}{
+		{"/", "es-AR,en;q=0.5", "", "¡Bienvenido!"},
+		{"/", "de, fr;q=0.8", "", "Bienvenue !"},
+		{"/", "de", "", "Welcome!"},
+		{"/", "", "", "Welcome!"},
+		{"/?lang=fr", "es", "es", "Bienvenue !"},
+		{"/", "fr", "es", "¡Bienvenido!"},
+		{"/", "es", "", "¡Bienvenido!"},
+	} {
+		r := httptest.NewRequest("GET", tc.url, nil)
+		if tc.header != "" {
+			r.Header.Set("Accept-Language", tc.header)
+		}
+		if tc.cookie != "" {
+			r.AddCookie(&http.Cookie{Name: "lang", Value: tc.cookie})
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if body := w.Body.String(); body != tc.expected {
+			t.Errorf("Expected '%s' for %s '%s' but got '%s'", tc.expected, tc.url, tc.header, body)
+		}
+	}
+
+	// Locales are shared between requests
+	if locales[0] != locales[len(locales)-1] {
+		t.Error("Expected the same Locale for the same language")
+	}
+
+	// Empty context
+	if FromContext(context.Background()) != nil {
+		t.Error("Expected no Locale on an empty context")
+	}
+}
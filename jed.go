@@ -0,0 +1,144 @@
+package gotext
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Plural-Forms header used when the catalog doesn't declare one.
+const defaultPluralForms = "nplurals=2; plural=(n != 1);"
+
+/*
+ExportJed returns the catalog encoded as a JSON document in the format consumed by the Jed (1.x) JavaScript library,
+and compatible libraries like gettext.js, using the given name (domain) for the domain.
+
+The resulting document has the following schema:
+
+    {
+        "domain": "<domain>",
+        "locale_data": {
+            "<domain>": {
+                "": {
+                    "domain": "<domain>",
+                    "lang": "<Language header>",
+                    "plural_forms": "<Plural-Forms header>"
+                },
+                "<msgid>": ["<msgstr>"],
+                "<msgid of a plural entry>": ["<msgstr[0]>", "<msgstr[1]>", ...],
+                "<msgctxt>\u0004<msgid>": ["<msgstr>"]
+            }
+        }
+    }
+
+Entries within a context are keyed by the context and the msgid separated by the EOT character (\u0004),
+following the gettext convention.
+Plural entries list every translated form in order, using empty strings for missing forms.
+The "lang" and "plural_forms" values are taken from the catalog header;
+when the Plural-Forms header is missing, "nplurals=2; plural=(n != 1);" is used.
+*/
+func (po *Po) ExportJed(domain string) ([]byte, error) {
+	return po.ExportJedOptions(domain, JSONOptions{})
+}
+
+// JSONOptions holds the settings used to encode JSON exports.
+type JSONOptions struct {
+	// Escape every non-ASCII character as \uXXXX, using surrogate pairs beyond the Basic Multilingual Plane,
+	// for consumers that can't handle raw UTF-8. By default, non-ASCII characters are written as UTF-8.
+	ASCIIOnly bool
+}
+
+// ExportJedOptions works like ExportJed using the given encoding options (opts).
+// Strings are always emitted as valid UTF-8 JSON: invalid byte sequences are replaced by U+FFFD.
+func (po *Po) ExportJedOptions(domain string, opts JSONOptions) ([]byte, error) {
+	entries := po.snapshot()
+
+	// Read header
+	headers := make(map[string]string)
+	if h, ok := entries[entryKey{}]; ok {
+		headers = parseHeader(h.Trs[0])
+	}
+
+	pluralForms := headers["Plural-Forms"]
+	if pluralForms == "" {
+		pluralForms = defaultPluralForms
+	}
+
+	messages := map[string]interface{}{
+		"": map[string]string{
+			"domain":       domain,
+			"lang":         headers["Language"],
+			"plural_forms": pluralForms,
+		},
+	}
+
+	for k, t := range entries {
+		// Skip header
+		if k.id == "" && k.ctx == "" {
+			continue
+		}
+
+		key := k.id
+		if k.ctx != "" {
+			key = k.ctx + "\x04" + k.id
+		}
+
+		messages[key] = t.forms()
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"domain": domain,
+		"locale_data": map[string]interface{}{
+			domain: messages,
+		},
+	})
+	if err != nil || !opts.ASCIIOnly {
+		return data, err
+	}
+
+	return asciiJSON(data), nil
+}
+
+// asciiJSON escapes the non-ASCII characters of the given JSON document (data) as \uXXXX sequences.
+// Non-ASCII characters can only be found inside JSON strings, so they can be escaped without parsing the document.
+func asciiJSON(data []byte) []byte {
+	var buf bytes.Buffer
+
+	for _, r := range string(data) {
+		switch {
+		case r < utf8.RuneSelf:
+			buf.WriteRune(r)
+
+		case r > 0xFFFF:
+			r1, r2 := utf16.EncodeRune(r)
+			fmt.Fprintf(&buf, "\\u%04x\\u%04x", r1, r2)
+
+		default:
+			fmt.Fprintf(&buf, "\\u%04x", r)
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// forms returns the translated forms in plural index order, using empty strings for missing indexes.
+// Singular entries return a single form.
+func (t *Translation) forms() []string {
+	size := 1
+	for i := range t.Trs {
+		if i+1 > size {
+			size = i + 1
+		}
+	}
+
+	forms := make([]string, size)
+	for i, str := range t.Trs {
+		if i >= 0 {
+			forms[i] = str
+		}
+	}
+
+	return forms
+}
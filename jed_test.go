@@ -0,0 +1,150 @@
+package gotext
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPoExportJed(t *testing.T) {
+	// Set PO content
+	str := `msgid ""
+msgstr ""
+"Language: ru\n"
+"Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+msgid "My text"
+msgstr "Мой текст"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d файл"
+msgstr[2] "%d файлов"
+
+msgctxt "Ctx"
+msgid "My text"
+msgstr "Мой текст в контексте"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	data, err := po.ExportJed("messages")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	var doc struct {
+		Domain     string                                `json:"domain"`
+		LocaleData map[string]map[string]json.RawMessage `json:"locale_data"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Can't decode exported JSON: %s", err.Error())
+	}
+
+	if doc.Domain != "messages" {
+		t.Errorf("Expected domain 'messages' but got '%s'", doc.Domain)
+	}
+
+	messages, ok := doc.LocaleData["messages"]
+	if !ok {
+		t.Fatal("Expected 'messages' locale data")
+	}
+
+	// Test header
+	var header map[string]string
+	if err := json.Unmarshal(messages[""], &header); err != nil {
+		t.Fatalf("Can't decode header: %s", err.Error())
+	}
+
+	expectedHeader := map[string]string{
+		"domain":       "messages",
+		"lang":         "ru",
+		"plural_forms": "nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);",
+	}
+	if !reflect.DeepEqual(header, expectedHeader) {
+		t.Errorf("Expected header %v but got %v", expectedHeader, header)
+	}
+
+	// Test messages
+	expected := map[string][]string{
+		"My text":        {"Мой текст"},
+		"%d file":        {"%d файл", "", "%d файлов"},
+		"Ctx\x04My text": {"Мой текст в контексте"},
+	}
+
+	if len(messages) != len(expected)+1 {
+		t.Errorf("Expected %d messages but got %d", len(expected)+1, len(messages))
+	}
+
+	for key, forms := range expected {
+		var got []string
+		if err := json.Unmarshal(messages[key], &got); err != nil {
+			t.Errorf("Can't decode message '%s': %s", key, err.Error())
+			continue
+		}
+		if !reflect.DeepEqual(got, forms) {
+			t.Errorf("Expected %v for '%s' but got %v", forms, key, got)
+		}
+	}
+
+	// Test default plural forms
+	po = new(Po)
+	po.Parse(`msgid "My text"
+msgstr "Translated text"`)
+
+	data, err = po.ExportJed("default")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	expectedJSON := `{"domain":"default","locale_data":{"default":{"":{"domain":"default","lang":"","plural_forms":"nplurals=2; plural=(n != 1);"},"My text":["Translated text"]}}}`
+	if string(data) != expectedJSON {
+		t.Errorf("Expected %s but got %s", expectedJSON, string(data))
+	}
+}
+
+func TestPoExportJedASCIIOnly(t *testing.T) {
+	// Set PO content
+	str := `msgid "My text"
+msgstr "Texto ñ 😀"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	data, err := po.ExportJedOptions("messages", JSONOptions{ASCIIOnly: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	for _, b := range data {
+		if b >= 0x80 {
+			t.Fatalf("Expected ASCII only output but got: %s", data)
+		}
+	}
+
+	if !bytes.Contains(data, []byte(`"Texto \u00f1 \ud83d\ude00"`)) {
+		t.Errorf("Expected escaped string but got: %s", data)
+	}
+
+	// Escaped output decodes to the same strings
+	var doc struct {
+		LocaleData map[string]map[string]interface{} `json:"locale_data"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Can't decode exported JSON: %s", err.Error())
+	}
+
+	forms := doc.LocaleData["messages"]["My text"].([]interface{})
+	if forms[0] != "Texto ñ 😀" {
+		t.Errorf("Expected 'Texto ñ 😀' but got '%v'", forms[0])
+	}
+
+	// Default output keeps UTF-8
+	data, _ = po.ExportJed("messages")
+	if !bytes.Contains(data, []byte("Texto ñ 😀")) {
+		t.Errorf("Expected raw UTF-8 string but got: %s", data)
+	}
+}
@@ -0,0 +1,237 @@
+package gotext
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"strings"
+)
+
+/*
+JSON parses the content of JSON catalogs, like the flat or nested key-value files used by i18next,
+and provides the same translation methods as Po, so Go backends can share their catalogs with JavaScript frontends.
+
+Example:
+
+    import "github.com/leonelquinteros/gotext"
+
+    func main() {
+        // Create json object
+        j := new(gotext.JSON)
+
+        // Parse .json file
+        j.ParseFile("/path/to/json/file/translations.json")
+
+        // Get translation
+        println(j.Get("Translate this"))
+    }
+
+The catalog is a JSON object whose keys are the msgids and whose values are their translations:
+
+    {
+        "": "Plural-Forms: nplurals=2; plural=(n != 1);\n",
+        "My text": "Mi texto",
+        "menu": {
+            "open": "Abrir"
+        },
+        "One file": ["Un archivo", "%d archivos"],
+        "item": "Un elemento",
+        "item_plural": "%d elementos"
+    }
+
+Keys of nested objects are joined with a dot, so "open" above is looked up as "menu.open".
+Plural forms are given as an array of strings, by plural index, or with the i18next "_plural" suffix,
+which sets the form 1 of the key without the suffix when it exists.
+As JSON catalogs don't have plural msgids, the msgid is used as the plural one.
+Contexts use the separator of the MO files, like "Menu\u0004Open" for the msgid "Open" in the "Menu" context.
+The optional "" key holds the header, in the same format as the PO header entry, to set the Plural-Forms rule.
+
+Like Po, badly formatted content is ignored: content that isn't a valid JSON catalog loads no translations at all.
+*/
+type JSON struct {
+	Po
+}
+
+// ParseFile tries to read the file by its provided path (f) and parse its content as a .json file.
+func (j *JSON) ParseFile(f string) {
+	data, err := ioutil.ReadFile(f)
+	if err != nil {
+		return
+	}
+
+	j.Parse(data)
+}
+
+// Parse loads the translations specified in the provided JSON content (buf).
+func (j *JSON) Parse(buf []byte) {
+	j.Po.parseJSON(buf)
+}
+
+// ParseReader reads the JSON content from the provided reader (r) and parses it, like ParseFile.
+// The content is parsed only if it's read completely, otherwise the read error is returned.
+func (j *JSON) ParseReader(r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	j.Parse(data)
+
+	return nil
+}
+
+// ParseFS reads the file at the given path (name) of the given file system (fsys) and parses it as a .json file,
+// like Po.ParseFS. It returns the error if the file can't be read or isn't a valid JSON catalog.
+func (j *JSON) ParseFS(fsys fs.FS, name string) error {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+
+	return j.Po.parseJSON(data)
+}
+
+// jsonEntry is a key of a JSON catalog, with its translated forms.
+type jsonEntry struct {
+	key   string
+	forms []string
+}
+
+// parseJSON loads the translations from the given JSON catalog content (buf).
+// The whole content is checked before storing any translation, so it returns an error without changes
+// if it isn't a valid JSON catalog.
+func (po *Po) parseJSON(buf []byte) error {
+	var entries []jsonEntry
+
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	err := jsonEntries(dec, "", func(key string, forms []string) {
+		entries = append(entries, jsonEntry{key: key, forms: forms})
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("json: unexpected content after the catalog")
+	}
+
+	// Build translations
+	keys := make(map[string]*Translation, len(entries))
+	for _, e := range entries {
+		keys[e.key] = jsonTranslation(e.key, e.forms)
+	}
+
+	// Set "_plural" forms on their keys, keeping the catalog order for the rest
+	var trs []*Translation
+	for _, e := range entries {
+		if base := strings.TrimSuffix(e.key, "_plural"); base != e.key && keys[base] != nil {
+			if len(e.forms) != 1 {
+				return fmt.Errorf("json: plural form %q must be a string", e.key)
+			}
+
+			t := keys[base]
+			t.PluralID = t.ID
+			t.Trs[1] = e.forms[0]
+			continue
+		}
+
+		trs = append(trs, keys[e.key])
+	}
+
+	// Init storage
+	po.initParse()
+
+	// Keep track of the parsed content
+	po.Lock()
+	po.fingerprint = SourceFingerprint(po.fingerprint + string(buf))
+	po.Unlock()
+
+	for _, tr := range trs {
+		po.save(tr.Context, tr)
+	}
+
+	return nil
+}
+
+// jsonEntries reads the JSON object at the decoder (dec) position and calls the given function (add)
+// for each string or array of strings found on it or its nested objects,
+// with its key prefixed by the keys of the enclosing objects (prefix), separated by dots.
+func jsonEntries(dec *json.Decoder, prefix string, add func(key string, forms []string)) error {
+	if tok, err := dec.Token(); err != nil {
+		return fmt.Errorf("json: %s", err.Error())
+	} else if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return errors.New("json: catalog must be an object")
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("json: %s", err.Error())
+		}
+
+		key := tok.(string)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return fmt.Errorf("json: %s", err.Error())
+		}
+
+		switch raw[0] {
+		case '{':
+			if err := jsonEntries(json.NewDecoder(bytes.NewReader(raw)), key, add); err != nil {
+				return err
+			}
+
+		case '"':
+			var str string
+			json.Unmarshal(raw, &str)
+			add(key, []string{str})
+
+		case '[':
+			var forms []string
+			if err := json.Unmarshal(raw, &forms); err != nil || len(forms) == 0 {
+				return fmt.Errorf("json: plural forms of %q must be a non-empty array of strings", key)
+			}
+			add(key, forms)
+
+		default:
+			return fmt.Errorf("json: unsupported value for %q", key)
+		}
+	}
+
+	// Read closing delimiter
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("json: %s", err.Error())
+	}
+
+	return nil
+}
+
+// jsonTranslation returns the translation for the given JSON catalog key and translated forms.
+func jsonTranslation(key string, forms []string) *Translation {
+	tr := NewTranslation()
+
+	// Get context
+	if i := strings.Index(key, "\x04"); i >= 0 {
+		tr.Context = key[:i]
+		key = key[i+1:]
+	}
+
+	tr.ID = key
+	for n, form := range forms {
+		tr.Trs[n] = form
+	}
+
+	if len(forms) > 1 {
+		tr.PluralID = key
+	}
+
+	return tr
+}
@@ -0,0 +1,162 @@
+package gotext
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"testing/fstest"
+)
+
+func TestJSON(t *testing.T) {
+	// Set JSON content
+	str := `{
+    "": "Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n",
+    "My text": "Mi texto",
+    "Hello, %s": "Hola, %s",
+    "menu": {
+        "open": "Abrir",
+        "file": {
+            "save": "Guardar"
+        }
+    },
+    "One file": ["%d archivo", "%d archivos", "%d de archivos"],
+    "item": "Un elemento",
+    "item_plural": "%d elementos",
+    "orphan_plural": "Sin clave",
+    "Menu\u0004Close": "Cerrar"
+}`
+
+	j := new(JSON)
+	j.Parse([]byte(str))
+
+	tests := map[string]string{
+		"My text":        "Mi texto",
+		"menu.open":      "Abrir",
+		"menu.file.save": "Guardar",
+		"item":           "Un elemento",
+		"orphan_plural":  "Sin clave",
+	}
+	for id, expected := range tests {
+		if tr := j.GetEntry(id); tr == nil || tr.Trs[0] != expected {
+			t.Errorf("Expected '%s' for '%s' but got %v", expected, id, tr)
+		}
+	}
+
+	for _, id := range []string{"menu", "Close"} {
+		if j.GetEntry(id) != nil {
+			t.Errorf("Unexpected entry '%s'", id)
+		}
+	}
+
+	if tr := j.Get("Hello, %s", "Ana"); tr != "Hola, Ana" {
+		t.Errorf("Expected 'Hola, Ana' but got '%s'", tr)
+	}
+
+	if tr := j.GetC("Close", "Menu"); tr != "Cerrar" {
+		t.Errorf("Expected 'Cerrar' but got '%s'", tr)
+	}
+
+	// Plural forms use the header rule
+	plurals := map[int]string{1: "1 archivo", 3: "3 archivos", 5: "5 de archivos"}
+	for n, expected := range plurals {
+		if tr := j.GetN("One file", "%d files", n, n); tr != expected {
+			t.Errorf("Expected '%s' but got '%s'", expected, tr)
+		}
+	}
+
+	if tr := j.GetN("item", "items", 2, 2); tr != "2 elementos" {
+		t.Errorf("Expected '2 elementos' but got '%s'", tr)
+	}
+	if j.GetEntry("item_plural") != nil {
+		t.Error("Expected 'item_plural' to be a plural form of 'item'")
+	}
+	if tr := j.GetEntry("item"); tr == nil || tr.PluralID != "item" {
+		t.Errorf("Expected 'item' plural msgid but got %v", tr)
+	}
+
+	// Invalid content is ignored
+	invalid := []string{
+		`["My text"]`,
+		`{"My text": 1}`,
+		`{"My text": []}`,
+		`{"My text": [1, 2]}`,
+		`{"item": "Un elemento", "item_plural": ["%d elementos", "%d de elementos"]}`,
+		`{"My text": "Mi texto"`,
+		`{"My text": "Mi texto"} {}`,
+	}
+	for _, str := range invalid {
+		j := new(JSON)
+		j.Parse([]byte(str))
+
+		if tr := j.Get("My text"); tr != "My text" {
+			t.Errorf("Expected no translations for %s but got '%s'", str, tr)
+		}
+
+		if err := j.ParseFS(fstest.MapFS{"es.json": {Data: []byte(str)}}, "es.json"); err == nil {
+			t.Errorf("Expected error parsing %s", str)
+		}
+	}
+
+	// Parse from file system
+	j = new(JSON)
+	if err := j.ParseFS(fstest.MapFS{"es.json": {Data: []byte(str)}}, "es.json"); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if tr := j.Get("My text"); tr != "Mi texto" {
+		t.Errorf("Expected 'Mi texto' but got '%s'", tr)
+	}
+}
+
+func TestLocaleAddDomainJSON(t *testing.T) {
+	// Create Locale directory
+	dirname := path.Clean("/tmp" + string(os.PathSeparator) + "pt_BR")
+	err := os.MkdirAll(dirname, os.ModePerm)
+	if err != nil {
+		t.Fatalf("Can't create test directory: %s", err.Error())
+	}
+
+	write := func(name, str string) {
+		if err := ioutil.WriteFile(path.Join(dirname, name), []byte(str), 0644); err != nil {
+			t.Fatalf("Can't write to test file: %s", err.Error())
+		}
+	}
+
+	write("frontend.json", `{"My text": "Meu texto"}`)
+	write("both.json", `{"My text": "JSON text"}`)
+	write("both.po", "msgid \"My text\"\nmsgstr \"PO text\"\n")
+	write("broken.json", `{"My text": "Meu texto"`)
+
+	l := NewLocale("/tmp", "pt_BR")
+	l.AddDomain("frontend")
+	l.AddDomain("both")
+	l.AddDomain("broken")
+
+	if tr := l.GetD("frontend", "My text"); tr != "Meu texto" {
+		t.Errorf("Expected 'Meu texto' but got '%s'", tr)
+	}
+
+	// PO files are preferred
+	if tr := l.GetD("both", "My text"); tr != "PO text" {
+		t.Errorf("Expected 'PO text' but got '%s'", tr)
+	}
+
+	if tr := l.GetD("broken", "My text"); tr != "My text" {
+		t.Errorf("Expected 'My text' but got '%s'", tr)
+	}
+
+	// Reload JSON files
+	write("frontend.json", `{"My text": "Novo texto"}`)
+	write("broken.json", `{"My text": "Texto"}`)
+
+	if errs := l.Reload(); len(errs) != 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+
+	if tr := l.GetD("frontend", "My text"); tr != "Novo texto" {
+		t.Errorf("Expected 'Novo texto' but got '%s'", tr)
+	}
+	if tr := l.GetD("broken", "My text"); tr != "Texto" {
+		t.Errorf("Expected 'Texto' but got '%s'", tr)
+	}
+}
@@ -0,0 +1,189 @@
+package gotext
+
+import (
+	"strings"
+)
+
+// Gettext modifiers used for the BCP 47 script subtags, like "sr@latin" for "sr-Latn".
+// Other scripts use their lowercase code as modifier.
+var scriptModifiers = map[string]string{
+	"Arab": "arabic",
+	"Cyrl": "cyrillic",
+	"Deva": "devanagari",
+	"Latn": "latin",
+}
+
+// Regions implied by the Chinese scripts, as gettext names Chinese catalogs by region ("zh_TW") instead of script.
+var chineseScripts = map[string]string{
+	"Hans": "CN",
+	"Hant": "TW",
+}
+
+/*
+NormalizeLanguage returns the given language tag (tag) using the naming convention of the gettext locale directories,
+so BCP 47 tags, like the ones sent by browsers, match the directories on disk:
+
+    gotext.NormalizeLanguage("en-us")      // "en_US"
+    gotext.NormalizeLanguage("sr_Latn_RS") // "sr_RS@latin"
+    gotext.NormalizeLanguage("zh-Hant-TW") // "zh_TW"
+    gotext.NormalizeLanguage("es-419")     // "es_419"
+
+The language is lowercased and the region uppercased. Script subtags become gettext modifiers,
+except for Chinese, whose catalogs are named by region: "zh-Hant" and "zh-Hans" become "zh_TW" and "zh_CN"
+when no region is given. Variants, extensions and private use subtags are dropped,
+and so is the charset of POSIX locale names, like "de_DE.UTF-8@euro", while their modifier is kept.
+It returns an empty string for an empty tag.
+*/
+func NormalizeLanguage(tag string) string {
+	tag = strings.TrimSpace(tag)
+
+	// POSIX modifier and charset
+	mod := ""
+	if i := strings.Index(tag, "@"); i >= 0 {
+		tag, mod = tag[:i], strings.ToLower(tag[i+1:])
+	}
+	if i := strings.Index(tag, "."); i >= 0 {
+		tag = tag[:i]
+	}
+
+	parts := strings.FieldsFunc(tag, func(r rune) bool {
+		return r == '-' || r == '_'
+	})
+	if len(parts) == 0 {
+		return ""
+	}
+
+	lang, script, region := strings.ToLower(parts[0]), "", ""
+	for _, part := range parts[1:] {
+		// Extensions and private use subtags start with a single character
+		if len(part) == 1 {
+			break
+		}
+
+		switch {
+		case len(part) == 4 && script == "" && region == "" && isLetters(part):
+			script = strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
+		case region == "" && (len(part) == 2 && isLetters(part) || len(part) == 3 && isDigits(part)):
+			region = strings.ToUpper(part)
+		}
+	}
+
+	// Scripts
+	if script != "" && mod == "" {
+		if r, ok := chineseScripts[script]; ok && lang == "zh" {
+			if region == "" {
+				region = r
+			}
+		} else if m, ok := scriptModifiers[script]; ok {
+			mod = m
+		} else {
+			mod = strings.ToLower(script)
+		}
+	}
+
+	res := lang
+	if region != "" {
+		res += "_" + region
+	}
+	if mod != "" {
+		res += "@" + mod
+	}
+
+	return res
+}
+
+// splitLanguage returns the language, region and modifier of the given normalized language code (code).
+func splitLanguage(code string) (lang, region, mod string) {
+	if i := strings.Index(code, "@"); i >= 0 {
+		code, mod = code[:i], code[i+1:]
+	}
+	if i := strings.Index(code, "_"); i >= 0 {
+		code, region = code[:i], code[i+1:]
+	}
+
+	return code, region, mod
+}
+
+// languageCandidates returns the language codes to try for the given normalized language code (code), in order:
+// the code itself, the code without region and the code without modifier, if any, and the generic language.
+func languageCandidates(code string) []string {
+	lang, region, mod := splitLanguage(code)
+
+	candidates := []string{code}
+	if mod != "" && region != "" {
+		candidates = append(candidates, lang+"@"+mod, lang+"_"+region)
+	}
+	if code != lang {
+		candidates = append(candidates, lang)
+	}
+
+	return candidates
+}
+
+/*
+MatchLanguage returns the first of the available languages (available) matching the requested ones (requested),
+in order of preference, or an empty string if none matches.
+Both requested and available languages are compared once normalized with NormalizeLanguage,
+so BCP 47 tags like "en-US" or "zh-Hant-TW" match the "en_US" and "zh_TW" directories.
+
+Each requested language matches the same available language, then its language without region
+and without modifier, if any ("sr@latin" and "sr_RS" for "sr_RS@latin"), then its generic language ("en" for "en_US"),
+and then any regional variant of its generic language ("en_GB" for "en"), preferring the ones with the same modifier,
+before trying the next requested language.
+*/
+func MatchLanguage(requested, available []string) string {
+	normalized := make([]string, len(available))
+	for i, lang := range available {
+		normalized[i] = NormalizeLanguage(lang)
+	}
+
+	for _, req := range requested {
+		req = NormalizeLanguage(req)
+		if req == "" {
+			continue
+		}
+
+		for _, candidate := range languageCandidates(req) {
+			for i, lang := range normalized {
+				if lang == candidate {
+					return available[i]
+				}
+			}
+		}
+
+		// Regional variants
+		generic, _, mod := splitLanguage(req)
+		for _, sameMod := range []bool{true, false} {
+			for i, lang := range normalized {
+				l, _, m := splitLanguage(lang)
+				if l == generic && (!sameMod || m == mod) {
+					return available[i]
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// isLetters reports whether the given string (str) is made of ASCII letters only.
+func isLetters(str string) bool {
+	for _, r := range str {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isDigits reports whether the given string (str) is made of ASCII digits only.
+func isDigits(str string) bool {
+	for _, r := range str {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
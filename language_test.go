@@ -0,0 +1,106 @@
+package gotext
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeLanguage(t *testing.T) {
+	for tag, expected := range map[string]string{
+		"":                 "",
+		"es":               "es",
+		"en-us":            "en_US",
+		"EN_US":            "en_US",
+		"sr_Latn_RS":       "sr_RS@latin",
+		"sr-Cyrl":          "sr@cyrillic",
+		"zh-Hant-TW":       "zh_TW",
+		"zh-Hant":          "zh_TW",
+		"zh-Hans":          "zh_CN",
+		"zh-Hant-HK":       "zh_HK",
+		"es-419":           "es_419",
+		"de-DE-1996":       "de_DE",
+		"en-US-u-ca-greg":  "en_US",
+		"ja-x-private":     "ja",
+		"de_DE.UTF-8@euro": "de_DE@euro",
+		"sr_RS@latin":      "sr_RS@latin",
+		"az-Arab-IR":       "az_IR@arabic",
+		"pa-Guru":          "pa@guru",
+		" fil-PH ":         "fil_PH",
+	} {
+		if norm := NormalizeLanguage(tag); norm != expected {
+			t.Errorf("Expected '%s' for '%s' but got '%s'", expected, tag, norm)
+		}
+	}
+}
+
+func TestMatchLanguage(t *testing.T) {
+	available := []string{"en_US", "es", "pt_BR", "pt_PT", "sr", "sr@latin", "zh_TW", "zh_CN", "uz_UZ", "uz_AF@arabic"}
+
+	for requested, expected := range map[string]string{
+		"es_AR":       "es",
+		"EN-us":       "en_US",
+		"en_GB":       "en_US",
+		"pt":          "pt_BR",
+		"pt_PT":       "pt_PT",
+		"fr,es":       "es",
+		"fr,de":       "",
+		"fr_CA,pt_PT": "pt_PT",
+		"sr-Latn-RS":  "sr@latin",
+		"sr-RS":       "sr",
+		"zh-Hant-TW":  "zh_TW",
+		"zh-Hans":     "zh_CN",
+		"zh-HK":       "zh_TW",
+		"uz-Arab":     "uz_AF@arabic",
+		"uz":          "uz_UZ",
+		"en-GB-oed":   "en_US",
+		"es,":         "es",
+		",es":         "es",
+	} {
+		if lang := MatchLanguage(strings.Split(requested, ","), available); lang != expected {
+			t.Errorf("Expected '%s' for '%s' but got '%s'", expected, requested, lang)
+		}
+	}
+}
+
+func TestLocaleNormalizedLanguage(t *testing.T) {
+	// Create Locale directories
+	for lang, str := range map[string]string{
+		"en_US":      "msgid \"Color\"\nmsgstr \"Color (US)\"\n",
+		"sr@latin":   "msgid \"Color\"\nmsgstr \"Boja\"\n",
+		"zh_Hant_TW": "msgid \"Color\"\nmsgstr \"顏色\"\n",
+	} {
+		dirname := path.Join("/tmp", "normalized", lang)
+		if err := os.MkdirAll(dirname, os.ModePerm); err != nil {
+			t.Fatalf("Can't create test directory: %s", err.Error())
+		}
+		if err := ioutil.WriteFile(path.Join(dirname, "default.po"), []byte(str), 0644); err != nil {
+			t.Fatalf("Can't write to test file: %s", err.Error())
+		}
+	}
+
+	for lang, expected := range map[string]string{
+		"en-US":      "Color (US)",
+		"en-us":      "Color (US)",
+		"sr-Latn-RS": "Boja",
+		"zh-Hant-TW": "顏色",
+	} {
+		l := NewLocale("/tmp/normalized", lang)
+		l.AddDomain("default")
+
+		if tr := l.Get("Color"); tr != expected {
+			t.Errorf("Expected '%s' for '%s' but got '%s'", expected, lang, tr)
+		}
+	}
+
+	// Library matching
+	lib, _ := LoadLibrary("/tmp/normalized")
+	if l := lib.Locale("sr-Latn"); l == nil || l.Get("Color") != "Boja" {
+		t.Errorf("Expected 'sr@latin' Locale for 'sr-Latn' but got %v", l)
+	}
+	if langs := strings.Join(lib.Languages(), ","); langs != "en_US,sr@latin,zh_Hant_TW" {
+		t.Errorf("Unexpected languages: %s", langs)
+	}
+}
@@ -0,0 +1,140 @@
+package gotext
+
+import (
+	"sort"
+	"sync"
+)
+
+// lazyDomain is a domain registered by AddDomain on lazy mode, whose file is parsed once, on its first lookup.
+type lazyDomain struct {
+	filename string
+	once     sync.Once
+	err      error
+}
+
+/*
+SetLazy enables or disables the lazy mode for the domains added afterwards with AddDomain on this Locale,
+so apps with many domains but only a few used on each request don't parse every catalog on startup:
+
+    l := gotext.NewLocale("/path/to/i18n/dir", "es")
+    l.SetLazy(true)
+
+    // Only the file path is resolved here
+    l.AddDomain("billing")
+    l.AddDomain("reports")
+
+    // The "billing" catalog is parsed now, "reports" is left untouched
+    println(l.GetD("billing", "Invoice"))
+
+On lazy mode, AddDomain registers the domain by name and its file is parsed on the first lookup of the domain.
+Concurrent first lookups parse the file only once, the rest of them wait for it to be loaded.
+Load errors are ignored like on AddDomain; use Preload to load the domains up front and get them.
+Disabling the lazy mode doesn't load the domains already registered, they're still parsed on their first lookup.
+
+The fallbacks created along with this Locale get the same mode.
+*/
+func (l *Locale) SetLazy(lazy bool) {
+	l.Lock()
+	l.lazy = lazy
+	fb, own := l.fallback, l.ownFallback
+	l.Unlock()
+
+	if own {
+		fb.SetLazy(lazy)
+	}
+}
+
+/*
+Preload parses the files of the given domains (doms) registered on lazy mode and not loaded yet,
+or of every one of them if none is given, restoring the eager behaviour of AddDomain where it's needed,
+like warming up the catalogs before serving requests:
+
+    if errs := l.Preload(); len(errs) > 0 {
+        log.Println(errs)
+    }
+
+It returns the errors found loading the files, like missing or badly formatted ones.
+Domains already loaded, not registered or being loaded by a lookup are skipped, waiting for the latter to finish.
+The fallbacks created along with this Locale preload the same domains, and their errors aren't reported.
+*/
+func (l *Locale) Preload(doms ...string) []error {
+	errs := l.preload(doms...)
+
+	l.RLock()
+	fb, own := l.fallback, l.ownFallback
+	l.RUnlock()
+
+	if own {
+		fb.Preload(doms...)
+	}
+
+	return errs
+}
+
+// preload works like Preload, without preloading the fallbacks.
+func (l *Locale) preload(doms ...string) []error {
+	l.RLock()
+	if len(doms) == 0 {
+		for dom := range l.pending {
+			doms = append(doms, dom)
+		}
+		sort.Strings(doms)
+	}
+
+	pending := make([]*lazyDomain, len(doms))
+	for i, dom := range doms {
+		pending[i] = l.pending[dom]
+	}
+	l.RUnlock()
+
+	var errs []error
+	for i, d := range pending {
+		if d == nil {
+			continue
+		}
+
+		if err := l.loadPending(doms[i], d); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// setPending registers the given domain (dom) to be loaded from the given file (filename) on its first lookup,
+// replacing it if it exists.
+func (l *Locale) setPending(dom, filename string) {
+	l.Lock()
+	defer l.Unlock()
+	l.changed()
+
+	if l.pending == nil {
+		l.pending = make(map[string]*lazyDomain)
+	}
+	l.pending[dom] = &lazyDomain{filename: filename}
+
+	delete(l.domains, dom)
+	delete(l.shared, dom)
+	delete(l.files, dom)
+}
+
+// loadPending parses the file of the given pending domain (d), registered as the given domain (dom), only once,
+// and saves it unless the domain was replaced meanwhile. It returns the load error, if any.
+func (l *Locale) loadPending(dom string, d *lazyDomain) error {
+	d.once.Do(func() {
+		var po *Po
+		po, d.err = l.loadFile(d.filename)
+
+		l.Lock()
+		defer l.Unlock()
+
+		if l.pending[dom] != d {
+			return
+		}
+		l.changed()
+
+		l.storeDomain(dom, d.filename, po)
+	})
+
+	return d.err
+}
@@ -0,0 +1,99 @@
+package gotext
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+	"testing"
+)
+
+func TestLocaleLazy(t *testing.T) {
+	// Create Locale directory
+	dirname := path.Join("/tmp", "lazy", "es")
+	err := os.MkdirAll(dirname, os.ModePerm)
+	if err != nil {
+		t.Fatalf("Can't create test directory: %s", err.Error())
+	}
+
+	write := func(dom, str string) {
+		if err := ioutil.WriteFile(path.Join(dirname, dom+".po"), []byte(str), 0644); err != nil {
+			t.Fatalf("Can't write to test file: %s", err.Error())
+		}
+	}
+
+	write("billing", `
+msgid "Invoice"
+msgstr "Borrador"
+`)
+	write("reports", `
+msgid "Report"
+msgstr "Informe"
+`)
+	os.Remove(path.Join(dirname, "missing.po"))
+
+	l := NewLocale(path.Join("/tmp", "lazy"), "es")
+	l.SetLazy(true)
+	l.AddDomain("billing")
+	l.AddDomain("reports")
+	l.AddDomain("missing")
+
+	// Domains are registered, but not parsed
+	if doms := l.GetDomains(); len(doms) != 3 || doms[0] != "billing" || doms[1] != "missing" || doms[2] != "reports" {
+		t.Errorf("Expected the registered domains but got %v", doms)
+	}
+	if entries, _ := l.MemStats(); entries != 0 {
+		t.Errorf("Expected no entries loaded but got %d", entries)
+	}
+
+	// The file is read on the first lookup
+	write("billing", `
+msgid "Invoice"
+msgstr "Factura"
+`)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if tr := l.GetD("billing", "Invoice"); tr != "Factura" {
+				t.Errorf("Expected 'Factura' but got '%s'", tr)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if entries, _ := l.MemStats(); entries != 1 {
+		t.Errorf("Expected only the looked up domain loaded but got %d entries", entries)
+	}
+
+	// Preload the rest of the domains
+	if errs := l.Preload(); len(errs) != 1 {
+		t.Errorf("Expected an error for the missing domain but got %v", errs)
+	}
+	if entries, _ := l.MemStats(); entries != 2 {
+		t.Errorf("Expected every domain loaded but got %d entries", entries)
+	}
+	if tr := l.GetD("reports", "Report"); tr != "Informe" {
+		t.Errorf("Expected 'Informe' but got '%s'", tr)
+	}
+	if errs := l.Preload("reports"); len(errs) != 0 {
+		t.Errorf("Expected no errors preloading a loaded domain but got %v", errs)
+	}
+
+	// Domains added on eager mode replace the registered ones
+	l.AddDomain("reports")
+	l.SetLazy(false)
+	write("reports", `
+msgid "Report"
+msgstr "Reporte"
+`)
+	l.AddDomain("reports")
+	if tr := l.GetD("reports", "Report"); tr != "Reporte" {
+		t.Errorf("Expected 'Reporte' but got '%s'", tr)
+	}
+	if errs := l.Preload("reports"); len(errs) != 0 {
+		t.Errorf("Expected no errors for a domain added on eager mode but got %v", errs)
+	}
+}
@@ -0,0 +1,120 @@
+package gotext
+
+import (
+	"io/fs"
+	"os"
+	"sort"
+	"sync"
+)
+
+/*
+Library holds a Locale object for each language found on a library directory, with all its domains loaded,
+so apps don't need to enumerate the language directories and add each domain themselves:
+
+    import "github.com/leonelquinteros/gotext"
+
+    func main() {
+        // Load every domain of every language on '/path/to/i18n/dir'
+        lib, errs := gotext.LoadLibrary("/path/to/i18n/dir")
+        for _, err := range errs {
+            log.Println(err)
+        }
+
+        // Use the 'de_DE' Locale
+        println(lib.Locale("de_DE").Get("Translate this"))
+    }
+
+The directory is expected to use the same layout as NewLocale: one directory per language code
+containing the catalog files of its domains, loaded with Locale.AddAllDomains.
+*/
+type Library struct {
+	// Loaded Locale objects by language.
+	locales map[string]*Locale
+
+	// Sorted language codes.
+	langs []string
+
+	// Sync Mutex
+	sync.RWMutex
+}
+
+// LoadLibrary creates a new Library object loading every language directory found on the given path (p).
+// Directories without catalog files aren't loaded as languages.
+// It returns the Library along with the errors found reading the directory or loading its catalogs, if any.
+func LoadLibrary(p string) (*Library, []error) {
+	lib := &Library{locales: make(map[string]*Locale)}
+
+	entries, err := os.ReadDir(p)
+	if err != nil {
+		return lib, []error{err}
+	}
+
+	return lib, lib.load(entries, func(lang string) *Locale {
+		return NewLocale(p, lang)
+	})
+}
+
+// LoadLibraryFS works like LoadLibrary, loading the language directories found on the root
+// of the given file system (fsys), like NewLocaleFS.
+func LoadLibraryFS(fsys fs.FS) (*Library, []error) {
+	lib := &Library{locales: make(map[string]*Locale)}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return lib, []error{err}
+	}
+
+	return lib, lib.load(entries, func(lang string) *Locale {
+		return NewLocaleFS(fsys, lang)
+	})
+}
+
+// load creates a Locale object with the given function (locale) for each directory of the given entries,
+// and keeps the ones with any domain. It returns the errors found loading the domains.
+func (lib *Library) load(entries []fs.DirEntry, locale func(lang string) *Locale) []error {
+	lib.Lock()
+	defer lib.Unlock()
+
+	var errs []error
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		l := locale(entry.Name())
+		errs = append(errs, l.AddAllDomains()...)
+
+		if len(l.GetDomains()) == 0 {
+			continue
+		}
+
+		lib.locales[entry.Name()] = l
+		lib.langs = append(lib.langs, entry.Name())
+	}
+
+	sort.Strings(lib.langs)
+
+	return errs
+}
+
+// Languages returns the sorted codes of the languages loaded on this Library.
+func (lib *Library) Languages() []string {
+	lib.RLock()
+	defer lib.RUnlock()
+
+	return append([]string(nil), lib.langs...)
+}
+
+// Locale returns the Locale object for the given language code (lang), or nil if it isn't available.
+// When the language isn't loaded, its closest available language is used, as chosen by MatchLanguage,
+// so "de_AT" gets the "de" or "de_DE" Locale.
+func (lib *Library) Locale(lang string) *Locale {
+	lib.RLock()
+	defer lib.RUnlock()
+
+	if l, ok := lib.locales[lang]; ok {
+		return l
+	}
+
+	return lib.locales[MatchLanguage([]string{lang}, lib.langs)]
+}
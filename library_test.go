@@ -0,0 +1,92 @@
+package gotext
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadLibrary(t *testing.T) {
+	// Set catalog files content
+	files := map[string]string{
+		"de_DE/default.po":         "msgid \"My text\"\nmsgstr \"Mein Text\"\n",
+		"de_DE/extras.po":          "msgid \"Extra text\"\nmsgstr \"Zusatztext\"\n",
+		"es/default.json":          `{"My text": "Mi texto"}`,
+		"es/LC_MESSAGES/extras.po": "msgid \"Extra text\"\nmsgstr \"Texto extra\"\n",
+		"fr/README":                "Not a catalog\n",
+		"LICENSE":                  "Not a language\n",
+	}
+
+	root := path.Clean("/tmp" + string(os.PathSeparator) + "library")
+	os.RemoveAll(root)
+
+	fsys := fstest.MapFS{}
+	for name, str := range files {
+		filename := path.Join(root, name)
+
+		err := os.MkdirAll(path.Dir(filename), os.ModePerm)
+		if err != nil {
+			t.Fatalf("Can't create test directory: %s", err.Error())
+		}
+
+		err = ioutil.WriteFile(filename, []byte(str), 0644)
+		if err != nil {
+			t.Fatalf("Can't write to test file: %s", err.Error())
+		}
+
+		fsys[name] = &fstest.MapFile{Data: []byte(str)}
+	}
+
+	for _, load := range []func() (*Library, []error){
+		func() (*Library, []error) { return LoadLibrary(root) },
+		func() (*Library, []error) { return LoadLibraryFS(fsys) },
+	} {
+		lib, errs := load()
+		if len(errs) != 0 {
+			t.Fatalf("Unexpected errors: %v", errs)
+		}
+
+		if langs := lib.Languages(); strings.Join(langs, ",") != "de_DE,es" {
+			t.Errorf("Unexpected languages: %v", langs)
+		}
+
+		tests := map[string]string{
+			"de_DE": "Mein Text",
+			"de":    "Mein Text",
+			"es_AR": "Mi texto",
+		}
+		for lang, expected := range tests {
+			l := lib.Locale(lang)
+			if l == nil {
+				t.Errorf("Expected Locale for '%s'", lang)
+				continue
+			}
+
+			if tr := l.Get("My text"); tr != expected {
+				t.Errorf("Expected '%s' but got '%s'", expected, tr)
+			}
+		}
+
+		if tr := lib.Locale("es").GetD("extras", "Extra text"); tr != "Texto extra" {
+			t.Errorf("Expected 'Texto extra' but got '%s'", tr)
+		}
+
+		for _, lang := range []string{"fr", "it"} {
+			if lib.Locale(lang) != nil {
+				t.Errorf("Unexpected Locale for '%s'", lang)
+			}
+		}
+	}
+
+	// Missing directory
+	lib, errs := LoadLibrary(path.Join(root, "missing"))
+	if len(errs) != 1 {
+		t.Errorf("Expected 1 error but got %v", errs)
+	}
+	if len(lib.Languages()) != 0 {
+		t.Errorf("Unexpected languages: %v", lib.Languages())
+	}
+}
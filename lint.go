@@ -0,0 +1,209 @@
+package gotext
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Warning describes a potential problem found on a catalog entry.
+type Warning struct {
+	// Context and msgid of the entry.
+	Context string
+	MsgID   string
+
+	// Index of the msgstr the warning applies to, or -1 when it applies to the whole entry.
+	Index int
+
+	// Line of the entry msgid on the parsed content, or 0 if unknown.
+	Line int
+
+	// Description of the problem.
+	Message string
+}
+
+// String returns the warning in a human readable form.
+func (w Warning) String() string {
+	entry := entryKey{ctx: w.Context, id: w.MsgID}.String()
+
+	if w.Line > 0 {
+		entry = "line " + strconv.Itoa(w.Line) + ": " + entry
+	}
+
+	if w.Index < 0 {
+		return entry + ": " + w.Message
+	}
+
+	return fmt.Sprintf("%s (msgstr[%d]): %s", entry, w.Index, w.Message)
+}
+
+// Error returns the warning in a human readable form, so it can be used as an error.
+func (w Warning) Error() string {
+	return w.String()
+}
+
+// Equivalent trailing punctuation marks, mapped to the name reported on warnings.
+var trailingPunctuation = map[string]string{
+	"...": "ellipsis",
+	"…":   "ellipsis",
+	":":   "colon",
+	"：":   "colon",
+	".":   "period",
+	"。":   "period",
+	"!":   "exclamation mark",
+	"！":   "exclamation mark",
+	"?":   "question mark",
+	"？":   "question mark",
+	"؟":   "question mark",
+	";":   "semicolon",
+	"；":   "semicolon",
+	",":   "comma",
+	"，":   "comma",
+	"、":   "comma",
+}
+
+/*
+LintPunctuation checks every translated string on the catalog against its source string
+and returns a warning for each one whose trailing whitespace or punctuation doesn't match the source.
+
+The singular msgstr is compared with the msgid, and the plural forms with the msgid_plural.
+Trailing punctuation marks are compared by kind so equivalent marks in other scripts are accepted.
+For example, a source ending with ": " requires the translation to end with a colon (":" or "：")
+followed by trailing whitespace too.
+
+Untranslated (empty) strings and the header entry are ignored.
+Warnings are sorted by context, msgid and msgstr index.
+*/
+func (po *Po) LintPunctuation() []Warning {
+	entries := po.snapshot()
+
+	// Sort entries
+	keys := make([]entryKey, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sortEntryKeys(keys)
+
+	warnings := make([]Warning, 0)
+
+	for _, k := range keys {
+		t := entries[k]
+
+		// Skip header
+		if t.ID == "" {
+			continue
+		}
+
+		for _, i := range sortedIndexes(t.Trs) {
+			tr := t.Trs[i]
+			if tr == "" {
+				continue
+			}
+
+			// Get source string for this form
+			src := t.ID
+			if i > 0 && t.PluralID != "" {
+				src = t.PluralID
+			}
+
+			for _, msg := range lintTrailing(src, tr) {
+				warnings = append(warnings, Warning{
+					Context: k.ctx,
+					MsgID:   k.id,
+					Index:   i,
+					Message: msg,
+				})
+			}
+		}
+	}
+
+	return warnings
+}
+
+// loadWarnings returns the warnings reported on load for the translation: one for the whole entry
+// if it's untranslated or fuzzy, or one for each empty plural form if it's partially translated.
+func (t *Translation) loadWarnings() []Warning {
+	// Skip header
+	if t.ID == "" {
+		return nil
+	}
+
+	warning := func(i int, msg string) Warning {
+		return Warning{Context: t.Context, MsgID: t.ID, Index: i, Line: t.Line, Message: msg}
+	}
+
+	// Collect empty forms
+	empty := make([]int, 0)
+	for _, i := range sortedIndexes(t.Trs) {
+		if t.Trs[i] == "" {
+			empty = append(empty, i)
+		}
+	}
+
+	switch {
+	case len(empty) == len(t.Trs):
+		return []Warning{warning(-1, "untranslated entry")}
+	case t.HasFlag("fuzzy"):
+		return []Warning{warning(-1, "fuzzy entry")}
+	}
+
+	warnings := make([]Warning, 0, len(empty))
+	for _, i := range empty {
+		warnings = append(warnings, warning(i, "untranslated plural form"))
+	}
+
+	return warnings
+}
+
+// lintTrailing compares the trailing whitespace and punctuation of the source (src) and translated (tr) strings
+// and returns a message for each mismatch found.
+func lintTrailing(src, tr string) []string {
+	msgs := make([]string, 0)
+
+	// Compare trailing whitespace
+	srcTrim := strings.TrimRightFunc(src, unicode.IsSpace)
+	trTrim := strings.TrimRightFunc(tr, unicode.IsSpace)
+
+	srcSpace := len(srcTrim) < len(src)
+	trSpace := len(trTrim) < len(tr)
+
+	if srcSpace && !trSpace {
+		msgs = append(msgs, "source ends with whitespace but translation doesn't")
+	} else if !srcSpace && trSpace {
+		msgs = append(msgs, "translation ends with whitespace but source doesn't")
+	}
+
+	// Compare trailing punctuation
+	srcPunct := lastPunctuation(srcTrim)
+	trPunct := lastPunctuation(trTrim)
+
+	if srcPunct != trPunct {
+		switch {
+		case trPunct == "":
+			msgs = append(msgs, "source ends with "+srcPunct+" but translation doesn't")
+		case srcPunct == "":
+			msgs = append(msgs, "translation ends with "+trPunct+" but source doesn't")
+		default:
+			msgs = append(msgs, "source ends with "+srcPunct+" but translation ends with "+trPunct)
+		}
+	}
+
+	return msgs
+}
+
+// lastPunctuation returns the kind of trailing punctuation mark of the given string, or an empty string if there isn't any.
+func lastPunctuation(str string) string {
+	// Ellipsis has to be checked before the period
+	if strings.HasSuffix(str, "...") {
+		return trailingPunctuation["..."]
+	}
+
+	r, _ := utf8.DecodeLastRuneInString(str)
+	if r == utf8.RuneError {
+		return ""
+	}
+
+	return trailingPunctuation[string(r)]
+}
@@ -0,0 +1,71 @@
+package gotext
+
+import (
+	"testing"
+)
+
+func TestLintPunctuation(t *testing.T) {
+	// Set PO content
+	str := `
+msgid ""
+msgstr "Language: es\n"
+
+msgid "Name: "
+msgstr "Nombre:"
+
+msgid "Loading..."
+msgstr "Cargando…"
+
+msgid "Saving..."
+msgstr "Guardando"
+
+msgid "Are you sure?"
+msgstr "¿Está seguro?"
+
+msgid "Done"
+msgstr "Listo."
+
+msgid "Untranslated: "
+msgstr ""
+
+msgid "Full width:"
+msgstr "全角："
+
+msgid "%d file"
+msgid_plural "%d files:"
+msgstr[0] "%d archivo"
+msgstr[1] "%d archivos"
+
+msgctxt "Ctx"
+msgid "Value: "
+msgstr "Valor "
+    `
+
+	po := new(Po)
+	po.Parse(str)
+
+	expected := []string{
+		`msgid "%d file" (msgstr[1]): source ends with colon but translation doesn't`,
+		`msgid "Done" (msgstr[0]): translation ends with period but source doesn't`,
+		`msgid "Name: " (msgstr[0]): source ends with whitespace but translation doesn't`,
+		`msgid "Saving..." (msgstr[0]): source ends with ellipsis but translation doesn't`,
+		`msgctxt "Ctx" msgid "Value: " (msgstr[0]): source ends with colon but translation doesn't`,
+	}
+
+	warnings := po.LintPunctuation()
+	if len(warnings) != len(expected) {
+		t.Fatalf("Expected %d warnings but got %d: %v", len(expected), len(warnings), warnings)
+	}
+
+	for i, w := range warnings {
+		if w.String() != expected[i] {
+			t.Errorf("Expected warning '%s' but got '%s'", expected[i], w.String())
+		}
+	}
+
+	// Test entry level warnings format
+	w := Warning{MsgID: "My text", Index: -1, Message: "Some problem"}
+	if w.String() != `msgid "My text": Some problem` {
+		t.Errorf("Expected 'msgid \"My text\": Some problem' but got '%s'", w.String())
+	}
+}
@@ -1,10 +1,19 @@
 package gotext
 
 import (
+	"archive/tar"
 	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"log"
 	"os"
 	"path"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 /*
@@ -38,12 +47,81 @@ type Locale struct {
 	// Path to locale files.
 	path string
 
+	// File system holding the locale files, or nil to use the OS file system.
+	fsys fs.FS
+
 	// Language for this Locale
 	lang string
 
 	// List of available domains for this locale.
 	domains map[string]*Po
 
+	// Domain used by the methods without a domain parameter, or empty to use "default".
+	domain string
+
+	// Domains attached with a Po object shared with other locales.
+	shared map[string]bool
+
+	// Files the domains were loaded from, used to reload them.
+	files map[string]string
+
+	// Locale to look at when a translation isn't found in this one.
+	fallback *Locale
+
+	// The fallback was created along with this Locale by NewLocaleWithFallback, so it gets the same domains.
+	ownFallback bool
+
+	// Return the source string when a translation receives less arguments than it requires.
+	verifyArgs bool
+
+	// Collapse runs of whitespace on msgids and looked up strings.
+	collapse bool
+
+	// Use the entries flagged as fuzzy on lookups.
+	fuzzy bool
+
+	// Reject files that can't be converted to UTF-8 from their declared charset.
+	strictCharset bool
+
+	// Supply the count as argument to plural translations called without vars.
+	autoCount bool
+
+	// Return the strings without formatting them when there are no vars.
+	rawWithoutVars bool
+
+	// Format used to mark untranslated strings.
+	missingFormat string
+
+	// Regional language codes to load generic language codes from, when their files aren't available.
+	regionDefaults map[string]string
+
+	// Texts returned by plural lookups when the count is zero.
+	zeroForms map[zeroKey]string
+
+	// Named vars used by the Named lookups in each context, unless the call gives its own.
+	contextVars map[string]map[string]interface{}
+
+	// Use the closest available plural form when the requested one is missing.
+	lenientPlural bool
+
+	// Function called for each string that isn't translated.
+	missingHandler func(dom, ctx, id string, n int)
+
+	// Collector saving each string that isn't translated.
+	missingCollector *MissingCollector
+
+	// Translations set at runtime by domain, taking precedence over the domain catalogs.
+	overrides map[string]*Po
+
+	// Register the domains added with AddDomain without parsing their files until their first lookup.
+	lazy bool
+
+	// Domains registered on lazy mode whose files weren't parsed yet.
+	pending map[string]*lazyDomain
+
+	// Lookup state published for the lookups, or a nil *localeView when it has to be built again.
+	published atomic.Value
+
 	// Sync Mutex
 	sync.RWMutex
 }
@@ -58,108 +136,1110 @@ func NewLocale(p, l string) *Locale {
 	}
 }
 
+/*
+NewLocaleWithFallback creates and initializes a new Locale object for a given language (lang),
+with a fallback chain made of a new Locale for each of the given languages (fallbacks), in order,
+all of them using the same path for the i18n files directory (p):
+
+    l := gotext.NewLocaleWithFallback("/path/to/i18n/dir", "pt_BR", "pt", "en")
+    l.AddDomain("default")
+
+Domains added with AddDomain are loaded on every Locale of the chain, so lookups consult the "pt_BR", "pt"
+and "en" catalogs, in that order, before returning the untranslated string.
+*/
+func NewLocaleWithFallback(p, lang string, fallbacks ...string) *Locale {
+	l := NewLocale(p, lang)
+
+	for last, i := l, 0; i < len(fallbacks); i++ {
+		fb := NewLocale(p, fallbacks[i])
+
+		last.SetFallback(fb)
+		last.ownFallback = true
+		last = fb
+	}
+
+	return l
+}
+
+// NewLocaleExecRelative creates and initializes a new Locale object for a given language (lang),
+// using a path for the i18n files directory (subpath) relative to the directory of the running executable.
+// Symbolic links to the executable are resolved, so translations can be installed next to the real binary.
+// It returns an error if the executable path can't be determined.
+func NewLocaleExecRelative(subpath, lang string) (*Locale, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve symbolic links
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	return NewLocale(filepath.Join(filepath.Dir(exe), subpath), lang), nil
+}
+
+/*
+NewLocaleFS creates and initializes a new Locale object for a given language (lang),
+reading the i18n files from the root of the given file system (fsys) instead of the OS file system,
+so translations embedded with embed.FS can be used:
+
+    //go:embed i18n
+    var i18n embed.FS
+
+    func main() {
+        sub, _ := fs.Sub(i18n, "i18n")
+        l := gotext.NewLocaleFS(sub, "en_US")
+
+        // Load domain 'i18n/en_US/default.po' from the embedded files
+        l.AddDomain("default")
+    }
+
+The files are looked up the same way as on a directory, generic language code fallback included,
+using forward slash separated paths as required by fs.FS.
+*/
+func NewLocaleFS(fsys fs.FS, lang string) *Locale {
+	l := NewLocale(".", lang)
+	l.fsys = fsys
+
+	return l
+}
+
 // AddDomain creates a new domain for a given locale object and initializes the Po object.
+// The PO file of the domain is used when available, falling back to the compiled MO file,
+// so deployments shipping only the files generated by msgfmt work as well, and then to the JSON catalog (see JSON)
+// and the XLIFF one, with the ".xlf" or ".xliff" extension (see XLIFF).
 // If the domain exists, it gets reloaded.
+// On lazy mode, the file is parsed on the first lookup of the domain instead (see SetLazy).
 func (l *Locale) AddDomain(dom string) {
+	filename := l.domainFile(dom, catalogExts...)
+
+	l.RLock()
+	lazy := l.lazy
+	l.RUnlock()
+
+	if lazy {
+		l.setPending(dom, filename)
+	} else {
+		// Parse file.
+		po, _ := l.loadFile(filename)
+
+		// Save new domain
+		l.setDomain(dom, filename, po)
+	}
+
+	// Add domain to the fallbacks created along with this Locale
+	l.RLock()
+	fb, own := l.fallback, l.ownFallback
+	l.RUnlock()
+
+	if own {
+		fb.AddDomain(dom)
+	}
+}
+
+// catalogExts are the file extensions of the catalogs loaded by AddDomain, in order of preference.
+var catalogExts = []string{".po", ".mo", ".json", ".xlf", ".xliff"}
+
+// catalogFormats maps the catalog formats accepted by AddDomainFormat to their file extensions, in order of preference.
+var catalogFormats = map[string][]string{
+	"po":    {".po"},
+	"mo":    {".mo"},
+	"json":  {".json"},
+	"xliff": {".xlf", ".xliff"},
+}
+
+// AddDomainFormat works like AddDomain, loading the domain only from the file of the given catalog format:
+// "po", "mo", "json" or "xliff", for languages shipping the same domain on several formats.
+// It returns an error if the format isn't supported or the file can't be loaded, without changing the domain.
+// The fallbacks created along with this Locale load the domain the same way, and their errors aren't reported.
+func (l *Locale) AddDomainFormat(dom, format string) error {
+	exts, ok := catalogFormats[strings.ToLower(format)]
+	if !ok {
+		return fmt.Errorf("unsupported catalog format %q", format)
+	}
+
+	filename := l.domainFile(dom, exts...)
+	po, err := l.loadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	// Save new domain
+	l.setDomain(dom, filename, po)
+
+	// Add domain to the fallbacks created along with this Locale
+	l.RLock()
+	fb, own := l.fallback, l.ownFallback
+	l.RUnlock()
+
+	if own {
+		fb.AddDomainFormat(dom, format)
+	}
+
+	return nil
+}
+
+/*
+AddDomainsGlob loads every PO file matching the given pattern as a domain for this Locale,
+using the file name without the ".po" extension as the domain name.
+It returns the errors found, or nil if every matching file was loaded.
+
+The pattern uses the filepath.Match syntax and is relative to the language directory,
+the same one used by AddDomain: the full language code one if it exists, or the generic language one.
+Files in subdirectories are only matched when the pattern includes them,
+so the GNU gettext layout can be loaded with an "LC_MESSAGES/*.po" pattern:
+
+    // Load '/path/to/i18n/dir/en_US/*.po'
+    l.AddDomainsGlob("*.po")
+
+    // Load '/path/to/i18n/dir/en_US/LC_MESSAGES/*.po'
+    l.AddDomainsGlob("LC_MESSAGES/*.po")
+
+Matching directories and files without the ".po" extension are skipped.
+Existing domains with the same name get reloaded.
+If more than one matching file results in the same domain name, the first one in lexical order is loaded
+and an error is returned for the others.
+*/
+func (l *Locale) AddDomainsGlob(pattern string) []error {
+	dir := l.langDir()
+
+	matches, err := l.glob(l.join(dir, pattern))
+	if err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	loaded := make(map[string]string)
+
+	for _, filename := range matches {
+		// Skip directories and other files
+		info, err := l.stat(filename)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if info.IsDir() || filepath.Ext(filename) != ".po" {
+			continue
+		}
+
+		// Check for duplicated domains
+		dom := strings.TrimSuffix(filepath.Base(filename), ".po")
+		if prev, ok := loaded[dom]; ok {
+			errs = append(errs, fmt.Errorf("%s: domain %q already loaded from %s", filename, dom, prev))
+			continue
+		}
+
+		// Parse file
+		po, err := l.loadFile(filename)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		l.setDomain(dom, filename, po)
+		loaded[dom] = filename
+	}
+
+	return errs
+}
+
+/*
+AddAllDomains loads every catalog file found on the language directory of this Locale as a domain,
+the same directory used by AddDomain, so apps with many domains don't need to add them one by one.
+It returns the errors found, or nil if every catalog file was loaded.
+
+The domain names are the file names without their extension. Like on AddDomain, the PO file of a domain is preferred
+over its MO file, and both over its JSON and XLIFF catalogs (see JSON and XLIFF). Files on the GNU gettext "LC_MESSAGES" subdirectory
+are loaded as well, unless the language directory has a file for the same domain:
+
+    // Loads 'en_US/default.po', 'en_US/extras.mo' and 'en_US/LC_MESSAGES/errors.po'
+    l.AddAllDomains()
+
+Existing domains with the same name get reloaded. Files that can't be loaded, like invalid MO files, are skipped
+and their errors returned. Other files and directories are skipped as well.
+The fallbacks created along with this Locale by NewLocaleWithFallback load all their domains as well.
+*/
+func (l *Locale) AddAllDomains() []error {
+	dir := l.langDir()
+
+	// Find catalog files in order of preference
+	files := make(map[string]string)
+	var doms []string
+
+	for _, d := range []string{dir, l.join(dir, "LC_MESSAGES")} {
+		for _, ext := range catalogExts {
+			matches, err := l.glob(l.join(d, "*"+ext))
+			if err != nil {
+				return []error{err}
+			}
+
+			for _, filename := range matches {
+				dom := strings.TrimSuffix(filepath.Base(filename), ext)
+				if _, ok := files[dom]; ok {
+					continue
+				}
+
+				if info, err := l.stat(filename); err != nil || info.IsDir() {
+					continue
+				}
+
+				files[dom] = filename
+				doms = append(doms, dom)
+			}
+		}
+	}
+
+	var errs []error
+	for _, dom := range doms {
+		po, err := l.loadFile(files[dom])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		l.setDomain(dom, files[dom], po)
+	}
+
+	// Add domains to the fallbacks created along with this Locale
+	l.RLock()
+	fb, own := l.fallback, l.ownFallback
+	l.RUnlock()
+
+	if own {
+		errs = append(errs, fb.AddAllDomains()...)
+	}
+
+	return errs
+}
+
+/*
+AddDomainsTar reads a tar archive stream (r) and loads every PO file for the given language code (lang) as a domain
+for this Locale, using the file name without the ".po" extension as the domain name, like AddDomainsGlob.
+It returns the errors found, or nil if every PO file for the language was loaded.
+
+The archive is expected to use the same layout as the library directory, relative to its root:
+"<lang>/<domain>.po" or the GNU gettext "<lang>/LC_MESSAGES/<domain>.po". A leading "./" is accepted.
+For example, l.AddDomainsTar(r, "es") loads the "default" and "extras" domains from this archive:
+
+    es/default.po
+    es/LC_MESSAGES/extras.po
+    fr/default.po
+
+An empty language code uses the language of this Locale. Other archive entries are skipped.
+Existing domains with the same name get reloaded. If more than one entry results in the same domain name,
+the first one in the archive is loaded and an error is returned for the others.
+Reading stops at the first error reading the archive itself.
+*/
+func (l *Locale) AddDomainsTar(r io.Reader, lang string) []error {
+	if lang == "" {
+		lang = l.lang
+	}
+
+	var errs []error
+	loaded := make(map[string]string)
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return append(errs, err)
+		}
+
+		// Check layout
+		name := strings.TrimPrefix(hdr.Name, "./")
+		dir, file := path.Split(name)
+		if hdr.Typeflag != tar.TypeReg || path.Ext(file) != ".po" {
+			continue
+		}
+		if dir != lang+"/" && dir != lang+"/LC_MESSAGES/" {
+			continue
+		}
+
+		// Check for duplicated domains
+		dom := strings.TrimSuffix(file, ".po")
+		if prev, ok := loaded[dom]; ok {
+			errs = append(errs, fmt.Errorf("%s: domain %q already loaded from %s", hdr.Name, dom, prev))
+			continue
+		}
+
+		// Parse entry
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return append(errs, err)
+		}
+
+		po := l.newPo()
+		if err := po.parse(string(data)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s", hdr.Name, err.Error()))
+			continue
+		}
+
+		l.setDomain(dom, "", po)
+		loaded[dom] = hdr.Name
+	}
+
+	return errs
+}
+
+// newPo returns an empty Po object with the locale settings applied.
+func (l *Locale) newPo() *Po {
 	po := new(Po)
 
-	// Check for file.
-	filename := path.Clean(l.path + string(os.PathSeparator) + l.lang + string(os.PathSeparator) + dom + ".po")
+	l.RLock()
+	po.SetCollapseWhitespace(l.collapse)
+	po.SetFuzzyEnabled(l.fuzzy)
+	po.SetStrictCharset(l.strictCharset)
+	l.RUnlock()
+
+	return po
+}
+
+// loadFile returns a new Po object with the locale settings applied, holding the content of the given PO, MO, JSON or XLIFF file.
+// It returns the empty Po object along with the error if the file can't be read or isn't a valid MO, JSON or XLIFF file,
+// or a PO file that can't be converted to UTF-8 on the strict charset mode.
+func (l *Locale) loadFile(filename string) (*Po, error) {
+	po := l.newPo()
+
+	data, err := l.readFile(filename)
+	if err != nil {
+		return po, err
+	}
+
+	switch path.Ext(filename) {
+	case ".mo":
+		err = po.parseMO(data)
+	case ".json":
+		err = po.parseJSON(data)
+	case ".xlf", ".xliff":
+		err = po.parseXLIFF(data)
+	default:
+		err = po.parse(string(data))
+	}
+
+	if err != nil {
+		return po, fmt.Errorf("%s: %s", filename, err.Error())
+	}
+
+	return po, nil
+}
+
+// setDomain saves the given Po object (po) as a domain (dom) owned by this Locale,
+// loaded from the given file (filename), or from another source if it's empty.
+func (l *Locale) setDomain(dom, filename string, po *Po) {
+	l.Lock()
+	defer l.Unlock()
+	l.changed()
+
+	l.storeDomain(dom, filename, po)
+}
+
+// storeDomain works like setDomain, for callers already holding the lock.
+func (l *Locale) storeDomain(dom, filename string, po *Po) {
+	if l.domains == nil {
+		l.domains = make(map[string]*Po)
+	}
+	l.domains[dom] = po
+	delete(l.shared, dom)
+	delete(l.pending, dom)
 
-	// Try to use the generic language dir if the provided isn't available
-	if _, err := os.Stat(filename); err != nil {
-		if len(l.lang) > 2 {
-			filename = path.Clean(l.path + string(os.PathSeparator) + l.lang[:2] + string(os.PathSeparator) + dom + ".po")
+	if filename == "" {
+		delete(l.files, dom)
+		return
+	}
+
+	if l.files == nil {
+		l.files = make(map[string]string)
+	}
+	l.files[dom] = filename
+}
+
+// domainFile returns the path of the file for the given domain (dom), using the first of the given extensions (exts) found.
+// The language dirs are tried in the order returned by langDirs, using the first one containing any of the files.
+// If none is found, it returns the path for the first extension on the last language dir.
+func (l *Locale) domainFile(dom string, exts ...string) string {
+	dirs := l.langDirs()
+
+	for _, dir := range dirs {
+		for _, ext := range exts {
+			// Check for file.
+			filename := l.join(dir, dom+ext)
+			if _, err := l.stat(filename); err == nil {
+				return filename
+			}
 		}
 	}
 
-	// Parse file.
-	po.ParseFile(filename)
+	return l.join(dirs[len(dirs)-1], dom+exts[0])
+}
 
-	// Save new domain
+// langDir returns the directory holding the PO files for this Locale.
+// The language dirs are tried in the order returned by langDirs, using the first one available.
+func (l *Locale) langDir() string {
+	dirs := l.langDirs()
+
+	for _, dir := range dirs {
+		if info, err := l.stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+
+	return dirs[0]
+}
+
+// langDirs returns the candidate directories for the PO files of this Locale, in the order they have to be tried:
+// the full language code dir, the dirs of the language code normalized by NormalizeLanguage and its candidates
+// (see MatchLanguage), ending with the generic language dir, and the default region dir set with SetRegionDefault.
+func (l *Locale) langDirs() []string {
+	codes := []string{l.lang, strings.Replace(l.lang, "-", "_", -1)}
+
+	// Try the normalized codes and the generic language dir if the provided isn't available
+	norm := NormalizeLanguage(l.lang)
+	generic, _, _ := splitLanguage(norm)
+	if norm != "" {
+		codes = append(codes, languageCandidates(norm)...)
+	}
+
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		if !seen[code] {
+			seen[code] = true
+			dirs = append(dirs, l.join(l.path, code))
+		}
+	}
+
+	// Try to use the default region dir if the generic one isn't available
+	l.RLock()
+	region := l.regionDefaults[generic]
+	l.RUnlock()
+
+	if region != "" && !seen[region] {
+		dirs = append(dirs, l.join(l.path, region))
+	}
+
+	return dirs
+}
+
+// join returns the path of the given file name (name) inside a directory (dir) of the locale files,
+// using forward slashes when they are read from a fs.FS.
+func (l *Locale) join(dir, name string) string {
+	if l.fsys != nil {
+		return path.Join(dir, name)
+	}
+
+	return path.Clean(dir + string(os.PathSeparator) + name)
+}
+
+// stat returns the file info of the given locale file (name).
+func (l *Locale) stat(name string) (fs.FileInfo, error) {
+	if l.fsys != nil {
+		return fs.Stat(l.fsys, name)
+	}
+
+	return os.Stat(name)
+}
+
+// readFile returns the content of the given locale file (name).
+func (l *Locale) readFile(name string) ([]byte, error) {
+	if l.fsys != nil {
+		return fs.ReadFile(l.fsys, name)
+	}
+
+	return ioutil.ReadFile(name)
+}
+
+// glob returns the locale files matching the given pattern.
+func (l *Locale) glob(pattern string) ([]string, error) {
+	if l.fsys != nil {
+		return fs.Glob(l.fsys, pattern)
+	}
+
+	return filepath.Glob(pattern)
+}
+
+// zeroKey identifies the entries having a zero form set with SetZeroForm.
+type zeroKey struct {
+	dom, ctx, id string
+}
+
+// SetZeroForm sets the text returned by the plural lookups (GetN, GetND, GetNC and GetNDC) when the count is zero,
+// for the entry with the given msgid (id) in the given domain (dom) and context (ctx, empty for context-less entries).
+// It allows a distinct empty state message, like "No files", for languages whose plural rules don't single out zero,
+// without changing the call sites. The zero form takes precedence over the catalog and the fallback chain,
+// and it's formatted with the lookup vars like a regular translation.
+// Use an empty text to remove the zero form.
+func (l *Locale) SetZeroForm(dom, ctx, id, text string) {
+	l.Lock()
+	defer l.Unlock()
+	l.changed()
+
+	if l.zeroForms == nil {
+		l.zeroForms = make(map[zeroKey]string)
+	}
+
+	k := zeroKey{dom: dom, ctx: ctx, id: id}
+	if text == "" {
+		delete(l.zeroForms, k)
+		return
+	}
+	l.zeroForms[k] = text
+}
+
+// SetRegionDefault sets the regional language code (region) whose files are loaded for a generic language code (lang)
+// when there are no files for it, like SetRegionDefault("pt", "pt_BR") for catalogs shipped only for regional variants.
+// It's the inverse of the generic language dir fallback: a Locale for "pt" loads "pt_BR/default.po"
+// if "pt/default.po" doesn't exist. It also applies to other regions once their own and the generic files are missing,
+// so a Locale for "pt_AO" loads "pt_BR/default.po" too.
+// Domains already loaded aren't affected. Use an empty region to remove the default.
+func (l *Locale) SetRegionDefault(lang, region string) {
 	l.Lock()
 	defer l.Unlock()
 
+	if l.regionDefaults == nil {
+		l.regionDefaults = make(map[string]string)
+	}
+
+	if region == "" {
+		delete(l.regionDefaults, lang)
+		return
+	}
+	l.regionDefaults[lang] = region
+}
+
+// AttachDomain sets an already parsed Po object (po) as the given domain (dom) for this Locale.
+// It allows to load a domain once and share it across multiple Locale objects,
+// like a language-neutral domain that is identical for every language.
+// If the domain exists, it gets replaced.
+//
+// The Po object is shared, not copied: it's expected to be read-only once attached.
+// Locale settings affecting its domains (like SetCollapseWhitespace) aren't applied to attached Po objects.
+func (l *Locale) AttachDomain(dom string, po *Po) {
+	l.Lock()
+	defer l.Unlock()
+	l.changed()
+
 	if l.domains == nil {
 		l.domains = make(map[string]*Po)
 	}
+	if l.shared == nil {
+		l.shared = make(map[string]bool)
+	}
+
 	l.domains[dom] = po
+	l.shared[dom] = true
+	delete(l.files, dom)
+	delete(l.pending, dom)
+}
+
+// SetFallback sets the Locale (fb) to look at when a translation isn't found on this Locale.
+// The fallback Locale can have its own fallback, forming a chain that is consulted in order,
+// preserving the requested domain and context, before returning the untranslated string.
+// A fallback whose chain leads back to this Locale is ignored, so lookups can't loop forever.
+// Use nil to remove the fallback.
+func (l *Locale) SetFallback(fb *Locale) {
+	// Avoid loops on the chain
+	for f := fb; f != nil; f = f.GetFallback() {
+		if f == l {
+			return
+		}
+	}
+
+	l.Lock()
+	defer l.Unlock()
+	l.changed()
+
+	l.fallback = fb
+	l.ownFallback = false
+}
+
+// AddFallback adds the Locale (fb) at the end of the fallback chain of this Locale,
+// so it's consulted after every other fallback. Like SetFallback, a Locale that would make the chain loop is ignored.
+func (l *Locale) AddFallback(fb *Locale) {
+	last := l
+	for f := l.GetFallback(); f != nil; f = f.GetFallback() {
+		last = f
+	}
+
+	last.SetFallback(fb)
+}
+
+// ownChain returns this Locale followed by the fallbacks created along with it by NewLocaleWithFallback.
+func (l *Locale) ownChain() []*Locale {
+	chain := []*Locale{l}
+
+	for loc := l; ; {
+		loc.RLock()
+		fb, own := loc.fallback, loc.ownFallback
+		loc.RUnlock()
+
+		if !own || fb == nil {
+			return chain
+		}
+
+		chain = append(chain, fb)
+		loc = fb
+	}
+}
+
+// GetFallback returns the fallback Locale set for this Locale, or nil if there isn't any.
+func (l *Locale) GetFallback() *Locale {
+	l.RLock()
+	defer l.RUnlock()
+
+	return l.fallback
+}
+
+// SetDomain sets the domain (dom) used by the methods without a domain parameter, like Get and GetN,
+// for catalogs named after the application instead of "default".
+// The domain doesn't need to be loaded again. Use an empty domain to restore the "default" one.
+func (l *Locale) SetDomain(dom string) {
+	l.Lock()
+	defer l.Unlock()
+	l.changed()
+
+	l.domain = dom
+}
+
+// GetDomain returns the domain used by the methods without a domain parameter.
+func (l *Locale) GetDomain() string {
+	if dom := l.view().domain; dom != "" {
+		return dom
+	}
+
+	return "default"
+}
+
+// GetDomains returns the names of the domains loaded on this Locale, attached ones included, sorted by name.
+// Domains registered on lazy mode are included, even if they weren't parsed yet.
+// Domains of the fallback Locale aren't included.
+func (l *Locale) GetDomains() []string {
+	l.RLock()
+	defer l.RUnlock()
+
+	doms := make([]string, 0, len(l.domains)+len(l.pending))
+	for dom := range l.domains {
+		doms = append(doms, dom)
+	}
+	for dom := range l.pending {
+		doms = append(doms, dom)
+	}
+	sort.Strings(doms)
+
+	return doms
+}
+
+// GetTranslations returns a copy of the translations of the given domain (dom) keyed by msgid, as described on Po.GetTranslations,
+// or nil if the domain isn't loaded on this Locale.
+func (l *Locale) GetTranslations(dom string) map[string]string {
+	po, _ := l.lookup(dom)
+	if po == nil {
+		return nil
+	}
+
+	return po.GetTranslations()
+}
+
+// Translations returns a copy of every entry of the given domain (dom), as described on Po.Translations,
+// or nil if the domain isn't loaded on this Locale.
+func (l *Locale) Translations(dom string) []*Translation {
+	po, _ := l.lookup(dom)
+	if po == nil {
+		return nil
+	}
+
+	return po.Translations()
+}
+
+// SetVerifyArgs enables or disables the format arguments verification for this Locale.
+// When enabled, translations receiving less arguments (vars) than they require return the unformatted source string
+// instead of a string with "%!s(MISSING)" marks on it.
+// The required amount is declared on the PO file with a flag like "#, args:2", or inferred from the msgid format verbs.
+func (l *Locale) SetVerifyArgs(verify bool) {
+	l.Lock()
+	defer l.Unlock()
+	l.changed()
+
+	l.verifyArgs = verify
+}
+
+// SetAutoCount enables or disables the automatic count argument for this Locale.
+// When enabled, plural translations (GetN, GetND, GetNC and GetNDC) called without vars
+// use the count (n) as the only argument if the selected string contains any format verb,
+// so GetN("%d apple", "%d apples", 3) returns "3 apples" without passing 3 twice.
+// When disabled (default), verbs without arguments are left as formatted by fmt.Sprintf.
+func (l *Locale) SetAutoCount(auto bool) {
+	l.Lock()
+	defer l.Unlock()
+	l.changed()
+
+	l.autoCount = auto
+}
+
+// SetRawWithoutVars enables or disables the raw mode for this Locale.
+// When enabled, lookups called without vars return the translation as is instead of formatting it with fmt.Sprintf,
+// so literal "%" characters, like in "100% free", aren't turned into "%!f(MISSING)" marks,
+// and the formatting cost is skipped. Strings that need a literal "%" when formatted with vars still use "%%".
+// The automatic count argument (see SetAutoCount) is supplied before, so counted plural lookups are still formatted.
+// It's disabled by default.
+func (l *Locale) SetRawWithoutVars(raw bool) {
+	l.Lock()
+	defer l.Unlock()
+	l.changed()
+
+	l.rawWithoutVars = raw
+}
+
+// SetLenientPlural enables or disables the lenient plural mode.
+// When enabled, plural lookups (GetN, GetND, GetNC and GetNDC) asking for a plural form index missing on the entry
+// use the highest available index below it, or the lowest available one for negative indexes, and log it,
+// instead of returning the untranslated plural string.
+// It keeps some translation showing when a catalog has fewer forms than its plural rule expects.
+// It's disabled by default.
+func (l *Locale) SetLenientPlural(lenient bool) {
+	l.Lock()
+	defer l.Unlock()
+	l.changed()
+
+	l.lenientPlural = lenient
+}
+
+// SetMissingFormat sets a format (fmt.Printf syntax) used to mark the strings that aren't translated
+// on this Locale nor on its fallback chain, like "[MISSING: %s]".
+// The format receives the untranslated string, already formatted with the vars, as its only argument.
+// Use an empty format (default) to return untranslated strings unmarked.
+func (l *Locale) SetMissingFormat(format string) {
+	l.Lock()
+	defer l.Unlock()
+	l.changed()
+
+	l.missingFormat = format
+}
+
+/*
+SetMissingHandler sets a function (h) to be called for each string requested that isn't translated
+on this Locale nor on its fallback chain, right before returning the untranslated string.
+It allows to collect the strings missing on each locale, for QA or metrics:
+
+    l.SetMissingHandler(func(dom, ctx, id string, n int) {
+        log.Printf("Missing %s translation on %s: %q", lang, dom, id)
+    })
+
+The handler receives the requested domain (dom), context (ctx, empty for the lookups without context),
+msgid (id) and count (n), which is -1 for the lookups without a count, like Get.
+It's called for every Get method, and without holding the Locale lock, so it can use the Locale too.
+Use nil (default) to remove the handler.
+*/
+func (l *Locale) SetMissingHandler(h func(dom, ctx, id string, n int)) {
+	l.Lock()
+	defer l.Unlock()
+	l.changed()
+
+	l.missingHandler = h
+}
+
+// SetMissingCollector sets a MissingCollector (c) to save each string requested that isn't translated
+// on this Locale nor on its fallback chain, along with its domain, context and plural msgid,
+// so they can be written as PO templates. It works along with the missing handler, if any.
+// Use nil (default) to remove the collector.
+func (l *Locale) SetMissingCollector(c *MissingCollector) {
+	l.Lock()
+	defer l.Unlock()
+	l.changed()
+
+	l.missingCollector = c
+}
+
+// missing reports the given untranslated string (id), with its plural msgid (plural) if any,
+// to the missing handler and collector, if any.
+func (l *Locale) missing(dom, ctx, id, plural string, n int) {
+	v := l.view()
+	h, c := v.missingHandler, v.missingCollector
+
+	if h != nil {
+		h(dom, ctx, id, n)
+	}
+	if c != nil {
+		c.add(dom, ctx, id, plural)
+	}
+}
+
+// SetFuzzyEnabled enables or disables the use of the entries flagged as "fuzzy" on lookups
+// for all the domains of this Locale, including the ones added afterwards, except the attached ones.
+// Fuzzy entries are ignored by default, so lookups go through the fallback chain instead.
+// See Po.SetFuzzyEnabled for details.
+func (l *Locale) SetFuzzyEnabled(enabled bool) {
+	l.Lock()
+	defer l.Unlock()
+
+	l.fuzzy = enabled
+
+	for dom, po := range l.domains {
+		if po != nil && !l.shared[dom] {
+			po.SetFuzzyEnabled(enabled)
+		}
+	}
+}
+
+// SetStrictCharset enables or disables the strict charset mode for the files loaded afterwards on this Locale,
+// so PO files that can't be converted to UTF-8 from their declared charset aren't loaded, returning the error instead.
+// See Po.SetStrictCharset for details.
+func (l *Locale) SetStrictCharset(strict bool) {
+	l.Lock()
+	defer l.Unlock()
+
+	l.strictCharset = strict
 }
 
-// Get uses a domain "default" to return the corresponding translation of a given string.
+// SetCollapseWhitespace enables or disables the collapsing of consecutive whitespace characters
+// for all the domains of this Locale, including the ones added afterwards, except the attached ones.
+// See Po.SetCollapseWhitespace for details.
+func (l *Locale) SetCollapseWhitespace(collapse bool) {
+	l.Lock()
+	defer l.Unlock()
+
+	l.collapse = collapse
+
+	for dom, po := range l.domains {
+		if po != nil && !l.shared[dom] {
+			po.SetCollapseWhitespace(collapse)
+		}
+	}
+
+	for _, po := range l.overrides {
+		po.SetCollapseWhitespace(collapse)
+	}
+}
+
+// Get uses the default domain ("default" unless changed with SetDomain) to return the corresponding translation of a given string.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func (l *Locale) Get(str string, vars ...interface{}) string {
-	return l.GetD("default", str, vars...)
+	return l.GetD(l.GetDomain(), str, vars...)
 }
 
-// GetN retrieves the (N)th plural form translation for the given string in the "default" domain.
-// If n == 0, usually the singular form of the string is returned as defined in the PO file.
+// GetN retrieves the plural form translation for the given string and count (n) in the default domain.
+// The form is chosen by the Plural-Forms header of the domain catalog, like on Po.GetN.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func (l *Locale) GetN(str, plural string, n int, vars ...interface{}) string {
-	return l.GetND("default", str, plural, n, vars...)
+	return l.GetND(l.GetDomain(), str, plural, n, vars...)
 }
 
 // GetD returns the corresponding translation in the given domain for the given string.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func (l *Locale) GetD(dom, str string, vars ...interface{}) string {
-	return l.GetND(dom, str, str, 0, vars...)
+	t, _ := l.findD(dom, str)
+	if t == nil {
+		l.missing(dom, "", str, "", -1)
+	}
+
+	return l.format(t, 0, str, 0, false, vars)
 }
 
-// GetND retrieves the (N)th plural form translation in the given domain for the given string.
-// If n == 0, usually the singular form of the string is returned as defined in the PO file.
+// GetND retrieves the plural form translation in the given domain for the given string and count (n).
+// The form is chosen by the Plural-Forms header of the domain catalog, like on Po.GetN.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func (l *Locale) GetND(dom, str, plural string, n int, vars ...interface{}) string {
-	// Sync read
-	l.RLock()
-	defer l.RUnlock()
+	return l.GetNDv(dom, str, plural, n, vars)
+}
 
-	if l.domains != nil {
-		if _, ok := l.domains[dom]; ok {
-			if l.domains[dom] != nil {
-				return l.domains[dom].GetN(str, plural, n, vars...)
-			}
+// GetNDv works like GetND, receiving the parameters to be inserted on the formatted string as a slice (vars),
+// for callers building them dynamically.
+func (l *Locale) GetNDv(dom, str, plural string, n int, vars []interface{}) string {
+	if t := l.zeroForm(dom, str, "", n); t != nil {
+		return l.format(t, 0, plural, n, true, vars)
+	}
+
+	t, po := l.findD(dom, str)
+	if t == nil {
+		l.missing(dom, "", str, plural, n)
+
+		return l.format(nil, 0, plural, n, true, vars)
+	}
+
+	return l.format(t, po.pluralForm(n), plural, n, true, vars)
+}
+
+// findD returns the translation object in the given domain for the given string and the catalog holding it,
+// going through the fallback chain when it isn't found on this Locale.
+func (l *Locale) findD(dom, str string) (*Translation, *Po) {
+	po, fb := l.lookup(dom)
+
+	if t, o := l.override(dom, str, "", false, po); t != nil {
+		return t, o
+	}
+
+	if po != nil {
+		if t := po.translation(po.find(str)); t != nil {
+			return t, po
 		}
 	}
 
-	// Return the same we received by default
-	return fmt.Sprintf(plural, vars...)
+	if fb != nil {
+		return fb.findD(dom, str)
+	}
+
+	return nil, nil
 }
 
-// GetC uses a domain "default" to return the corresponding translation of the given string in the given context.
+// GetC uses the default domain to return the corresponding translation of the given string in the given context.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func (l *Locale) GetC(str, ctx string, vars ...interface{}) string {
-	return l.GetDC("default", str, ctx, vars...)
+	return l.GetDC(l.GetDomain(), str, ctx, vars...)
 }
 
-// GetNC retrieves the (N)th plural form translation for the given string in the given context in the "default" domain.
-// If n == 0, usually the singular form of the string is returned as defined in the PO file.
+// GetNC retrieves the plural form translation for the given string and count (n) in the given context in the default domain.
+// The form is chosen by the Plural-Forms header of the domain catalog, like on Po.GetN.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func (l *Locale) GetNC(str, plural string, n int, ctx string, vars ...interface{}) string {
-	return l.GetNDC("default", str, plural, n, ctx, vars...)
+	return l.GetNDC(l.GetDomain(), str, plural, n, ctx, vars...)
 }
 
 // GetDC returns the corresponding translation in the given domain for the given string in the given context.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func (l *Locale) GetDC(dom, str, ctx string, vars ...interface{}) string {
-	return l.GetNDC(dom, str, str, 0, ctx, vars...)
+	t, _ := l.findDC(dom, str, ctx)
+	if t == nil {
+		l.missing(dom, ctx, str, "", -1)
+	}
+
+	return l.format(t, 0, str, 0, false, vars)
 }
 
-// GetNDC retrieves the (N)th plural form translation in the given domain for the given string in the given context.
-// If n == 0, usually the singular form of the string is returned as defined in the PO file.
+// GetNDC retrieves the plural form translation in the given domain for the given string and count (n) in the given context.
+// The form is chosen by the Plural-Forms header of the domain catalog, like on Po.GetN.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func (l *Locale) GetNDC(dom, str, plural string, n int, ctx string, vars ...interface{}) string {
-	// Sync read
-	l.RLock()
-	defer l.RUnlock()
+	return l.GetNDCv(dom, str, plural, n, ctx, vars)
+}
 
-	if l.domains != nil {
-		if _, ok := l.domains[dom]; ok {
-			if l.domains[dom] != nil {
-				return l.domains[dom].GetNC(str, plural, n, ctx, vars...)
-			}
+// GetNDCv works like GetNDC, receiving the parameters to be inserted on the formatted string as a slice (vars),
+// for callers building them dynamically.
+func (l *Locale) GetNDCv(dom, str, plural string, n int, ctx string, vars []interface{}) string {
+	if t := l.zeroForm(dom, str, ctx, n); t != nil {
+		return l.format(t, 0, plural, n, true, vars)
+	}
+
+	t, po := l.findDC(dom, str, ctx)
+	if t == nil {
+		l.missing(dom, ctx, str, plural, n)
+
+		return l.format(nil, 0, plural, n, true, vars)
+	}
+
+	return l.format(t, po.pluralForm(n), plural, n, true, vars)
+}
+
+// findDC returns the translation object in the given domain for the given string in the given context
+// and the catalog holding it, going through the fallback chain when it isn't found on this Locale.
+func (l *Locale) findDC(dom, str, ctx string) (*Translation, *Po) {
+	po, fb := l.lookup(dom)
+
+	if t, o := l.override(dom, str, ctx, true, po); t != nil {
+		return t, o
+	}
+
+	if po != nil {
+		if t := po.translation(po.findC(str, ctx)); t != nil {
+			return t, po
 		}
 	}
 
+	if fb != nil {
+		return fb.findDC(dom, str, ctx)
+	}
+
+	return nil, nil
+}
+
+// zeroForm returns a translation object holding the zero form set for the given domain, string and context,
+// or nil if the count (n) isn't zero or there is no zero form for them.
+func (l *Locale) zeroForm(dom, str, ctx string, n int) *Translation {
+	if n != 0 {
+		return nil
+	}
+
+	text, ok := l.view().zeroForms[zeroKey{dom: dom, ctx: ctx, id: str}]
+	if !ok {
+		return nil
+	}
+
+	t := NewTranslation()
+	t.Context = ctx
+	t.ID = text
+	t.Trs[0] = text
+
+	return t
+}
+
+// format returns the given plural form (form) of the translation object (t) formatted with the given vars,
+// or the plural string formatted the same way when there is no translation.
+// The count (n) is supplied as the only argument when counted is true and the automatic count is enabled.
+func (l *Locale) format(t *Translation, form int, plural string, n int, counted bool, vars []interface{}) string {
+	v := l.view()
+	verify := v.verifyArgs
+	auto := v.autoCount && counted
+	raw := v.rawWithoutVars
+	missing := v.missingFormat
+
+	str := l.text(t, form, plural, counted)
+
+	// Supply count argument
+	if auto && len(vars) == 0 && countVerbs(str) > 0 {
+		vars = []interface{}{n}
+	}
+
+	// Verify arguments
+	if t != nil && verify && len(vars) < t.requiredArgs() {
+		return plural
+	}
+
+	// Keep the string as is without vars on the raw mode
+	if !raw || len(vars) > 0 {
+		str = fmt.Sprintf(str, vars...)
+	}
+
+	// Mark untranslated strings
+	if t == nil && missing != "" {
+		return fmt.Sprintf(missing, str)
+	}
+
+	return str
+}
+
+// text returns the given plural form (form) of the translation object (t), or the plural string when there is no translation.
+// On the lenient plural mode, an available form is used for plural lookups (counted) when the form is missing.
+func (l *Locale) text(t *Translation, form int, plural string, counted bool) string {
 	// Return the same we received by default
-	return fmt.Sprintf(plural, vars...)
+	if t == nil {
+		return plural
+	}
+
+	lenient := l.view().lenientPlural
+	str := t.getN(form)
+
+	// Use an available form when the index is missing
+	if lenient && counted && t.PluralID != "" {
+		if i, ok := t.clampIndex(form); ok && i != form {
+			log.Printf("gotext: plural form %d missing for %s, using form %d", form, entryKey{ctx: t.Context, id: t.ID}, i)
+			str = t.Trs[i]
+		}
+	}
+
+	return str
+}
+
+// lookup returns the Po object for the given domain (if loaded) and the fallback Locale.
+// It reads the published lookup state, so the fallback chain is never queried while holding the lock.
+// Domains registered on lazy mode are parsed here, on their first lookup.
+func (l *Locale) lookup(dom string) (*Po, *Locale) {
+	v := l.view()
+	for d := v.pending[dom]; d != nil; d = v.pending[dom] {
+		l.loadPending(dom, d)
+		v = l.view()
+	}
+
+	return v.domains[dom], v.fallback
 }
@@ -1,9 +1,19 @@
 package gotext
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"log"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 func TestLocale(t *testing.T) {
@@ -92,8 +102,8 @@ msgstr "More translation"
 
 	// Test plural
 	tr = l.GetND("my_domain", "One with var: %s", "Several with vars: %s", 2, v)
-	if tr != "And this is the second plural form: Variable" {
-		t.Errorf("Expected 'And this is the second plural form: Variable' but got '%s'", tr)
+	if tr != "This one is the plural: Variable" {
+		t.Errorf("Expected 'This one is the plural: Variable' but got '%s'", tr)
 	}
 
 	// Test non-existent "deafult" domain responses
@@ -137,7 +147,7 @@ msgstr "More translation"
 	}
 
 	// Test plural
-	tr = l.GetNDC("my_domain", "One with var: %s", "Several with vars: %s", 1, "Ctx", v)
+	tr = l.GetNDC("my_domain", "One with var: %s", "Several with vars: %s", 2, "Ctx", v)
 	if tr != "This one is the plural in a Ctx context: Test" {
 		t.Errorf("Expected 'This one is the plural in a Ctx context: Test' but got '%s'", tr)
 	}
@@ -214,3 +224,1428 @@ msgstr[2] "And this is the second plural form: %s"
 	<-ac
 	<-rc
 }
+
+func TestLocaleFallback(t *testing.T) {
+	// Set PO content for the regional language
+	regional := `
+msgid "My text"
+msgstr "Regional text"
+
+msgctxt "Ctx"
+msgid "Some random in a context"
+msgstr "Regional translation in a context"
+    `
+
+	// Set PO content for the base language
+	base := `
+msgid "My text"
+msgstr "Base text"
+
+msgid "Only in base"
+msgstr "Only in base translation"
+
+msgctxt "Ctx"
+msgid "Some random in a context"
+msgstr "Base translation in a context"
+
+msgctxt "Ctx"
+msgid "Only in base in a context"
+msgstr "Only in base translation in a context"
+
+msgctxt "Ctx"
+msgid "One with var: %s"
+msgid_plural "Several with vars: %s"
+msgstr[0] "This one is the singular in a Ctx context: %s"
+msgstr[1] "This one is the plural in a Ctx context: %s"
+    `
+
+	// Create Locales directories and write PO content to files
+	for lang, str := range map[string]string{"pt_BR": regional, "pt": base} {
+		dirname := path.Clean("/tmp" + string(os.PathSeparator) + lang)
+		err := os.MkdirAll(dirname, os.ModePerm)
+		if err != nil {
+			t.Fatalf("Can't create test directory: %s", err.Error())
+		}
+
+		filename := path.Clean(dirname + string(os.PathSeparator) + "fallback.po")
+
+		f, err := os.Create(filename)
+		if err != nil {
+			t.Fatalf("Can't create test file: %s", err.Error())
+		}
+		defer f.Close()
+
+		_, err = f.WriteString(str)
+		if err != nil {
+			t.Fatalf("Can't write to test file: %s", err.Error())
+		}
+	}
+
+	// Create Locales and chain them
+	fb := NewLocale("/tmp", "pt")
+	fb.AddDomain("fallback")
+
+	l := NewLocale("/tmp", "pt_BR")
+	l.AddDomain("fallback")
+	l.SetFallback(fb)
+
+	if l.GetFallback() != fb {
+		t.Error("Expected GetFallback to return the fallback Locale")
+	}
+
+	// Test translations found in the primary Locale
+	tr := l.GetD("fallback", "My text")
+	if tr != "Regional text" {
+		t.Errorf("Expected 'Regional text' but got '%s'", tr)
+	}
+
+	tr = l.GetDC("fallback", "Some random in a context", "Ctx")
+	if tr != "Regional translation in a context" {
+		t.Errorf("Expected 'Regional translation in a context' but got '%s'", tr)
+	}
+
+	// Test translations found in the fallback Locale
+	tr = l.GetD("fallback", "Only in base")
+	if tr != "Only in base translation" {
+		t.Errorf("Expected 'Only in base translation' but got '%s'", tr)
+	}
+
+	tr = l.GetDC("fallback", "Only in base in a context", "Ctx")
+	if tr != "Only in base translation in a context" {
+		t.Errorf("Expected 'Only in base translation in a context' but got '%s'", tr)
+	}
+
+	v := "Test"
+	tr = l.GetNDC("fallback", "One with var: %s", "Several with vars: %s", 2, "Ctx", v)
+	if tr != "This one is the plural in a Ctx context: Test" {
+		t.Errorf("Expected 'This one is the plural in a Ctx context: Test' but got '%s'", tr)
+	}
+
+	// Test context is preserved through the fallback
+	tr = l.GetD("fallback", "Only in base in a context")
+	if tr != "Only in base in a context" {
+		t.Errorf("Expected 'Only in base in a context' but got '%s'", tr)
+	}
+
+	tr = l.GetDC("fallback", "Only in base", "Ctx")
+	if tr != "Only in base" {
+		t.Errorf("Expected 'Only in base' but got '%s'", tr)
+	}
+
+	// Test inexistent translations
+	tr = l.GetNDC("fallback", "This is a test", "This are tests", 1, "Ctx")
+	if tr != "This are tests" {
+		t.Errorf("Expected 'This are tests' but got '%s'", tr)
+	}
+
+	// Loops are ignored
+	fb.SetFallback(l)
+	l.SetFallback(l)
+
+	if f := fb.GetFallback(); f != nil {
+		t.Errorf("Expected no fallback on the fallback Locale but got %v", f)
+	}
+	if f := l.GetFallback(); f != fb {
+		t.Errorf("Expected fallback to be kept but got %v", f)
+	}
+
+	tr = l.GetD("fallback", "Missing everywhere")
+	if tr != "Missing everywhere" {
+		t.Errorf("Expected 'Missing everywhere' but got '%s'", tr)
+	}
+
+	// Remove fallback
+	l.SetFallback(nil)
+
+	tr = l.GetDC("fallback", "Only in base in a context", "Ctx")
+	if tr != "Only in base in a context" {
+		t.Errorf("Expected 'Only in base in a context' but got '%s'", tr)
+	}
+}
+
+func TestNewLocaleWithFallback(t *testing.T) {
+	// Create Locales directories and write PO content to files
+	for lang, str := range map[string]string{
+		"pt_BR": "msgid \"My text\"\nmsgstr \"Regional text\"\n",
+		"pt":    "msgid \"My text\"\nmsgstr \"Base text\"\n\nmsgid \"Only in base\"\nmsgstr \"Base only text\"\n",
+		"en":    "msgid \"Only in base\"\nmsgstr \"English text\"\n\nmsgid \"Only in English\"\nmsgstr \"English only text\"\n",
+	} {
+		dirname := path.Clean("/tmp" + string(os.PathSeparator) + lang)
+		err := os.MkdirAll(dirname, os.ModePerm)
+		if err != nil {
+			t.Fatalf("Can't create test directory: %s", err.Error())
+		}
+
+		err = ioutil.WriteFile(path.Clean(dirname+string(os.PathSeparator)+"chain.po"), []byte(str), 0644)
+		if err != nil {
+			t.Fatalf("Can't write to test file: %s", err.Error())
+		}
+	}
+
+	l := NewLocaleWithFallback("/tmp", "pt_BR", "pt", "en")
+	l.AddDomain("chain")
+
+	for str, expected := range map[string]string{
+		"My text":         "Regional text",
+		"Only in base":    "Base only text",
+		"Only in English": "English only text",
+		"Missing":         "Missing",
+	} {
+		if tr := l.GetD("chain", str); tr != expected {
+			t.Errorf("Expected '%s' but got '%s'", expected, tr)
+		}
+	}
+
+	// Add a fallback at the end of the chain
+	last := NewLocale("/tmp", "xx")
+	po := new(Po)
+	po.Parse("msgid \"Missing\"\nmsgstr \"Last resort\"\n")
+	last.AttachDomain("chain", po)
+
+	l.AddFallback(last)
+	l.AddFallback(l)
+
+	if tr := l.GetD("chain", "Missing"); tr != "Last resort" {
+		t.Errorf("Expected 'Last resort' but got '%s'", tr)
+	}
+
+	if fb := l.GetFallback().GetFallback().GetFallback(); fb != last {
+		t.Error("Expected the added fallback at the end of the chain")
+	}
+}
+
+func TestLocaleVerifyArgs(t *testing.T) {
+	// Set PO content
+	str := `
+msgid "Hello %s"
+msgstr "Hola %s"
+
+#, c-format, args:2
+msgid "Declared %s"
+msgstr "Declarado %s de %s"
+
+msgid "One with var: %s"
+msgid_plural "Several with vars: %s"
+msgstr[0] "This one is the singular: %s"
+msgstr[1] "This one is the plural: %s"
+
+msgctxt "Ctx"
+msgid "Hello %s"
+msgstr "Hola %s en un contexto"
+    `
+
+	// Create Locales directory
+	dirname := path.Clean("/tmp" + string(os.PathSeparator) + "es")
+	err := os.MkdirAll(dirname, os.ModePerm)
+	if err != nil {
+		t.Fatalf("Can't create test directory: %s", err.Error())
+	}
+
+	// Write PO content to file
+	filename := path.Clean(dirname + string(os.PathSeparator) + "args.po")
+
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatalf("Can't create test file: %s", err.Error())
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(str)
+	if err != nil {
+		t.Fatalf("Can't write to test file: %s", err.Error())
+	}
+
+	l := NewLocale("/tmp", "es")
+	l.AddDomain("args")
+
+	// Test default behaviour
+	tr := l.GetD("args", "Hello %s")
+	if tr != "Hola %!s(MISSING)" {
+		t.Errorf("Expected 'Hola %%!s(MISSING)' but got '%s'", tr)
+	}
+
+	// Enable verification
+	l.SetVerifyArgs(true)
+
+	tr = l.GetD("args", "Hello %s")
+	if tr != "Hello %s" {
+		t.Errorf("Expected 'Hello %%s' but got '%s'", tr)
+	}
+
+	tr = l.GetD("args", "Hello %s", "Juan")
+	if tr != "Hola Juan" {
+		t.Errorf("Expected 'Hola Juan' but got '%s'", tr)
+	}
+
+	// Test declared arguments
+	tr = l.GetD("args", "Declared %s", "uno")
+	if tr != "Declared %s" {
+		t.Errorf("Expected 'Declared %%s' but got '%s'", tr)
+	}
+
+	tr = l.GetD("args", "Declared %s", "uno", "dos")
+	if tr != "Declarado uno de dos" {
+		t.Errorf("Expected 'Declarado uno de dos' but got '%s'", tr)
+	}
+
+	// Test plural
+	tr = l.GetND("args", "One with var: %s", "Several with vars: %s", 1)
+	if tr != "Several with vars: %s" {
+		t.Errorf("Expected 'Several with vars: %%s' but got '%s'", tr)
+	}
+
+	// Test context
+	tr = l.GetDC("args", "Hello %s", "Ctx")
+	if tr != "Hello %s" {
+		t.Errorf("Expected 'Hello %%s' but got '%s'", tr)
+	}
+
+	tr = l.GetDC("args", "Hello %s", "Ctx", "Juan")
+	if tr != "Hola Juan en un contexto" {
+		t.Errorf("Expected 'Hola Juan en un contexto' but got '%s'", tr)
+	}
+}
+
+func TestNewLocaleExecRelative(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Skipf("Can't get executable path: %s", err.Error())
+	}
+
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	l, err := NewLocaleExecRelative("locales", "en_US")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	expected := filepath.Join(filepath.Dir(exe), "locales")
+	if l.path != expected {
+		t.Errorf("Expected path '%s' but got '%s'", expected, l.path)
+	}
+
+	if l.lang != "en_US" {
+		t.Errorf("Expected language 'en_US' but got '%s'", l.lang)
+	}
+}
+
+func TestLocaleIdenticalPlural(t *testing.T) {
+	// Set PO content
+	regional := `
+msgid "fish"
+msgid_plural "fish"
+msgstr[0] "pez"
+msgstr[1] "peces"
+    `
+
+	base := `
+msgid "sheep"
+msgid_plural "sheep"
+msgstr[0] "oveja"
+msgstr[1] "ovejas"
+    `
+
+	// Create Locales directories and write PO content to files
+	for lang, str := range map[string]string{"es_AR": regional, "es": base} {
+		dirname := path.Clean("/tmp" + string(os.PathSeparator) + lang)
+		err := os.MkdirAll(dirname, os.ModePerm)
+		if err != nil {
+			t.Fatalf("Can't create test directory: %s", err.Error())
+		}
+
+		filename := path.Clean(dirname + string(os.PathSeparator) + "identical.po")
+
+		f, err := os.Create(filename)
+		if err != nil {
+			t.Fatalf("Can't create test file: %s", err.Error())
+		}
+		defer f.Close()
+
+		_, err = f.WriteString(str)
+		if err != nil {
+			t.Fatalf("Can't write to test file: %s", err.Error())
+		}
+	}
+
+	fb := NewLocale("/tmp", "es")
+	fb.AddDomain("identical")
+
+	l := NewLocale("/tmp", "es_AR")
+	l.AddDomain("identical")
+	l.SetFallback(fb)
+
+	// Test loaded translations
+	tr := l.GetND("identical", "fish", "fish", 1)
+	if tr != "pez" {
+		t.Errorf("Expected 'pez' but got '%s'", tr)
+	}
+
+	tr = l.GetND("identical", "fish", "fish", 2)
+	if tr != "peces" {
+		t.Errorf("Expected 'peces' but got '%s'", tr)
+	}
+
+	// Test translations from the fallback Locale
+	tr = l.GetND("identical", "sheep", "sheep", 1)
+	if tr != "oveja" {
+		t.Errorf("Expected 'oveja' but got '%s'", tr)
+	}
+
+	tr = l.GetND("identical", "sheep", "sheep", 2)
+	if tr != "ovejas" {
+		t.Errorf("Expected 'ovejas' but got '%s'", tr)
+	}
+
+	// Test untranslated strings
+	tr = l.GetND("identical", "deer", "deer", 1)
+	if tr != "deer" {
+		t.Errorf("Expected 'deer' but got '%s'", tr)
+	}
+
+	tr = l.GetND("identical", "deer", "deer", 0)
+	if tr != "deer" {
+		t.Errorf("Expected 'deer' but got '%s'", tr)
+	}
+}
+
+func TestLocaleAutoCount(t *testing.T) {
+	// Set PO content
+	str := `
+msgid "%d apple"
+msgid_plural "%d apples"
+msgstr[0] "%d manzana"
+msgstr[1] "%d manzanas"
+
+msgid "No count"
+msgid_plural "No counts"
+msgstr[0] "Sin cuenta"
+msgstr[1] "Sin cuentas"
+
+msgid "%d item"
+msgstr "%d elemento"
+
+msgctxt "Ctx"
+msgid "%d apple"
+msgid_plural "%d apples"
+msgstr[0] "%d manzana en un contexto"
+msgstr[1] "%d manzanas en un contexto"
+    `
+
+	// Create Locales directory
+	dirname := path.Clean("/tmp" + string(os.PathSeparator) + "es")
+	err := os.MkdirAll(dirname, os.ModePerm)
+	if err != nil {
+		t.Fatalf("Can't create test directory: %s", err.Error())
+	}
+
+	// Write PO content to file
+	filename := path.Clean(dirname + string(os.PathSeparator) + "count.po")
+
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatalf("Can't create test file: %s", err.Error())
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(str)
+	if err != nil {
+		t.Fatalf("Can't write to test file: %s", err.Error())
+	}
+
+	l := NewLocale("/tmp", "es")
+	l.AddDomain("count")
+
+	// Test default behaviour
+	tr := l.GetND("count", "%d apple", "%d apples", 1)
+	if tr != "%!d(MISSING) manzana" {
+		t.Errorf("Expected '%%!d(MISSING) manzana' but got '%s'", tr)
+	}
+
+	// Enable automatic count
+	l.SetAutoCount(true)
+
+	tr = l.GetND("count", "%d apple", "%d apples", 1)
+	if tr != "1 manzana" {
+		t.Errorf("Expected '1 manzana' but got '%s'", tr)
+	}
+
+	tr = l.GetND("count", "%d apple", "%d apples", 2, 5)
+	if tr != "5 manzanas" {
+		t.Errorf("Expected '5 manzanas' but got '%s'", tr)
+	}
+
+	tr = l.GetND("count", "No count", "No counts", 2)
+	if tr != "Sin cuentas" {
+		t.Errorf("Expected 'Sin cuentas' but got '%s'", tr)
+	}
+
+	tr = l.GetNDC("count", "%d apple", "%d apples", 0, "Ctx")
+	if tr != "0 manzanas en un contexto" {
+		t.Errorf("Expected '0 manzanas en un contexto' but got '%s'", tr)
+	}
+
+	// Test untranslated strings
+	tr = l.GetND("count", "%d pear", "%d pears", 3)
+	if tr != "3 pears" {
+		t.Errorf("Expected '3 pears' but got '%s'", tr)
+	}
+
+	// Test singular getters aren't affected
+	tr = l.GetD("count", "%d item")
+	if tr != "%!d(MISSING) elemento" {
+		t.Errorf("Expected '%%!d(MISSING) elemento' but got '%s'", tr)
+	}
+}
+
+func TestLocaleRawWithoutVars(t *testing.T) {
+	// Set PO content
+	str := `
+msgid "100% free"
+msgstr "100% gratis"
+
+msgid "Hello %s"
+msgstr "Hola %s"
+
+msgid "%d apple"
+msgid_plural "%d apples"
+msgstr[0] "%d manzana"
+msgstr[1] "%d manzanas"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	l := NewLocale("/tmp", "es")
+	l.AttachDomain("default", po)
+
+	// Test default behaviour
+	if tr := l.Get("100% free"); tr != "100%!g(MISSING)ratis" {
+		t.Errorf("Expected '100%%!g(MISSING)ratis' but got '%s'", tr)
+	}
+
+	l.SetRawWithoutVars(true)
+
+	tests := []struct{ tr, expected string }{
+		{l.Get("100% free"), "100% gratis"},
+		{l.Get("Hello %s"), "Hola %s"},
+		{l.Get("Hello %s", "Ana"), "Hola Ana"},
+		{l.Get("50% off"), "50% off"},
+		{l.GetN("%d apple", "%d apples", 2), "%d manzanas"},
+		{l.GetN("%d apple", "%d apples", 2, 2), "2 manzanas"},
+		{l.GetNamed("100% free", nil), "100% gratis"},
+	}
+	for _, test := range tests {
+		if test.tr != test.expected {
+			t.Errorf("Expected '%s' but got '%s'", test.expected, test.tr)
+		}
+	}
+
+	// Automatic count is supplied first
+	l.SetAutoCount(true)
+	if tr := l.GetN("%d apple", "%d apples", 3); tr != "3 manzanas" {
+		t.Errorf("Expected '3 manzanas' but got '%s'", tr)
+	}
+
+	// Untranslated strings are still marked
+	l.SetMissingFormat("[%s]")
+	if tr := l.Get("50% off"); tr != "[50% off]" {
+		t.Errorf("Expected '[50%% off]' but got '%s'", tr)
+	}
+}
+
+func TestLocaleFuzzy(t *testing.T) {
+	es := NewLocale("/tmp", "es")
+	po := new(Po)
+	po.Parse("#, fuzzy\nmsgid \"My text\"\nmsgstr \"Mi texto\"\n")
+	es.AttachDomain("default", po)
+
+	// Fuzzy entries go through the fallback chain
+	en := NewLocale("/tmp", "en")
+	fb := new(Po)
+	fb.Parse("msgid \"My text\"\nmsgstr \"My reviewed text\"\n")
+	en.AttachDomain("default", fb)
+	es.SetFallback(en)
+
+	if tr := es.Get("My text"); tr != "My reviewed text" {
+		t.Errorf("Expected 'My reviewed text' but got '%s'", tr)
+	}
+
+	// Attached domains keep their own setting
+	es.SetFuzzyEnabled(true)
+	if tr := es.Get("My text"); tr != "My reviewed text" {
+		t.Errorf("Expected 'My reviewed text' but got '%s'", tr)
+	}
+
+	// Domains added afterwards use the locale setting
+	es.setDomain("default", "", es.newPo())
+	es.domains["default"].Parse("#, fuzzy\nmsgid \"My text\"\nmsgstr \"Mi texto\"\n")
+	if tr := es.Get("My text"); tr != "Mi texto" {
+		t.Errorf("Expected 'Mi texto' but got '%s'", tr)
+	}
+
+	es.SetFuzzyEnabled(false)
+	if tr := es.Get("My text"); tr != "My reviewed text" {
+		t.Errorf("Expected 'My reviewed text' but got '%s'", tr)
+	}
+}
+
+func TestLocaleAttachDomain(t *testing.T) {
+	// Set PO content
+	str := `
+msgid "km"
+msgstr "kilometers"
+
+msgid "Hello  world"
+msgstr "Hello world translation"
+    `
+
+	// Create shared Po object
+	po := new(Po)
+	po.Parse(str)
+
+	en := NewLocale("/tmp", "en_US")
+	en.AttachDomain("units", po)
+
+	es := NewLocale("/tmp", "es")
+	es.domains = nil
+	es.AttachDomain("units", po)
+
+	// Test translations from both locales
+	tr := en.GetD("units", "km")
+	if tr != "kilometers" {
+		t.Errorf("Expected 'kilometers' but got '%s'", tr)
+	}
+
+	tr = es.GetD("units", "km")
+	if tr != "kilometers" {
+		t.Errorf("Expected 'kilometers' but got '%s'", tr)
+	}
+
+	if en.domains["units"] != es.domains["units"] {
+		t.Error("Expected the Po object to be shared between locales")
+	}
+
+	// Test locale settings aren't applied to the shared object
+	es.SetCollapseWhitespace(true)
+
+	tr = en.GetD("units", "Hello  world")
+	if tr != "Hello world translation" {
+		t.Errorf("Expected 'Hello world translation' but got '%s'", tr)
+	}
+
+	// Test replacing the attached domain
+	es.AddDomain("units")
+
+	if es.shared["units"] {
+		t.Error("Expected the domain to be no longer shared after AddDomain")
+	}
+
+	tr = es.GetD("units", "km")
+	if tr != "km" {
+		t.Errorf("Expected 'km' but got '%s'", tr)
+	}
+}
+
+func TestLocaleMissingFormat(t *testing.T) {
+	// Set PO content
+	str := `
+msgid "My text"
+msgstr "Translated text"
+
+msgctxt "Ctx"
+msgid "My text"
+msgstr "Translated text in a context"
+    `
+
+	po := new(Po)
+	po.Parse(str)
+
+	l := NewLocale("/tmp", "en_US")
+	l.AttachDomain("missing", po)
+
+	// Test default behaviour
+	tr := l.GetD("missing", "Untranslated %s", "text")
+	if tr != "Untranslated text" {
+		t.Errorf("Expected 'Untranslated text' but got '%s'", tr)
+	}
+
+	l.SetMissingFormat("[MISSING: %s]")
+
+	tr = l.GetD("missing", "My text")
+	if tr != "Translated text" {
+		t.Errorf("Expected 'Translated text' but got '%s'", tr)
+	}
+
+	tr = l.GetD("missing", "Untranslated %s", "text")
+	if tr != "[MISSING: Untranslated text]" {
+		t.Errorf("Expected '[MISSING: Untranslated text]' but got '%s'", tr)
+	}
+
+	tr = l.GetND("missing", "One file", "%d files", 2, 2)
+	if tr != "[MISSING: 2 files]" {
+		t.Errorf("Expected '[MISSING: 2 files]' but got '%s'", tr)
+	}
+
+	tr = l.GetDC("missing", "My text", "Other")
+	if tr != "[MISSING: My text]" {
+		t.Errorf("Expected '[MISSING: My text]' but got '%s'", tr)
+	}
+
+	tr = l.Get("My text")
+	if tr != "[MISSING: My text]" {
+		t.Errorf("Expected '[MISSING: My text]' but got '%s'", tr)
+	}
+
+	// Test translations from the fallback chain aren't marked
+	fb := NewLocale("/tmp", "en")
+	fb.AttachDomain("default", po)
+	l.SetFallback(fb)
+
+	tr = l.Get("My text")
+	if tr != "Translated text" {
+		t.Errorf("Expected 'Translated text' but got '%s'", tr)
+	}
+
+	// Disable marks
+	l.SetMissingFormat("")
+
+	tr = l.GetD("missing", "Untranslated")
+	if tr != "Untranslated" {
+		t.Errorf("Expected 'Untranslated' but got '%s'", tr)
+	}
+}
+
+func TestLocaleAddDomainsGlob(t *testing.T) {
+	// Set PO files content
+	files := map[string]string{
+		"xx/feature-a.po":              "msgid \"My text\"\nmsgstr \"Feature A text\"\n",
+		"xx/feature-b.po":              "msgid \"My text\"\nmsgstr \"Feature B text\"\n",
+		"xx/notes.txt":                 "msgid \"My text\"\nmsgstr \"Not a domain\"\n",
+		"xx/LC_MESSAGES/feature-a.po":  "msgid \"My text\"\nmsgstr \"Duplicated text\"\n",
+		"xx/LC_MESSAGES/messages.po":   "msgid \"My text\"\nmsgstr \"Messages text\"\n",
+		"xx/feature-dir.po/ignored.po": "",
+		"xx/extra/messages.po":         "msgid \"My text\"\nmsgstr \"Extra text\"\n",
+	}
+
+	// Create Locale directories and write PO content to files
+	for name, str := range files {
+		filename := path.Clean("/tmp" + string(os.PathSeparator) + name)
+
+		err := os.MkdirAll(path.Dir(filename), os.ModePerm)
+		if err != nil {
+			t.Fatalf("Can't create test directory: %s", err.Error())
+		}
+
+		f, err := os.Create(filename)
+		if err != nil {
+			t.Fatalf("Can't create test file: %s", err.Error())
+		}
+		defer f.Close()
+
+		_, err = f.WriteString(str)
+		if err != nil {
+			t.Fatalf("Can't write to test file: %s", err.Error())
+		}
+	}
+
+	// Create Locale using the generic language dir
+	l := NewLocale("/tmp", "xx_YY")
+
+	if errs := l.AddDomainsGlob("*.po"); len(errs) != 0 {
+		t.Errorf("Unexpected errors: %v", errs)
+	}
+
+	tr := l.GetD("feature-a", "My text")
+	if tr != "Feature A text" {
+		t.Errorf("Expected 'Feature A text' but got '%s'", tr)
+	}
+
+	tr = l.GetD("feature-b", "My text")
+	if tr != "Feature B text" {
+		t.Errorf("Expected 'Feature B text' but got '%s'", tr)
+	}
+
+	for _, dom := range []string{"notes", "messages", "feature-dir", "ignored"} {
+		if _, ok := l.domains[dom]; ok {
+			t.Errorf("Unexpected domain '%s'", dom)
+		}
+	}
+
+	// Load the GNU gettext layout
+	errs := l.AddDomainsGlob("LC_MESSAGES/*.po")
+	if len(errs) != 0 {
+		t.Errorf("Unexpected errors: %v", errs)
+	}
+
+	tr = l.GetD("messages", "My text")
+	if tr != "Messages text" {
+		t.Errorf("Expected 'Messages text' but got '%s'", tr)
+	}
+
+	tr = l.GetD("feature-a", "My text")
+	if tr != "Duplicated text" {
+		t.Errorf("Expected 'Duplicated text' but got '%s'", tr)
+	}
+
+	// Report duplicated domains
+	errs = l.AddDomainsGlob("*/messages.po")
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 error but got %v", errs)
+	}
+	if !strings.Contains(errs[0].Error(), "extra/messages.po") {
+		t.Errorf("Expected error for 'extra/messages.po' but got '%s'", errs[0].Error())
+	}
+
+	tr = l.GetD("messages", "My text")
+	if tr != "Messages text" {
+		t.Errorf("Expected 'Messages text' but got '%s'", tr)
+	}
+
+	// Bad pattern
+	if errs := l.AddDomainsGlob("["); len(errs) != 1 {
+		t.Errorf("Expected 1 error for bad pattern but got %v", errs)
+	}
+}
+
+func TestLocaleRegionDefault(t *testing.T) {
+	// Create regional Locale directories and write PO content to files
+	for lang, str := range map[string]string{"zz_AA": "Region A text", "zz_BB": "Region B text"} {
+		dirname := path.Clean("/tmp" + string(os.PathSeparator) + lang)
+		err := os.MkdirAll(dirname, os.ModePerm)
+		if err != nil {
+			t.Fatalf("Can't create test directory: %s", err.Error())
+		}
+
+		filename := path.Clean(dirname + string(os.PathSeparator) + "region.po")
+
+		f, err := os.Create(filename)
+		if err != nil {
+			t.Fatalf("Can't create test file: %s", err.Error())
+		}
+		defer f.Close()
+
+		_, err = f.WriteString("msgid \"My text\"\nmsgstr \"" + str + "\"\n")
+		if err != nil {
+			t.Fatalf("Can't write to test file: %s", err.Error())
+		}
+	}
+
+	// Without region default
+	l := NewLocale("/tmp", "zz")
+	l.AddDomain("region")
+
+	tr := l.GetD("region", "My text")
+	if tr != "My text" {
+		t.Errorf("Expected 'My text' but got '%s'", tr)
+	}
+
+	// Neutral language
+	l.SetRegionDefault("zz", "zz_BB")
+	l.AddDomain("region")
+
+	tr = l.GetD("region", "My text")
+	if tr != "Region B text" {
+		t.Errorf("Expected 'Region B text' but got '%s'", tr)
+	}
+
+	// Existing region isn't affected
+	l = NewLocale("/tmp", "zz_AA")
+	l.SetRegionDefault("zz", "zz_BB")
+	l.AddDomain("region")
+
+	tr = l.GetD("region", "My text")
+	if tr != "Region A text" {
+		t.Errorf("Expected 'Region A text' but got '%s'", tr)
+	}
+
+	// Missing region
+	l = NewLocale("/tmp", "zz_CC")
+	l.SetRegionDefault("zz", "zz_BB")
+	l.AddDomain("region")
+
+	tr = l.GetD("region", "My text")
+	if tr != "Region B text" {
+		t.Errorf("Expected 'Region B text' but got '%s'", tr)
+	}
+
+	// Remove region default
+	l.SetRegionDefault("zz", "")
+	l.AddDomain("region")
+
+	tr = l.GetD("region", "My text")
+	if tr != "My text" {
+		t.Errorf("Expected 'My text' but got '%s'", tr)
+	}
+}
+
+func TestLocaleAddDomainsTar(t *testing.T) {
+	// Create tar archive
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	for _, e := range []struct{ name, str string }{
+		{"es/", ""},
+		{"es/default.po", "msgid \"My text\"\nmsgstr \"Mi texto\"\n"},
+		{"./es/LC_MESSAGES/extras.po", "msgid \"My text\"\nmsgstr \"Mi texto extra\"\n"},
+		{"es/LC_MESSAGES/default.po", "msgid \"My text\"\nmsgstr \"Duplicado\"\n"},
+		{"es/notes.txt", "Not a catalog"},
+		{"es/sub/nested.po", "msgid \"My text\"\nmsgstr \"Anidado\"\n"},
+		{"fr/default.po", "msgid \"My text\"\nmsgstr \"Mon texte\"\n"},
+	} {
+		hdr := &tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.str)), Typeflag: tar.TypeReg}
+		if strings.HasSuffix(e.name, "/") {
+			hdr.Typeflag = tar.TypeDir
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("Can't write tar header: %s", err.Error())
+		}
+		if _, err := tw.Write([]byte(e.str)); err != nil {
+			t.Fatalf("Can't write tar entry: %s", err.Error())
+		}
+	}
+	tw.Close()
+
+	// Load domains for the Locale language
+	l := NewLocale("/tmp", "es")
+
+	errs := l.AddDomainsTar(bytes.NewReader(buf.Bytes()), "")
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 error but got %v", errs)
+	}
+	if !strings.Contains(errs[0].Error(), "es/LC_MESSAGES/default.po") {
+		t.Errorf("Expected error for 'es/LC_MESSAGES/default.po' but got '%s'", errs[0].Error())
+	}
+
+	tr := l.GetD("default", "My text")
+	if tr != "Mi texto" {
+		t.Errorf("Expected 'Mi texto' but got '%s'", tr)
+	}
+
+	tr = l.GetD("extras", "My text")
+	if tr != "Mi texto extra" {
+		t.Errorf("Expected 'Mi texto extra' but got '%s'", tr)
+	}
+
+	if _, ok := l.domains["nested"]; ok {
+		t.Error("Unexpected domain 'nested'")
+	}
+
+	// Load domains for another language
+	l = NewLocale("/tmp", "fr_FR")
+
+	if errs := l.AddDomainsTar(bytes.NewReader(buf.Bytes()), "fr"); len(errs) != 0 {
+		t.Errorf("Unexpected errors: %v", errs)
+	}
+
+	tr = l.GetD("default", "My text")
+	if tr != "Mon texte" {
+		t.Errorf("Expected 'Mon texte' but got '%s'", tr)
+	}
+
+	// Broken archive
+	if errs := l.AddDomainsTar(strings.NewReader("not a tar archive"), ""); len(errs) != 1 {
+		t.Errorf("Expected 1 error for broken archive but got %v", errs)
+	}
+}
+
+func TestLocaleZeroForm(t *testing.T) {
+	// Set PO content
+	str := `
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "One file"
+msgstr[1] "%d files"
+
+msgctxt "Ctx"
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "One file in a context"
+msgstr[1] "%d files in a context"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	l := NewLocale("/tmp", "en_US")
+	l.AttachDomain("default", po)
+
+	l.SetZeroForm("default", "", "One file", "No files")
+	l.SetZeroForm("default", "Ctx", "One file", "No files in %s")
+
+	tr := l.GetN("One file", "%d files", 0)
+	if tr != "No files" {
+		t.Errorf("Expected 'No files' but got '%s'", tr)
+	}
+
+	tr = l.GetN("One file", "%d files", 5, 5)
+	if tr != "5 files" {
+		t.Errorf("Expected '5 files' but got '%s'", tr)
+	}
+
+	tr = l.GetNC("One file", "%d files", 0, "Ctx", "a context")
+	if tr != "No files in a context" {
+		t.Errorf("Expected 'No files in a context' but got '%s'", tr)
+	}
+
+	// Other domains aren't affected
+	tr = l.GetND("extras", "One file", "%d files", 0)
+	if tr != "%!d(MISSING) files" {
+		t.Errorf("Expected '%%!d(MISSING) files' but got '%s'", tr)
+	}
+
+	// Remove zero form
+	l.SetZeroForm("default", "", "One file", "")
+
+	tr = l.GetN("One file", "%d files", 0, 0)
+	if tr != "0 files" {
+		t.Errorf("Expected '0 files' but got '%s'", tr)
+	}
+}
+
+func TestLocaleLenientPlural(t *testing.T) {
+	// Set PO content with fewer forms than expected
+	str := `
+msgid ""
+msgstr "Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "%d файл"
+msgstr[1] "%d файла"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	l := NewLocale("/tmp", "ru")
+	l.AttachDomain("default", po)
+
+	// Strict by default
+	tr := l.GetN("One file", "%d files", 5, 5)
+	if tr != "5 files" {
+		t.Errorf("Expected '5 files' but got '%s'", tr)
+	}
+
+	// Capture log
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	l.SetLenientPlural(true)
+
+	tr = l.GetN("One file", "%d files", 5, 5)
+	if tr != "5 файла" {
+		t.Errorf("Expected '5 файла' but got '%s'", tr)
+	}
+
+	if !strings.Contains(buf.String(), `plural form 2 missing for msgid "One file", using form 1`) {
+		t.Errorf("Expected missing form to be logged but got '%s'", buf.String())
+	}
+
+	// Available forms aren't affected
+	buf.Reset()
+
+	tr = l.GetN("One file", "%d files", 21, 21)
+	if tr != "21 файл" {
+		t.Errorf("Expected '21 файл' but got '%s'", tr)
+	}
+
+	tr = l.GetN("One file", "%d files", 3, 3)
+	if tr != "3 файла" {
+		t.Errorf("Expected '3 файла' but got '%s'", tr)
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("Expected nothing logged but got '%s'", buf.String())
+	}
+}
+
+func TestLocaleSliceVars(t *testing.T) {
+	// Set PO content
+	str := `
+msgid "One with var: %s"
+msgid_plural "Several with vars: %s, %s"
+msgstr[0] "This one is the singular: %s"
+msgstr[1] "This one is the plural: %s, %s"
+
+msgctxt "Ctx"
+msgid "One with var: %s"
+msgid_plural "Several with vars: %s, %s"
+msgstr[0] "This one is the singular in a Ctx context: %s"
+msgstr[1] "This one is the plural in a Ctx context: %s, %s"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	l := NewLocale("/tmp", "en_US")
+	l.AttachDomain("default", po)
+
+	vars := []interface{}{"a", "b"}
+
+	tr := l.GetNDv("default", "One with var: %s", "Several with vars: %s, %s", 2, vars)
+	if tr != "This one is the plural: a, b" {
+		t.Errorf("Expected 'This one is the plural: a, b' but got '%s'", tr)
+	}
+
+	tr = l.GetNDCv("default", "One with var: %s", "Several with vars: %s, %s", 2, "Ctx", vars)
+	if tr != "This one is the plural in a Ctx context: a, b" {
+		t.Errorf("Expected 'This one is the plural in a Ctx context: a, b' but got '%s'", tr)
+	}
+
+	// Same results as the variadic functions
+	if tr := l.GetND("default", "One with var: %s", "Several with vars: %s, %s", 0, vars[:1]...); tr != l.GetNDv("default", "One with var: %s", "Several with vars: %s, %s", 0, vars[:1]) {
+		t.Errorf("Expected same result as GetND but got '%s'", tr)
+	}
+
+	tr = l.GetNDv("extras", "One with var: %s", "Several with vars: %s, %s", 1, vars)
+	if tr != "Several with vars: a, b" {
+		t.Errorf("Expected 'Several with vars: a, b' but got '%s'", tr)
+	}
+}
+
+func TestNewLocaleFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"en/default.po":          {Data: []byte("msgid \"My text\"\nmsgstr \"Generic text\"\n")},
+		"en_US/extras.po":        {Data: []byte("msgid \"My text\"\nmsgstr \"Extras text\"\n")},
+		"en_US/LC_MESSAGES/a.po": {Data: []byte("msgid \"My text\"\nmsgstr \"Glob text\"\n")},
+		"es/default.po":          {Data: []byte("msgid \"My text\"\nmsgstr \"Mi texto\"\n")},
+	}
+
+	l := NewLocaleFS(fsys, "en_US")
+	l.AddDomain("default")
+	l.AddDomain("extras")
+
+	// Generic language fallback
+	if tr := l.Get("My text"); tr != "Generic text" {
+		t.Errorf("Expected 'Generic text' but got '%s'", tr)
+	}
+
+	if tr := l.GetD("extras", "My text"); tr != "Extras text" {
+		t.Errorf("Expected 'Extras text' but got '%s'", tr)
+	}
+
+	// Glob
+	if errs := l.AddDomainsGlob("LC_MESSAGES/*.po"); errs != nil {
+		t.Errorf("Unexpected errors: %v", errs)
+	}
+
+	if tr := l.GetD("a", "My text"); tr != "Glob text" {
+		t.Errorf("Expected 'Glob text' but got '%s'", tr)
+	}
+
+	// Reload
+	fsys["en_US/extras.po"] = &fstest.MapFile{Data: []byte("msgid \"My text\"\nmsgstr \"Reloaded text\"\n")}
+	if err := l.ReloadDomain("extras", nil); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+
+	if tr := l.GetD("extras", "My text"); tr != "Reloaded text" {
+		t.Errorf("Expected 'Reloaded text' but got '%s'", tr)
+	}
+
+	// Missing domain
+	l.AddDomain("missing")
+
+	if tr := l.GetD("missing", "My text"); tr != "My text" {
+		t.Errorf("Expected 'My text' but got '%s'", tr)
+	}
+}
+
+func TestNewLocaleFSZip(t *testing.T) {
+	// Create zip archive
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	files := map[string][]byte{
+		"es/default.po":  []byte("msgid \"My text\"\nmsgstr \"Mi texto\"\n"),
+		"es/compiled.mo": buildMo(binary.LittleEndian, []string{"My text"}, []string{"Mi texto compilado"}),
+	}
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("Can't create zip entry: %s", err.Error())
+		}
+		w.Write(data)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Can't write zip archive: %s", err.Error())
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Can't read zip archive: %s", err.Error())
+	}
+
+	l := NewLocaleFS(zr, "es_AR")
+	l.AddDomain("default")
+	l.AddDomain("compiled")
+
+	if tr := l.Get("My text"); tr != "Mi texto" {
+		t.Errorf("Expected 'Mi texto' but got '%s'", tr)
+	}
+
+	if tr := l.GetD("compiled", "My text"); tr != "Mi texto compilado" {
+		t.Errorf("Expected 'Mi texto compilado' but got '%s'", tr)
+	}
+
+	mo := new(Mo)
+	if err := mo.ParseFS(zr, "es/compiled.mo"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+
+	if tr := mo.Get("My text"); tr != "Mi texto compilado" {
+		t.Errorf("Expected 'Mi texto compilado' but got '%s'", tr)
+	}
+
+	if err := new(Mo).ParseFS(zr, "es/default.po"); err == nil {
+		t.Error("Expected error parsing a PO file as MO")
+	}
+}
+
+func TestLocaleMissingHandler(t *testing.T) {
+	// Set PO content
+	str := `
+msgid "My text"
+msgstr "Mi texto"
+
+msgctxt "Ctx"
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Un archivo"
+msgstr[1] "%d archivos"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	fb := NewLocale("/tmp", "es")
+	fb.AttachDomain("extras", po)
+
+	l := NewLocale("/tmp", "es_AR")
+	l.AttachDomain("default", po)
+	l.SetFallback(fb)
+
+	// Nil handler by default
+	l.Get("Missing")
+
+	var missing []string
+	l.SetMissingHandler(func(dom, ctx, id string, n int) {
+		// The Locale can be used from the handler
+		l.GetFallback()
+
+		missing = append(missing, fmt.Sprintf("%s|%s|%s|%d", dom, ctx, id, n))
+	})
+
+	l.Get("My text")
+	l.Get("Missing")
+	l.GetD("extras", "My text")
+	l.GetN("One file", "%d files", 2)
+	l.GetNC("One file", "%d files", 2, "Ctx")
+	l.GetNDC("extras", "One file", "%d files", 3, "Other")
+	l.GetC("Missing in context", "Ctx")
+	l.GetD("unknown", "My text")
+
+	expected := []string{
+		"default||Missing|-1",
+		"default||One file|2",
+		"extras|Other|One file|3",
+		"default|Ctx|Missing in context|-1",
+		"unknown||My text|-1",
+	}
+
+	if strings.Join(missing, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected missing strings %v but got %v", expected, missing)
+	}
+
+	// Remove handler
+	missing = nil
+	l.SetMissingHandler(nil)
+	l.Get("Missing")
+
+	if len(missing) != 0 {
+		t.Errorf("Expected no missing strings but got %v", missing)
+	}
+}
+
+func TestLocaleGetTranslations(t *testing.T) {
+	// Set PO content
+	str := `
+msgid ""
+msgstr "Language: es\n"
+
+msgid "My text"
+msgstr "Mi texto"
+
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Un archivo"
+msgstr[1] "%d archivos"
+
+msgctxt "Ctx"
+msgid "My text"
+msgstr "Mi texto en un contexto"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	l := NewLocale("/tmp", "es")
+	l.AttachDomain("extras", po)
+	l.AttachDomain("default", new(Po))
+
+	doms := l.GetDomains()
+	if strings.Join(doms, ",") != "default,extras" {
+		t.Errorf("Expected 'default' and 'extras' domains but got %v", doms)
+	}
+
+	trs := l.GetTranslations("extras")
+	expected := map[string]string{
+		"My text":        "Mi texto",
+		"One file":       "Un archivo",
+		"Ctx\x04My text": "Mi texto en un contexto",
+	}
+
+	if len(trs) != len(expected) {
+		t.Errorf("Expected %d translations but got %v", len(expected), trs)
+	}
+	for id, tr := range expected {
+		if trs[id] != tr {
+			t.Errorf("Expected '%s' for %q but got '%s'", tr, id, trs[id])
+		}
+	}
+
+	// Changes don't affect the catalog
+	trs["My text"] = "Changed"
+
+	if tr := l.GetD("extras", "My text"); tr != "Mi texto" {
+		t.Errorf("Expected 'Mi texto' but got '%s'", tr)
+	}
+
+	// Missing domain
+	if trs := l.GetTranslations("missing"); trs != nil {
+		t.Errorf("Expected nil translations but got %v", trs)
+	}
+}
+
+func TestLocaleSetDomain(t *testing.T) {
+	// Set PO content
+	str := `
+msgid "My text"
+msgstr "Mi texto"
+
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Un archivo"
+msgstr[1] "%d archivos"
+
+msgctxt "Ctx"
+msgid "My text"
+msgstr "Mi texto en un contexto"
+
+msgctxt "Ctx"
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Un archivo en un contexto"
+msgstr[1] "%d archivos en un contexto"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	l := NewLocale("/tmp", "es")
+	l.AttachDomain("myapp", po)
+
+	if dom := l.GetDomain(); dom != "default" {
+		t.Errorf("Expected 'default' domain but got '%s'", dom)
+	}
+
+	if tr := l.Get("My text"); tr != "My text" {
+		t.Errorf("Expected 'My text' but got '%s'", tr)
+	}
+
+	l.SetDomain("myapp")
+
+	if tr := l.Get("My text"); tr != "Mi texto" {
+		t.Errorf("Expected 'Mi texto' but got '%s'", tr)
+	}
+
+	if tr := l.GetN("One file", "%d files", 2, 2); tr != "2 archivos" {
+		t.Errorf("Expected '2 archivos' but got '%s'", tr)
+	}
+
+	if tr := l.GetC("My text", "Ctx"); tr != "Mi texto en un contexto" {
+		t.Errorf("Expected 'Mi texto en un contexto' but got '%s'", tr)
+	}
+
+	if tr := l.GetNC("One file", "%d files", 2, "Ctx", 2); tr != "2 archivos en un contexto" {
+		t.Errorf("Expected '2 archivos en un contexto' but got '%s'", tr)
+	}
+
+	// Restore default domain
+	l.SetDomain("")
+
+	if tr := l.Get("My text"); tr != "My text" {
+		t.Errorf("Expected 'My text' but got '%s'", tr)
+	}
+}
+
+func TestLocaleAddAllDomains(t *testing.T) {
+	// Set catalog files content
+	files := map[string]string{
+		"all/zz/default.po":               "msgid \"My text\"\nmsgstr \"Default text\"\n",
+		"all/zz/default.json":             `{"My text": "JSON default text"}`,
+		"all/zz/frontend.json":            `{"My text": "Frontend text"}`,
+		"all/zz/broken.json":              `{"My text": "Broken text"`,
+		"all/zz/notes.txt":                "msgid \"My text\"\nmsgstr \"Not a domain\"\n",
+		"all/zz/dir.po/ignored.po":        "",
+		"all/zz/LC_MESSAGES/default.po":   "msgid \"My text\"\nmsgstr \"Duplicated text\"\n",
+		"all/zz/LC_MESSAGES/messages.po":  "msgid \"My text\"\nmsgstr \"Messages text\"\n",
+		"all/zz/extra/extra.po":           "msgid \"My text\"\nmsgstr \"Extra text\"\n",
+		"all/zz_FB/fallback.po":           "msgid \"My text\"\nmsgstr \"Fallback text\"\n",
+		"all/zz_FB/LC_MESSAGES/errors.po": "msgid \"My text\"\nmsgstr \"Errors text\"\n",
+	}
+
+	os.RemoveAll(path.Clean("/tmp" + string(os.PathSeparator) + "all"))
+	for name, str := range files {
+		filename := path.Clean("/tmp" + string(os.PathSeparator) + name)
+
+		err := os.MkdirAll(path.Dir(filename), os.ModePerm)
+		if err != nil {
+			t.Fatalf("Can't create test directory: %s", err.Error())
+		}
+
+		err = ioutil.WriteFile(filename, []byte(str), 0644)
+		if err != nil {
+			t.Fatalf("Can't write to test file: %s", err.Error())
+		}
+	}
+
+	l := NewLocaleWithFallback("/tmp/all", "zz", "zz_FB")
+
+	errs := l.AddAllDomains()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "broken.json") {
+		t.Errorf("Unexpected errors: %v", errs)
+	}
+
+	expected := []string{"default", "frontend", "messages"}
+	if doms := l.GetDomains(); strings.Join(doms, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected domains %v but got %v", expected, doms)
+	}
+
+	tests := map[string]string{
+		"default":  "Default text",
+		"frontend": "Frontend text",
+		"messages": "Messages text",
+		"fallback": "Fallback text",
+		"errors":   "Errors text",
+	}
+	for dom, expected := range tests {
+		if tr := l.GetD(dom, "My text"); tr != expected {
+			t.Errorf("Expected '%s' on '%s' but got '%s'", expected, dom, tr)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package gotext
+
+// Approximate sizes, in bytes, used to estimate the memory used by the loaded catalogs.
+const (
+	// String header (pointer and length).
+	memString = 16
+
+	// Map bucket share of each entry, besides its key and value.
+	memMapEntry = 48
+
+	// Translation object, without the contents of its strings, forms and flags.
+	memTranslation = 120
+
+	// Empty map header.
+	memMap = 48
+)
+
+/*
+MemStats returns the amount of entries loaded on this Locale domains and an estimate of the memory they use, in bytes.
+Domains attached with AttachDomain are included, while the fallback Locale isn't,
+nor the domains registered on lazy mode that weren't looked up yet.
+It's meant for capacity planning, like sizing a cache of locales or detecting a runaway-large catalog:
+
+    entries, size := locale.MemStats()
+    if size > maxLocaleSize {
+        log.Printf("Locale %s uses about %d bytes for %d entries", lang, size, entries)
+    }
+
+The estimate sums the length of every string stored, plus a fixed overhead for each string, map entry and Translation object.
+It doesn't account for memory shared with other objects or for allocator overhead, so it isn't exact.
+Obsolete entries are counted, since they are kept in memory too.
+*/
+func (l *Locale) MemStats() (entries int, approxBytes int64) {
+	// Sync read
+	l.RLock()
+	domains := make(map[string]*Po, len(l.domains))
+	for dom, po := range l.domains {
+		domains[dom] = po
+	}
+	l.RUnlock()
+
+	approxBytes = memMap
+	for dom, po := range domains {
+		n, size := po.memStats()
+		entries += n
+		approxBytes += memMapEntry + memString + int64(len(dom)) + size
+	}
+
+	return entries, approxBytes
+}
+
+// memStats returns the amount of entries stored on the catalog and an estimate of the memory they use, in bytes.
+func (po *Po) memStats() (entries int, approxBytes int64) {
+	// Sync read
+	po.RLock()
+	defer po.RUnlock()
+
+	approxBytes = 2 * memMap
+	for key, t := range po.translations {
+		entries++
+		approxBytes += memMapEntry + memString + int64(len(key)) + t.memSize()
+	}
+
+	for ctx, trs := range po.contexts {
+		approxBytes += memMapEntry + memString + int64(len(ctx)) + memMap
+		for key, t := range trs {
+			entries++
+			approxBytes += memMapEntry + memString + int64(len(key)) + t.memSize()
+		}
+	}
+
+	for _, t := range po.obsolete {
+		entries++
+		approxBytes += t.memSize()
+	}
+
+	return entries, approxBytes
+}
+
+// memSize returns an estimate of the memory used by the translation, in bytes.
+func (t *Translation) memSize() int64 {
+	size := int64(memTranslation + len(t.Context) + len(t.ID) + len(t.PluralID))
+
+	size += memMap
+	for _, str := range t.Trs {
+		size += memMapEntry + memString + int64(len(str))
+	}
+
+	for _, list := range [][]string{t.Flags, t.Comments, t.ExtractedComments, t.References} {
+		for _, str := range list {
+			size += memString + int64(len(str))
+		}
+	}
+
+	return size
+}
@@ -0,0 +1,62 @@
+package gotext
+
+import (
+	"testing"
+)
+
+func TestLocaleMemStats(t *testing.T) {
+	// Set PO content
+	str := `
+msgid "My text"
+msgstr "Mi texto"
+
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Un archivo"
+msgstr[1] "%d archivos"
+
+msgctxt "Ctx"
+msgid "Some random in a context"
+msgstr "Algo aleatorio en un contexto"
+
+#~ msgid "Old text"
+#~ msgstr "Texto viejo"
+`
+
+	l := NewLocale("/tmp", "es")
+
+	entries, empty := l.MemStats()
+	if entries != 0 {
+		t.Errorf("Expected 0 entries but got %d", entries)
+	}
+
+	po := new(Po)
+	po.Parse(str)
+	l.AttachDomain("default", po)
+
+	entries, size := l.MemStats()
+	if entries != 4 {
+		t.Errorf("Expected 4 entries but got %d", entries)
+	}
+
+	// Size includes at least the stored strings
+	if size < empty+int64(len(str)/2) {
+		t.Errorf("Unexpected size %d", size)
+	}
+
+	// Bigger catalogs report a bigger size
+	big := new(Po)
+	big.Parse(str + `
+msgid "Another text, long enough to make a difference on the estimated size"
+msgstr "Otro texto, suficientemente largo para hacer una diferencia en el tamaño estimado"
+`)
+	l.AttachDomain("default", big)
+
+	entries, bigSize := l.MemStats()
+	if entries != 5 {
+		t.Errorf("Expected 5 entries but got %d", entries)
+	}
+	if bigSize <= size {
+		t.Errorf("Expected size bigger than %d but got %d", size, bigSize)
+	}
+}
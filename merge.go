@@ -0,0 +1,142 @@
+package gotext
+
+import (
+	"sort"
+)
+
+// PluralMerge defines how Merge solves the entries having a different amount of plural forms on each catalog.
+type PluralMerge int
+
+const (
+	// PluralMergeTarget keeps the target entry, so the merged catalog keeps the target plural conventions.
+	PluralMergeTarget PluralMerge = iota
+
+	// PluralMergeNPlurals uses the entry whose amount of plural forms matches the nplurals value
+	// declared on the target Plural-Forms header. The target entry is kept when both or none of them match,
+	// or when the target doesn't declare nplurals.
+	PluralMergeNPlurals
+)
+
+// MergeOptions holds the settings used to merge catalogs with Merge.
+type MergeOptions struct {
+	// Replace translated target entries with the translated source ones.
+	// By default, source entries only fill the entries missing or untranslated on the target.
+	Overwrite bool
+
+	// Rule used for entries having a different amount of plural forms on each catalog.
+	Plurals PluralMerge
+
+	// Keep the target msgid_plural on entries declaring a different one on each catalog,
+	// even when the source entry is merged, so the target acts as the canonical catalog.
+	// By default, the msgid_plural comes along with the merged entry.
+	KeepPluralID bool
+}
+
+/*
+Merge returns a new catalog combining the entries of the given catalogs (target and source),
+like when adding community translations (source) to an existing catalog (target).
+Neither of the given catalogs is modified.
+
+Entries are identified by their context and msgid. Every target entry is kept in its original order,
+and the source entries missing on the target are added after them. For the entries present on both catalogs:
+
+  - If the amount of plural forms differs, the entry is chosen as a whole following opts.Plurals,
+    so the merged entries never mix forms from different plural conventions.
+  - Otherwise, the source entry replaces an untranslated target entry,
+    or a translated one when opts.Overwrite is set. Untranslated source entries never replace target ones.
+  - The msgid_plural is taken from the chosen entry, or from the target one when opts.KeepPluralID is set.
+
+The target header is kept, or the source header is used if the target doesn't have one.
+The merged catalog uses the target whitespace normalization setting.
+*/
+func Merge(target, source *Po, opts MergeOptions) *Po {
+	a := target.snapshot()
+	b := source.snapshot()
+
+	// Get target plural forms amount
+	nplurals := 0
+	if h, ok := a[entryKey{}]; ok {
+		nplurals = headerNPlurals(parseHeader(h.Trs[0]))
+	}
+
+	merged := new(Po)
+	target.RLock()
+	merged.collapse = target.collapse
+	target.RUnlock()
+	merged.init()
+
+	// Merge target entries
+	for _, k := range seqOrder(a) {
+		t := a[k]
+
+		pluralID := t.PluralID
+		if s, ok := b[k]; ok && mergeSource(t, s, nplurals, opts) {
+			t = s
+		}
+
+		t = t.copy()
+		if opts.KeepPluralID {
+			t.PluralID = pluralID
+		}
+
+		merged.store(k.ctx, t)
+	}
+
+	// Add source entries missing on target
+	for _, k := range seqOrder(b) {
+		if _, ok := a[k]; !ok {
+			merged.store(k.ctx, b[k].copy())
+		}
+	}
+
+	return merged
+}
+
+// mergeSource returns true if the source translation (s) has to replace the target one (t) on a merged catalog
+// whose target declares the given amount of plural forms (nplurals).
+func mergeSource(t, s *Translation, nplurals int, opts MergeOptions) bool {
+	// Keep target header
+	if t.ID == "" {
+		return false
+	}
+
+	// Solve different plural forms amounts
+	if len(t.Trs) != len(s.Trs) {
+		if opts.Plurals == PluralMergeNPlurals && nplurals > 0 {
+			return len(s.Trs) == nplurals && len(t.Trs) != nplurals
+		}
+
+		return false
+	}
+
+	if !s.translated() {
+		return false
+	}
+
+	return opts.Overwrite || !t.translated()
+}
+
+// translated returns true if any of the translation forms isn't empty.
+func (t *Translation) translated() bool {
+	for _, str := range t.Trs {
+		if str != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// seqOrder returns the keys of the given entries sorted by their order on the parsed catalog.
+func seqOrder(entries map[entryKey]*Translation) []entryKey {
+	keys := make([]entryKey, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return entries[keys[i]].seq < entries[keys[j]].seq
+	})
+
+	return keys
+}
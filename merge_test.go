@@ -0,0 +1,172 @@
+package gotext
+
+import (
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	// Set target PO content
+	target := `
+msgid ""
+msgstr ""
+"Language: ru\n"
+"Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+msgid "My text"
+msgstr "Target text"
+
+msgid "Untranslated"
+msgstr ""
+
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Target file"
+msgstr[1] "Target files"
+
+msgid "One folder"
+msgid_plural "%d folders"
+msgstr[0] "Target folder"
+msgstr[1] "Target folders few"
+msgstr[2] "Target folders many"
+
+msgid "One item"
+msgid_plural "%d items"
+msgstr[0] ""
+msgstr[1] ""
+msgstr[2] ""
+`
+
+	// Set source PO content
+	source := `
+msgid ""
+msgstr ""
+"Language: ru\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+msgid "My text"
+msgstr "Source text"
+
+msgid "Untranslated"
+msgstr "Source translated"
+
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Source file"
+msgstr[1] "Source files few"
+msgstr[2] "Source files many"
+
+msgid "One folder"
+msgid_plural "%d folders"
+msgstr[0] "Source folder"
+msgstr[1] "Source folders"
+
+msgid "One item"
+msgid_plural "%d item(s)"
+msgstr[0] "Source item"
+msgstr[1] "Source items"
+msgstr[2] "Source items many"
+
+msgctxt "Ctx"
+msgid "Only in source"
+msgstr "Source only"
+
+msgid "Empty in source"
+msgstr ""
+`
+
+	a := new(Po)
+	a.Parse(target)
+
+	b := new(Po)
+	b.Parse(source)
+
+	// Default options
+	merged := Merge(a, b, MergeOptions{})
+
+	for str, expected := range map[string]string{
+		"My text":         "Target text",
+		"Untranslated":    "Source translated",
+		"Empty in source": "",
+	} {
+		if e := merged.GetEntry(str); e == nil || e.get() != expected {
+			t.Errorf("Expected '%s' for '%s' but got %v", expected, str, e)
+		}
+	}
+
+	if tr := merged.GetC("Only in source", "Ctx"); tr != "Source only" {
+		t.Errorf("Expected 'Source only' but got '%s'", tr)
+	}
+
+	if tr := merged.GetN("One file", "%d files", 2); tr != "Target files" {
+		t.Errorf("Expected 'Target files' but got '%s'", tr)
+	}
+
+	if tr := merged.GetN("One folder", "%d folders", 2); tr != "Target folders few" {
+		t.Errorf("Expected 'Target folders few' but got '%s'", tr)
+	}
+
+	if h := parseHeader(merged.snapshot()[entryKey{}].Trs[0]); headerNPlurals(h) != 3 {
+		t.Errorf("Expected target header but got '%s'", h["Plural-Forms"])
+	}
+
+	// Overwrite and plural forms matching the target header
+	merged = Merge(a, b, MergeOptions{Overwrite: true, Plurals: PluralMergeNPlurals})
+
+	if tr := merged.Get("My text"); tr != "Source text" {
+		t.Errorf("Expected 'Source text' but got '%s'", tr)
+	}
+
+	if e := merged.GetEntry("One file"); e == nil || len(e.Trs) != 3 || e.Trs[2] != "Source files many" {
+		t.Errorf("Expected source plural forms but got %v", e)
+	}
+
+	if e := merged.GetEntry("One folder"); e == nil || len(e.Trs) != 3 || e.Trs[2] != "Target folders many" {
+		t.Errorf("Expected target plural forms but got %v", e)
+	}
+
+	if e := merged.GetEntry("One item"); e == nil || e.PluralID != "%d item(s)" {
+		t.Errorf("Expected source msgid_plural but got %v", e)
+	}
+
+	// Canonical msgid_plural
+	merged = Merge(a, b, MergeOptions{KeepPluralID: true})
+
+	if e := merged.GetEntry("One item"); e == nil || e.PluralID != "%d items" || e.Trs[0] != "Source item" {
+		t.Errorf("Expected source entry with target msgid_plural but got %v", e)
+	}
+
+	// Original catalogs aren't modified
+	if tr := a.Get("Untranslated"); tr != "" {
+		t.Errorf("Expected target catalog to be unchanged but got '%s'", tr)
+	}
+
+	// Entries order
+	var order []string
+	for _, k := range seqOrder(merged.snapshot()) {
+		order = append(order, k.id)
+	}
+
+	expected := []string{"", "My text", "Untranslated", "One file", "One folder", "One item", "Only in source", "Empty in source"}
+	if len(order) != len(expected) {
+		t.Fatalf("Expected %v entries but got %v", expected, order)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Errorf("Expected entry '%s' at position %d but got '%s'", expected[i], i, order[i])
+		}
+	}
+}
+
+func TestHeaderNPlurals(t *testing.T) {
+	for value, expected := range map[string]int{
+		"nplurals=3; plural=(n%10==1 ? 0 : 1);": 3,
+		" nplurals = 2 ; plural=(n != 1);":      2,
+		"plural=(n != 1);":                      0,
+		"nplurals=x; plural=0;":                 0,
+		"":                                      0,
+	} {
+		if n := headerNPlurals(map[string]string{"Plural-Forms": value}); n != expected {
+			t.Errorf("Expected %d for '%s' but got %d", expected, value, n)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package gotext
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+/*
+MissingCollector gathers the strings requested on one or more Locale objects that aren't translated,
+so they can be reviewed or written as PO templates (.pot files) to be translated:
+
+    c := gotext.NewMissingCollector()
+    l.SetMissingCollector(c)
+
+    // ... exercise the application ...
+
+    c.DumpFiles("/path/to/templates")
+
+Each string is added once per domain, with its context and plural msgid, in the order they were first requested.
+It's safe for concurrent use, so a single collector can be shared by the Locale objects of every language.
+*/
+type MissingCollector struct {
+	// Templates with the missing strings by domain.
+	catalogs map[string]*Po
+
+	// Sync Mutex
+	sync.Mutex
+}
+
+// NewMissingCollector creates and initializes a new, empty MissingCollector.
+func NewMissingCollector() *MissingCollector {
+	return &MissingCollector{
+		catalogs: make(map[string]*Po),
+	}
+}
+
+// add saves the given untranslated string (id) with its context (ctx) and plural msgid (plural) on the given domain (dom),
+// unless it was already added.
+func (c *MissingCollector) add(dom, ctx, id, plural string) {
+	c.Lock()
+	defer c.Unlock()
+
+	pot, ok := c.catalogs[dom]
+	if !ok {
+		pot = NewPoTemplate()
+		c.catalogs[dom] = pot
+	}
+
+	if pot.GetEntryC(id, ctx) != nil {
+		return
+	}
+
+	t := NewTranslation()
+	t.Context = ctx
+	t.ID = id
+	t.PluralID = plural
+	t.Trs[0] = ""
+	if plural != "" {
+		t.Trs[1] = ""
+	}
+
+	pot.SetEntry(t)
+}
+
+// Domains returns the sorted names of the domains with missing strings.
+func (c *MissingCollector) Domains() []string {
+	c.Lock()
+	defer c.Unlock()
+
+	doms := make([]string, 0, len(c.catalogs))
+	for dom := range c.catalogs {
+		doms = append(doms, dom)
+	}
+	sort.Strings(doms)
+
+	return doms
+}
+
+// Catalog returns the template holding the missing strings of the given domain (dom),
+// or nil if none was found. It's shared with the collector, so it keeps getting the strings found afterwards.
+func (c *MissingCollector) Catalog(dom string) *Po {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.catalogs[dom]
+}
+
+// DumpFiles writes the template of each domain with missing strings to the given directory (dir),
+// named after the domain with the ".pot" extension, like "default.pot".
+// It stops and returns the error of the first file that can't be written.
+func (c *MissingCollector) DumpFiles(dir string) error {
+	for _, dom := range c.Domains() {
+		if err := c.Catalog(dom).DumpFile(filepath.Join(dir, dom+".pot")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reset removes every missing string collected so far.
+func (c *MissingCollector) Reset() {
+	c.Lock()
+	defer c.Unlock()
+
+	c.catalogs = make(map[string]*Po)
+}
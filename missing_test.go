@@ -0,0 +1,113 @@
+package gotext
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestMissingCollector(t *testing.T) {
+	// Set PO content
+	str := `
+msgid "My text"
+msgstr "Mi texto"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	c := NewMissingCollector()
+
+	// Shared by several locales
+	l := NewLocale("/tmp", "es")
+	l.AttachDomain("default", po)
+	l.SetMissingCollector(c)
+
+	fr := NewLocale("/tmp", "fr")
+	fr.AttachDomain("default", new(Po))
+	fr.SetMissingCollector(c)
+
+	l.Get("My text")
+	l.Get("Missing")
+	fr.Get("My text")
+	l.GetN("One file", "%d files", 2)
+	fr.GetN("One file", "%d files", 1)
+	l.GetC("Open", "Menu")
+	l.GetNDC("extras", "One item", "%d items", 2, "Ctx")
+
+	if doms := c.Domains(); len(doms) != 2 || doms[0] != "default" || doms[1] != "extras" {
+		t.Fatalf("Unexpected domains: %v", doms)
+	}
+
+	data, err := c.Catalog("default").MarshalText()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	expected := `#, fuzzy
+msgid ""
+msgstr ""
+"Project-Id-Version: PACKAGE VERSION\n"
+"MIME-Version: 1.0\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+"Content-Transfer-Encoding: 8bit\n"
+"Plural-Forms: nplurals=INTEGER; plural=EXPRESSION;\n"
+
+msgid "Missing"
+msgstr ""
+
+msgid "My text"
+msgstr ""
+
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] ""
+msgstr[1] ""
+
+msgctxt "Menu"
+msgid "Open"
+msgstr ""
+`
+	if string(data) != expected {
+		t.Errorf("Unexpected content:\n%s", data)
+	}
+
+	// Write templates
+	dirname := path.Clean("/tmp" + string(os.PathSeparator) + "missing")
+	os.RemoveAll(dirname)
+	if err := os.MkdirAll(dirname, os.ModePerm); err != nil {
+		t.Fatalf("Can't create test directory: %s", err.Error())
+	}
+
+	if err := c.DumpFiles(dirname); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	data, err = ioutil.ReadFile(path.Join(dirname, "extras.pot"))
+	if err != nil {
+		t.Fatalf("Can't read template: %s", err.Error())
+	}
+	if !strings.HasSuffix(string(data), "msgctxt \"Ctx\"\nmsgid \"One item\"\nmsgid_plural \"%d items\"\nmsgstr[0] \"\"\nmsgstr[1] \"\"\n") {
+		t.Errorf("Unexpected content:\n%s", data)
+	}
+
+	// Reset and remove
+	c.Reset()
+	if doms := c.Domains(); len(doms) != 0 {
+		t.Errorf("Expected no domains but got %v", doms)
+	}
+
+	l.SetMissingCollector(nil)
+	l.Get("Missing")
+	if c.Catalog("default") != nil {
+		t.Error("Expected no missing strings after removing the collector")
+	}
+
+	// Missing directory
+	fr.Get("Missing")
+	if err := c.DumpFiles(path.Join(dirname, "unknown")); err == nil {
+		t.Error("Expected error writing to a missing directory")
+	}
+}
@@ -0,0 +1,216 @@
+package gotext
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// MO files magic number, as read with the byte order used to write the file.
+const (
+	moMagic        = 0x950412de
+	moMagicSwapped = 0xde120495
+)
+
+// Size of the MO file header, up to the hash table offset.
+const moHeaderSize = 28
+
+/*
+Mo parses the content of any MO (Machine Object) file, the binary catalogs compiled by msgfmt,
+and provides the same translation methods as Po, so compiled catalogs can be used without their sources.
+
+Example:
+
+    import "github.com/leonelquinteros/gotext"
+
+    func main() {
+        // Create mo object
+        mo := new(gotext.Mo)
+
+        // Parse .mo file
+        mo.ParseFile("/path/to/mo/file/translations.mo")
+
+        // Get translation
+        println(mo.Get("Translate this"))
+    }
+
+Both byte orders are supported. Contexts and plural forms are read from the msgctxt and msgid_plural
+conventions of the format: the "\x04" separator between the context and the msgid,
+and the "\x00" separator between the msgid and the msgid_plural, and between the plural forms.
+
+Like Po, badly formatted content is ignored: a file that isn't a valid MO file, or is truncated, loads no translations at all.
+*/
+type Mo struct {
+	Po
+}
+
+// ParseFile tries to read the file by its provided path (f) and parse its content as a .mo file.
+func (mo *Mo) ParseFile(f string) {
+	mo.Po.parseMOFile(f)
+}
+
+// Parse loads the translations specified in the provided MO binary content (buf).
+func (mo *Mo) Parse(buf []byte) {
+	mo.Po.parseMO(buf)
+}
+
+// ParseReader reads the MO binary content from the provided reader (r) and parses it, like ParseFile.
+// The content is parsed only if it's read completely, otherwise the read error is returned,
+// as well as the error if it isn't a valid MO file, like ParseFS.
+func (mo *Mo) ParseReader(r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return mo.Po.parseMO(data)
+}
+
+// ParseFS reads the file at the given path (name) of the given file system (fsys) and parses it as a .mo file,
+// like Po.ParseFS. It returns the error if the file can't be read or isn't a valid MO file.
+func (mo *Mo) ParseFS(fsys fs.FS, name string) error {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+
+	return mo.Po.parseMO(data)
+}
+
+// parseMOFile loads the translations from the MO file at the given path (f), if it can be read.
+func (po *Po) parseMOFile(f string) {
+	// Check if file exists
+	info, err := os.Stat(f)
+	if err != nil {
+		return
+	}
+
+	// Check that isn't a directory
+	if info.IsDir() {
+		return
+	}
+
+	// Parse file content
+	data, err := ioutil.ReadFile(f)
+	if err != nil {
+		return
+	}
+
+	po.parseMO(data)
+}
+
+// parseMO loads the translations from the given MO binary content (buf).
+// The whole content is checked before storing any translation, so it returns an error without changes
+// if it isn't a valid MO file or any string is out of its bounds.
+func (po *Po) parseMO(buf []byte) error {
+	if len(buf) < moHeaderSize {
+		return errors.New("mo: file too short")
+	}
+
+	// Get byte order
+	var order binary.ByteOrder
+	switch binary.LittleEndian.Uint32(buf) {
+	case moMagic:
+		order = binary.LittleEndian
+	case moMagicSwapped:
+		order = binary.BigEndian
+	default:
+		return errors.New("mo: invalid magic number")
+	}
+
+	// Only major revisions 0 and 1 exist
+	if rev := order.Uint32(buf[4:]); rev>>16 > 1 {
+		return errors.New("mo: unsupported revision")
+	}
+
+	count := uint64(order.Uint32(buf[8:]))
+	origs := uint64(order.Uint32(buf[12:]))
+	trans := uint64(order.Uint32(buf[16:]))
+	hashSize := uint64(order.Uint32(buf[20:]))
+	hashOffset := uint64(order.Uint32(buf[24:]))
+
+	// Check tables bounds
+	if origs+count*8 > uint64(len(buf)) || trans+count*8 > uint64(len(buf)) {
+		return errors.New("mo: string table out of bounds")
+	}
+
+	// The hash table is only used to speed up lookups on the file itself, as every string gets indexed on load,
+	// but a declared table out of bounds means the file is corrupt
+	if hashSize > 0 && hashOffset+hashSize*4 > uint64(len(buf)) {
+		return errors.New("mo: hash table out of bounds")
+	}
+
+	// Read every entry
+	entries := make([]*Translation, 0, count)
+	for i := uint64(0); i < count; i++ {
+		id, err := moString(buf, order, origs+i*8)
+		if err != nil {
+			return err
+		}
+
+		str, err := moString(buf, order, trans+i*8)
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, moTranslation(id, str))
+	}
+
+	// Init storage
+	po.initParse()
+
+	// Keep track of the parsed content
+	po.Lock()
+	po.fingerprint = SourceFingerprint(po.fingerprint + string(buf))
+	po.Unlock()
+
+	for _, tr := range entries {
+		po.save(tr.Context, tr)
+	}
+
+	return nil
+}
+
+// moString returns the string described by the MO table descriptor (length and offset) found at the given position (pos).
+func moString(buf []byte, order binary.ByteOrder, pos uint64) (string, error) {
+	length := uint64(order.Uint32(buf[pos:]))
+	offset := uint64(order.Uint32(buf[pos+4:]))
+
+	if offset+length > uint64(len(buf)) {
+		return "", errors.New("mo: string out of bounds")
+	}
+
+	return string(buf[offset : offset+length]), nil
+}
+
+// moTranslation returns the translation for the given MO original (id) and translated (str) strings.
+func moTranslation(id, str string) *Translation {
+	tr := NewTranslation()
+
+	// Get context
+	if i := strings.Index(id, "\x04"); i >= 0 {
+		tr.Context = id[:i]
+		id = id[i+1:]
+	}
+
+	// Get plural
+	if i := strings.Index(id, "\x00"); i >= 0 {
+		tr.ID = id[:i]
+		tr.PluralID = id[i+1:]
+
+		for n, form := range strings.Split(str, "\x00") {
+			tr.Trs[n] = form
+		}
+
+		return tr
+	}
+
+	tr.ID = id
+	tr.Trs[0] = str
+
+	return tr
+}
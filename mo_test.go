@@ -0,0 +1,241 @@
+package gotext
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+// buildMo returns the MO binary content for the given original and translated strings, in the given byte order.
+func buildMo(order binary.ByteOrder, origs, trans []string) []byte {
+	var buf bytes.Buffer
+
+	n := uint32(len(origs))
+	origsOffset := uint32(28)
+	transOffset := origsOffset + n*8
+	offset := transOffset + n*8
+
+	binary.Write(&buf, order, []uint32{moMagic, 0, n, origsOffset, transOffset, 0, offset})
+
+	// Tables
+	var data bytes.Buffer
+	for _, list := range [][]string{origs, trans} {
+		for _, s := range list {
+			binary.Write(&buf, order, []uint32{uint32(len(s)), offset + uint32(data.Len())})
+			data.WriteString(s + "\x00")
+		}
+	}
+
+	buf.Write(data.Bytes())
+
+	return buf.Bytes()
+}
+
+func TestMo(t *testing.T) {
+	origs := []string{
+		"",
+		"My text",
+		"One file\x00%d files",
+		"Ctx\x04Some random in a context",
+	}
+	trans := []string{
+		"Language: es\nPlural-Forms: nplurals=2; plural=(n != 1);\n",
+		"Mi texto",
+		"Un archivo\x00%d archivos",
+		"Algo aleatorio en un contexto",
+	}
+
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		mo := new(Mo)
+		if err := mo.ParseReader(bytes.NewReader(buildMo(order, origs, trans))); err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+
+		if tr := mo.Get("My text"); tr != "Mi texto" {
+			t.Errorf("Expected 'Mi texto' but got '%s'", tr)
+		}
+
+		if tr := mo.GetN("One file", "%d files", 5, 5); tr != "5 archivos" {
+			t.Errorf("Expected '5 archivos' but got '%s'", tr)
+		}
+
+		if tr := mo.GetC("Some random in a context", "Ctx"); tr != "Algo aleatorio en un contexto" {
+			t.Errorf("Expected 'Algo aleatorio en un contexto' but got '%s'", tr)
+		}
+
+		if tr := mo.Get("Some random in a context"); tr != "Some random in a context" {
+			t.Errorf("Expected 'Some random in a context' but got '%s'", tr)
+		}
+
+		if lang := parseHeader(mo.snapshot()[entryKey{}].Trs[0])["Language"]; lang != "es" {
+			t.Errorf("Expected 'es' language but got '%s'", lang)
+		}
+	}
+}
+
+// msgfmtOutput is the big endian MO file, with a hash table, compiled by GNU msgfmt from:
+//
+//	msgid "school"
+//	msgstr "école"
+//
+//	msgctxt "law"
+//	msgid "right"
+//	msgstr "le droit"
+//
+//	msgctxt "good"
+//	msgid "right"
+//	msgstr "le bien"
+//
+//	msgctxt "organization"
+//	msgid "club"
+//	msgid_plural "clubs"
+//	msgstr[0] "le club"
+//	msgstr[1] "les clubs"
+//
+//	msgctxt "stick"
+//	msgid "club"
+//	msgid_plural "clubs"
+//	msgstr[0] "le bâton"
+//	msgstr[1] "les bâtons"
+//
+// along with a header declaring "Plural-Forms: nplurals=2; plural=(n > 1);".
+var msgfmtOutput, _ = hex.DecodeString(
+	"de12049500000000060000001c0000004c0000000b0000007c00000000000000a80000000a000000a900000009000000" +
+		"b400000017000000be00000006000000d600000010000000dd0000006a010000ee000000070000005902000008000000" +
+		"61020000110000006a020000060000007c02000015000000830200000100000000000000000000000300000006000000" +
+		"00000000020000000400000000000000050000000000000000676f6f64047269676874006c6177047269676874006f72" +
+		"67616e697a6174696f6e04636c756200636c756273007363686f6f6c00737469636b04636c756200636c756273005072" +
+		"6f6a6563742d49642d56657273696f6e3a205041434b4147452056455253494f4e0a5265706f72742d4d736769642d42" +
+		"7567732d546f3a200a504f542d4372656174696f6e2d446174653a20323031352d30312d32372031313a30352b303330" +
+		"300a504f2d5265766973696f6e2d446174653a20594541522d4d4f2d444120484f3a4d492b5a4f4e450a4c6173742d54" +
+		"72616e736c61746f723a2046554c4c204e414d45203c454d41494c40414444524553533e0a4c616e67756167652d5465" +
+		"616d3a204c414e4755414745203c4c4c406c692e6f72673e0a4c616e67756167653a200a4d494d452d56657273696f6e" +
+		"3a20312e300a436f6e74656e742d547970653a20746578742f706c61696e3b20636861727365743d7574662d380a436f" +
+		"6e74656e742d5472616e736665722d456e636f64696e673a20386269740a506c7572616c2d466f726d733a206e706c75" +
+		"72616c733d323b20706c7572616c3d286e203e2031293b0a006c65206269656e006c652064726f6974006c6520636c75" +
+		"62006c657320636c75627300c3a9636f6c65006c652062c3a2746f6e006c65732062c3a2746f6e7300")
+
+func TestMoMsgfmt(t *testing.T) {
+	mo := new(Mo)
+	if err := mo.ParseReader(bytes.NewReader(msgfmtOutput)); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	if tr := mo.Get("school"); tr != "école" {
+		t.Errorf("Expected 'école' but got '%s'", tr)
+	}
+
+	for ctx, expected := range map[string]string{"law": "le droit", "good": "le bien"} {
+		if tr := mo.GetC("right", ctx); tr != expected {
+			t.Errorf("Expected '%s' but got '%s'", expected, tr)
+		}
+	}
+
+	// Plural-Forms header: "n > 1"
+	for n, expected := range map[int]string{0: "le bâton", 1: "le bâton", 2: "les bâtons"} {
+		if tr := mo.GetNC("club", "clubs", n, "stick"); tr != expected {
+			t.Errorf("Expected '%s' for n = %d but got '%s'", expected, n, tr)
+		}
+	}
+
+	if tr := mo.GetNC("club", "clubs", 3, "organization"); tr != "les clubs" {
+		t.Errorf("Expected 'les clubs' but got '%s'", tr)
+	}
+
+	// Missing entries
+	if tr := mo.Get("right"); tr != "right" {
+		t.Errorf("Expected 'right' but got '%s'", tr)
+	}
+
+	if tr := mo.GetN("club", "clubs", 2); tr != "clubs" {
+		t.Errorf("Expected 'clubs' but got '%s'", tr)
+	}
+
+	// Corrupt hash table
+	bad := append([]byte(nil), msgfmtOutput...)
+	binary.BigEndian.PutUint32(bad[24:], uint32(len(bad)))
+	if err := new(Po).parseMO(bad); err == nil {
+		t.Error("Expected error for hash table out of bounds")
+	}
+}
+
+func TestMoInvalid(t *testing.T) {
+	data := buildMo(binary.LittleEndian, []string{"My text"}, []string{"Mi texto"})
+
+	// Truncated content
+	for _, size := range []int{0, 10, 30, len(data) - 10} {
+		mo := new(Mo)
+		mo.Parse(data[:size])
+
+		if tr := mo.Get("My text"); tr != "My text" {
+			t.Errorf("Expected 'My text' on truncated file at %d bytes but got '%s'", size, tr)
+		}
+	}
+
+	// Bad magic number
+	bad := append([]byte(nil), data...)
+	bad[0] = 0
+	mo := new(Mo)
+	mo.Parse(bad)
+	if tr := mo.Get("My text"); tr != "My text" {
+		t.Errorf("Expected 'My text' but got '%s'", tr)
+	}
+	if err := new(Mo).ParseReader(bytes.NewReader(bad)); err == nil {
+		t.Error("Expected error reading a bad magic number")
+	}
+	if err := new(Mo).ParseReader(bytes.NewReader(data[:30])); err == nil {
+		t.Error("Expected error reading a truncated file")
+	}
+
+	// String offset out of bounds
+	bad = append([]byte(nil), data...)
+	binary.LittleEndian.PutUint32(bad[40:], 0xffffffff)
+	if err := new(Po).parseMO(bad); err == nil {
+		t.Error("Expected error for string out of bounds")
+	}
+}
+
+func TestLocaleAddDomainMo(t *testing.T) {
+	// Set PO content
+	files := map[string][]byte{
+		"xm/default.mo":  buildMo(binary.LittleEndian, []string{"My text"}, []string{"Compiled text"}),
+		"xm/default.po":  []byte("msgid \"My text\"\nmsgstr \"Source text\"\n"),
+		"xm/extras.po":   []byte("msgid \"My text\"\nmsgstr \"Extras text\"\n"),
+		"xm/compiled.mo": buildMo(binary.LittleEndian, []string{"My text"}, []string{"Compiled text"}),
+	}
+
+	for name, data := range files {
+		filename := path.Clean("/tmp" + string(os.PathSeparator) + name)
+
+		err := os.MkdirAll(path.Dir(filename), os.ModePerm)
+		if err != nil {
+			t.Fatalf("Can't create test directory: %s", err.Error())
+		}
+
+		err = ioutil.WriteFile(filename, data, 0644)
+		if err != nil {
+			t.Fatalf("Can't write to test file: %s", err.Error())
+		}
+	}
+
+	l := NewLocale("/tmp", "xm")
+	l.AddDomain("default")
+	l.AddDomain("extras")
+	l.AddDomain("compiled")
+
+	if tr := l.Get("My text"); tr != "Source text" {
+		t.Errorf("Expected 'Source text' but got '%s'", tr)
+	}
+
+	if tr := l.GetD("compiled", "My text"); tr != "Compiled text" {
+		t.Errorf("Expected 'Compiled text' but got '%s'", tr)
+	}
+
+	if tr := l.GetD("extras", "My text"); tr != "Extras text" {
+		t.Errorf("Expected 'Extras text' but got '%s'", tr)
+	}
+}
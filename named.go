@@ -0,0 +1,295 @@
+package gotext
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+GetNamed retrieves the corresponding translation for the given string, like Get,
+replacing its named placeholders with the values of the given vars instead of using the fmt.Printf syntax,
+so translators can reorder the arguments and strings with many parameters stay readable:
+
+    po.GetNamed("{name} sent you {count} messages", map[string]interface{}{
+        "name":  "Ana",
+        "count": 3,
+    })
+
+Placeholders are variable names between braces, like "{name}", formatted as fmt.Sprint does.
+A fmt verb can follow the name after a colon, like "{price:%.2f}", to format the value with it.
+Placeholders without a matching var are left as they are, and doubled braces ("{{" and "}}") insert a single brace.
+When vars is nil, the string is formatted with the fmt.Printf syntax and no vars, like Get.
+*/
+func (po *Po) GetNamed(str string, vars map[string]interface{}) string {
+	if t := po.translation(po.find(str)); t != nil {
+		return formatNamed(t.get(), vars)
+	}
+
+	// Return the same we received by default
+	return formatNamed(str, vars)
+}
+
+// GetNNamed retrieves the plural form translation for the given string and count (n), like GetN,
+// replacing its named placeholders with the values of the given vars, as described on GetNamed.
+// The count is available as the "{n}" placeholder, like "{n} files in {dir}", unless vars has its own "n" var.
+func (po *Po) GetNNamed(str, plural string, n int, vars map[string]interface{}) string {
+	vars = countVars(vars, n)
+
+	if t := po.translation(po.find(str)); t != nil {
+		return formatNamed(t.getN(po.pluralForm(n)), vars)
+	}
+
+	// Return the plural string we received by default
+	return formatNamed(plural, vars)
+}
+
+// GetCNamed retrieves the corresponding translation for the given string in the given context, like GetC,
+// replacing its named placeholders with the values of the given vars, as described on GetNamed.
+func (po *Po) GetCNamed(str, ctx string, vars map[string]interface{}) string {
+	if t := po.translation(po.findC(str, ctx)); t != nil {
+		return formatNamed(t.get(), vars)
+	}
+
+	// Return the string we received by default
+	return formatNamed(str, vars)
+}
+
+// GetNCNamed retrieves the plural form translation for the given string and count (n) in the given context, like GetNC,
+// replacing its named placeholders with the values of the given vars, and the "{n}" placeholder with the count,
+// as described on GetNNamed.
+func (po *Po) GetNCNamed(str, plural string, n int, ctx string, vars map[string]interface{}) string {
+	vars = countVars(vars, n)
+
+	if t := po.translation(po.findC(str, ctx)); t != nil {
+		return formatNamed(t.getN(po.pluralForm(n)), vars)
+	}
+
+	// Return the plural string we received by default
+	return formatNamed(plural, vars)
+}
+
+// formatNamed returns the given string (str) with its named placeholders replaced by the values of the given vars,
+// as described on Po.GetNamed, or formatted with the fmt.Printf syntax and no vars if vars is nil.
+func formatNamed(str string, vars map[string]interface{}) string {
+	if vars == nil {
+		return fmt.Sprintf(str, []interface{}{}...)
+	}
+
+	var buf strings.Builder
+	for i := 0; i < len(str); {
+		c := str[i]
+
+		// Escaped braces
+		if (c == '{' || c == '}') && i+1 < len(str) && str[i+1] == c {
+			buf.WriteByte(c)
+			i += 2
+			continue
+		}
+
+		// Placeholders
+		if c == '{' {
+			if end := strings.IndexByte(str[i+1:], '}'); end >= 0 {
+				name, verb := str[i+1:i+1+end], "%v"
+				if j := strings.IndexByte(name, ':'); j >= 0 {
+					name, verb = name[:j], name[j+1:]
+				}
+
+				if v, ok := vars[name]; ok && strings.HasPrefix(verb, "%") {
+					buf.WriteString(fmt.Sprintf(verb, v))
+					i += end + 2
+					continue
+				}
+			}
+		}
+
+		buf.WriteByte(c)
+		i++
+	}
+
+	return buf.String()
+}
+
+// countVars returns the given named vars (vars) with the given count (n) as the "n" var, unless they have one.
+// The given vars aren't changed, and nil vars are returned as they are, to format the string with the fmt.Printf syntax.
+func countVars(vars map[string]interface{}, n int) map[string]interface{} {
+	if vars == nil {
+		return nil
+	}
+	if _, ok := vars["n"]; ok {
+		return vars
+	}
+
+	res := make(map[string]interface{}, len(vars)+1)
+	for name, v := range vars {
+		res[name] = v
+	}
+	res["n"] = n
+
+	return res
+}
+
+// GetNamed uses the default domain to return the corresponding translation of a given string, like Get,
+// replacing its named placeholders with the values of the given vars, as described on Po.GetNamed.
+// Unlike GetNDv and GetNDCv, which receive the fmt.Printf vars as a slice, the Named methods receive named vars.
+func (l *Locale) GetNamed(str string, vars map[string]interface{}) string {
+	return l.GetDNamed(l.GetDomain(), str, vars)
+}
+
+// GetNNamed retrieves the plural form translation for the given string and count (n) in the default domain, like GetN,
+// replacing its named placeholders with the values of the given vars, and the "{n}" placeholder with the count,
+// as described on Po.GetNNamed.
+func (l *Locale) GetNNamed(str, plural string, n int, vars map[string]interface{}) string {
+	return l.GetNDNamed(l.GetDomain(), str, plural, n, vars)
+}
+
+// GetDNamed returns the corresponding translation in the given domain for the given string, like GetD,
+// replacing its named placeholders with the values of the given vars, as described on Po.GetNamed.
+func (l *Locale) GetDNamed(dom, str string, vars map[string]interface{}) string {
+	t, _ := l.findD(dom, str)
+	if t == nil {
+		l.missing(dom, "", str, "", -1)
+	}
+
+	return l.formatV(t, 0, str, 0, false, vars)
+}
+
+// GetNDNamed retrieves the plural form translation in the given domain for the given string and count (n), like GetND,
+// replacing its named placeholders with the values of the given vars, and the "{n}" placeholder with the count,
+// as described on Po.GetNNamed.
+func (l *Locale) GetNDNamed(dom, str, plural string, n int, vars map[string]interface{}) string {
+	if t := l.zeroForm(dom, str, "", n); t != nil {
+		return l.formatV(t, 0, plural, n, true, vars)
+	}
+
+	t, po := l.findD(dom, str)
+	if t == nil {
+		l.missing(dom, "", str, plural, n)
+
+		return l.formatV(nil, 0, plural, n, true, vars)
+	}
+
+	return l.formatV(t, po.pluralForm(n), plural, n, true, vars)
+}
+
+// GetCNamed uses the default domain to return the corresponding translation of the given string in the given context, like GetC,
+// replacing its named placeholders with the values of the given vars, as described on Po.GetNamed.
+func (l *Locale) GetCNamed(str, ctx string, vars map[string]interface{}) string {
+	return l.GetDCNamed(l.GetDomain(), str, ctx, vars)
+}
+
+// GetNCNamed retrieves the plural form translation for the given string and count (n) in the given context in the default domain,
+// like GetNC, replacing its named placeholders with the values of the given vars, and the "{n}" placeholder with the count,
+// as described on Po.GetNNamed.
+func (l *Locale) GetNCNamed(str, plural string, n int, ctx string, vars map[string]interface{}) string {
+	return l.GetNDCNamed(l.GetDomain(), str, plural, n, ctx, vars)
+}
+
+// GetDCNamed returns the corresponding translation in the given domain for the given string in the given context, like GetDC,
+// replacing its named placeholders with the values of the given vars, as described on Po.GetNamed.
+func (l *Locale) GetDCNamed(dom, str, ctx string, vars map[string]interface{}) string {
+	t, _ := l.findDC(dom, str, ctx)
+	if t == nil {
+		l.missing(dom, ctx, str, "", -1)
+	}
+
+	return l.formatV(t, 0, str, 0, false, l.withContextVars(ctx, vars))
+}
+
+// GetNDCNamed retrieves the plural form translation in the given domain for the given string and count (n) in the given context,
+// like GetNDC, replacing its named placeholders with the values of the given vars, and the "{n}" placeholder with the count,
+// as described on Po.GetNNamed.
+func (l *Locale) GetNDCNamed(dom, str, plural string, n int, ctx string, vars map[string]interface{}) string {
+	vars = l.withContextVars(ctx, vars)
+
+	if t := l.zeroForm(dom, str, ctx, n); t != nil {
+		return l.formatV(t, 0, plural, n, true, vars)
+	}
+
+	t, po := l.findDC(dom, str, ctx)
+	if t == nil {
+		l.missing(dom, ctx, str, plural, n)
+
+		return l.formatV(nil, 0, plural, n, true, vars)
+	}
+
+	return l.formatV(t, po.pluralForm(n), plural, n, true, vars)
+}
+
+/*
+SetContextVars sets the named vars used by the Named lookups in the given context (ctx) of this Locale,
+so each surface of an app can substitute its own defaults, like a different product name on the menus and the emails,
+while keeping the call sites clean:
+
+    l.SetContextVars("menu", map[string]interface{}{"product": "Acme"})
+    l.SetContextVars("email", map[string]interface{}{"product": "Acme Inc."})
+
+    // "Try Acme"
+    l.GetCNamed("Try {product}", "menu", nil)
+
+The vars given on each call take precedence over the context vars, which are also used when the call gives nil vars.
+Lookups without a context (GetNamed, GetNNamed, GetDNamed and GetNDNamed) don't use them.
+The given vars are copied, and nil or empty vars remove the context vars.
+*/
+func (l *Locale) SetContextVars(ctx string, vars map[string]interface{}) {
+	l.Lock()
+	defer l.Unlock()
+	l.changed()
+
+	if len(vars) == 0 {
+		delete(l.contextVars, ctx)
+		return
+	}
+
+	if l.contextVars == nil {
+		l.contextVars = make(map[string]map[string]interface{})
+	}
+
+	cp := make(map[string]interface{}, len(vars))
+	for name, v := range vars {
+		cp[name] = v
+	}
+	l.contextVars[ctx] = cp
+}
+
+// withContextVars returns the given vars merged over the context vars set for the given context (ctx),
+// or the given vars as they are if there are none.
+// The given vars aren't changed.
+func (l *Locale) withContextVars(ctx string, vars map[string]interface{}) map[string]interface{} {
+	defaults := l.view().contextVars[ctx]
+	if defaults == nil {
+		return vars
+	}
+
+	res := make(map[string]interface{}, len(defaults)+len(vars))
+	for name, v := range defaults {
+		res[name] = v
+	}
+	for name, v := range vars {
+		res[name] = v
+	}
+
+	return res
+}
+
+// formatV works like format, replacing the named placeholders with the given vars instead,
+// or using format without vars if vars is nil.
+// The translations aren't checked for the vars they require, as named vars can be left unused.
+func (l *Locale) formatV(t *Translation, form int, plural string, n int, counted bool, vars map[string]interface{}) string {
+	if vars == nil {
+		return l.format(t, form, plural, n, counted, nil)
+	}
+	if counted {
+		vars = countVars(vars, n)
+	}
+
+	str := formatNamed(l.text(t, form, plural, counted), vars)
+
+	// Mark untranslated strings
+	missing := l.view().missingFormat
+
+	if t == nil && missing != "" {
+		return fmt.Sprintf(missing, str)
+	}
+
+	return str
+}
@@ -0,0 +1,318 @@
+package gotext
+
+import (
+	"testing"
+)
+
+func TestFormatNamed(t *testing.T) {
+	vars := map[string]interface{}{
+		"name":  "Ana",
+		"count": 3,
+		"price": 9.5,
+	}
+
+	tests := map[string]string{
+		"Hello, {name}":                   "Hello, Ana",
+		"{count} messages for {name}":     "3 messages for Ana",
+		"Total: {price:%.2f}":             "Total: 9.50",
+		"Padded: {count:%03d}":            "Padded: 003",
+		"Unknown {missing} and {name}":    "Unknown {missing} and Ana",
+		"Escaped {{name}} and }} {{":      "Escaped {name} and } {",
+		"Unclosed {name":                  "Unclosed {name",
+		"Invalid verb {name:x}":           "Invalid verb {name:x}",
+		"Percent 100% is kept for {name}": "Percent 100% is kept for Ana",
+		"{name}{name}":                    "AnaAna",
+		"":                                "",
+	}
+
+	for str, expected := range tests {
+		if res := formatNamed(str, vars); res != expected {
+			t.Errorf("Expected '%s' for '%s' but got '%s'", expected, str, res)
+		}
+	}
+
+	// No vars
+	if res := formatNamed("100%% {name}", nil); res != "100% {name}" {
+		t.Errorf("Expected '100%% {name}' but got '%s'", res)
+	}
+
+	if res := formatNamed("{name}", map[string]interface{}{}); res != "{name}" {
+		t.Errorf("Expected '{name}' but got '%s'", res)
+	}
+}
+
+func TestPoGetNamed(t *testing.T) {
+	// Set PO content
+	str := `
+msgid ""
+msgstr "Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+msgid "{name} sent you a message"
+msgstr "{name} te envió un mensaje"
+
+msgid "{name} sent {count} file to {to}"
+msgid_plural "{name} sent {count} files to {to}"
+msgstr[0] "{to} recibió {count} archivo de {name}"
+msgstr[1] "{to} recibió {count} archivos de {name}"
+
+msgctxt "Cart"
+msgid "Total: {price:%.2f}"
+msgstr "Total: {price:%.2f} €"
+
+msgctxt "Cart"
+msgid "{count} item"
+msgid_plural "{count} items"
+msgstr[0] "{count} artículo"
+msgstr[1] "{count} artículos"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	vars := map[string]interface{}{"name": "Ana", "to": "Luis", "count": 2, "price": 12.5}
+
+	if tr := po.GetNamed("{name} sent you a message", vars); tr != "Ana te envió un mensaje" {
+		t.Errorf("Expected 'Ana te envió un mensaje' but got '%s'", tr)
+	}
+
+	// Reordered placeholders
+	if tr := po.GetNNamed("{name} sent {count} file to {to}", "{name} sent {count} files to {to}", 2, vars); tr != "Luis recibió 2 archivos de Ana" {
+		t.Errorf("Expected 'Luis recibió 2 archivos de Ana' but got '%s'", tr)
+	}
+
+	if tr := po.GetCNamed("Total: {price:%.2f}", "Cart", vars); tr != "Total: 12.50 €" {
+		t.Errorf("Expected 'Total: 12.50 €' but got '%s'", tr)
+	}
+
+	if tr := po.GetNCNamed("{count} item", "{count} items", 1, "Cart", map[string]interface{}{"count": 1}); tr != "1 artículo" {
+		t.Errorf("Expected '1 artículo' but got '%s'", tr)
+	}
+
+	// Untranslated
+	if tr := po.GetNamed("Bye, {name}", vars); tr != "Bye, Ana" {
+		t.Errorf("Expected 'Bye, Ana' but got '%s'", tr)
+	}
+
+	if tr := po.GetNNamed("{count} apple", "{count} apples", 2, vars); tr != "2 apples" {
+		t.Errorf("Expected '2 apples' but got '%s'", tr)
+	}
+
+	if tr := po.GetCNamed("Total: {price}", "Other", vars); tr != "Total: 12.5" {
+		t.Errorf("Expected 'Total: 12.5' but got '%s'", tr)
+	}
+
+	if tr := po.GetNCNamed("{count} item", "{count} items", 2, "Other", vars); tr != "2 items" {
+		t.Errorf("Expected '2 items' but got '%s'", tr)
+	}
+
+	// Without vars
+	if tr := po.GetNamed("{name} sent you a message", nil); tr != "{name} te envió un mensaje" {
+		t.Errorf("Expected '{name} te envió un mensaje' but got '%s'", tr)
+	}
+}
+
+func TestLocaleGetNamed(t *testing.T) {
+	// Set PO content
+	str := `
+msgid ""
+msgstr "Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+msgid "Hello, {name}"
+msgstr "Hola, {name}"
+
+msgid "{count} file"
+msgid_plural "{count} files"
+msgstr[0] "{count} archivo"
+msgstr[1] "{count} archivos"
+
+msgctxt "Menu"
+msgid "Open {file}"
+msgstr "Abrir {file}"
+
+msgctxt "Menu"
+msgid "{count} tab"
+msgid_plural "{count} tabs"
+msgstr[0] "{count} pestaña"
+msgstr[1] "{count} pestañas"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	fb := NewLocale("/tmp", "es")
+	fb.AttachDomain("extras", po)
+
+	l := NewLocale("/tmp", "es_AR")
+	l.AttachDomain("default", po)
+	l.SetFallback(fb)
+	l.SetZeroForm("default", "", "{count} file", "Ningún archivo de {name}")
+
+	vars := map[string]interface{}{"name": "Ana", "file": "a.txt", "count": 3}
+
+	results := []struct {
+		tr, expected string
+	}{
+		{l.GetNamed("Hello, {name}", vars), "Hola, Ana"},
+		{l.GetNNamed("{count} file", "{count} files", 3, vars), "3 archivos"},
+		{l.GetCNamed("Open {file}", "Menu", vars), "Abrir a.txt"},
+		{l.GetNCNamed("{count} tab", "{count} tabs", 1, "Menu", map[string]interface{}{"count": 1}), "1 pestaña"},
+		{l.GetDNamed("extras", "Hello, {name}", vars), "Hola, Ana"},
+		{l.GetNDNamed("extras", "{count} file", "{count} files", 3, vars), "3 archivos"},
+		{l.GetDCNamed("extras", "Open {file}", "Menu", vars), "Abrir a.txt"},
+		{l.GetNDCNamed("extras", "{count} tab", "{count} tabs", 3, "Menu", vars), "3 pestañas"},
+
+		// Zero forms and fallbacks
+		{l.GetNNamed("{count} file", "{count} files", 0, vars), "Ningún archivo de Ana"},
+		{l.GetDNamed("extras", "Bye, {name}", vars), "Bye, Ana"},
+
+		// Without vars
+		{l.GetNamed("Hello, {name}", nil), "Hola, {name}"},
+	}
+
+	for _, r := range results {
+		if r.tr != r.expected {
+			t.Errorf("Expected '%s' but got '%s'", r.expected, r.tr)
+		}
+	}
+
+	// Missing strings are reported and marked
+	var missing []string
+	l.SetMissingHandler(func(dom, ctx, id string, n int) {
+		missing = append(missing, id)
+	})
+	l.SetMissingFormat("[MISSING: %s]")
+
+	if tr := l.GetNDCNamed("extras", "{count} window", "{count} windows", 3, "Menu", vars); tr != "[MISSING: 3 windows]" {
+		t.Errorf("Expected '[MISSING: 3 windows]' but got '%s'", tr)
+	}
+
+	if len(missing) != 1 || missing[0] != "{count} window" {
+		t.Errorf("Unexpected missing strings: %v", missing)
+	}
+}
+
+func TestLocaleContextVars(t *testing.T) {
+	// Set PO content
+	str := `
+msgid ""
+msgstr "Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+msgctxt "menu"
+msgid "Try {product}"
+msgstr "Prueba {product}"
+
+msgctxt "email"
+msgid "Try {product}"
+msgstr "Prueba {product}, {name}"
+
+msgctxt "menu"
+msgid "{n} plan of {product}"
+msgid_plural "{n} plans of {product}"
+msgstr[0] "{n} plan de {product}"
+msgstr[1] "{n} planes de {product}"
+
+msgid "Try {product}"
+msgstr "Prueba {product}"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	l := NewLocale("/tmp", "es")
+	l.AttachDomain("default", po)
+	l.SetContextVars("menu", map[string]interface{}{"product": "Acme"})
+
+	vars := map[string]interface{}{"product": "Acme Inc.", "name": "Ana"}
+	l.SetContextVars("email", vars)
+	vars["product"] = "Changed"
+
+	results := []struct {
+		tr, expected string
+	}{
+		{l.GetCNamed("Try {product}", "menu", nil), "Prueba Acme"},
+		{l.GetCNamed("Try {product}", "email", map[string]interface{}{}), "Prueba Acme Inc., Ana"},
+		{l.GetDCNamed("default", "Try {product}", "menu", nil), "Prueba Acme"},
+		{l.GetNCNamed("{n} plan of {product}", "{n} plans of {product}", 2, "menu", nil), "2 planes de Acme"},
+		{l.GetNDCNamed("default", "{n} plan of {product}", "{n} plans of {product}", 1, "menu", nil), "1 plan de Acme"},
+
+		// Per-call vars take precedence
+		{l.GetCNamed("Try {product}", "email", map[string]interface{}{"name": "Luis"}), "Prueba Acme Inc., Luis"},
+		{l.GetCNamed("Try {product}", "menu", map[string]interface{}{"product": "Beta"}), "Prueba Beta"},
+
+		// Lookups without a context or in other contexts don't use them
+		{l.GetNamed("Try {product}", nil), "Prueba {product}"},
+		{l.GetCNamed("Try {product}", "other", nil), "Try {product}"},
+	}
+
+	for _, r := range results {
+		if r.tr != r.expected {
+			t.Errorf("Expected '%s' but got '%s'", r.expected, r.tr)
+		}
+	}
+
+	// Removed context vars
+	l.SetContextVars("menu", nil)
+	if tr := l.GetCNamed("Try {product}", "menu", nil); tr != "Prueba {product}" {
+		t.Errorf("Expected 'Prueba {product}' but got '%s'", tr)
+	}
+}
+
+func TestNamedCount(t *testing.T) {
+	// Set PO content
+	str := `
+msgid ""
+msgstr "Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+msgid "{n} file in {dir}"
+msgid_plural "{n} files in {dir}"
+msgstr[0] "{n} файл в {dir}"
+msgstr[1] "{n} файла в {dir}"
+msgstr[2] "{n} файлов в {dir}"
+
+msgctxt "Inbox"
+msgid "{n} message"
+msgid_plural "{n} messages"
+msgstr[0] "{n} сообщение"
+msgstr[1] "{n} сообщения"
+msgstr[2] "{n} сообщений"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	l := NewLocale("/tmp", "ru")
+	l.AttachDomain("default", po)
+
+	vars := map[string]interface{}{"dir": "docs"}
+
+	results := []struct {
+		tr, expected string
+	}{
+		// The count picks the plural form and replaces the "{n}" placeholder
+		{po.GetNNamed("{n} file in {dir}", "{n} files in {dir}", 1, vars), "1 файл в docs"},
+		{po.GetNNamed("{n} file in {dir}", "{n} files in {dir}", 3, vars), "3 файла в docs"},
+		{po.GetNCNamed("{n} message", "{n} messages", 5, "Inbox", vars), "5 сообщений"},
+		{l.GetNNamed("{n} file in {dir}", "{n} files in {dir}", 21, vars), "21 файл в docs"},
+		{l.GetNDNamed("default", "{n} file in {dir}", "{n} files in {dir}", 12, vars), "12 файлов в docs"},
+		{l.GetNCNamed("{n} message", "{n} messages", 2, "Inbox", vars), "2 сообщения"},
+		{l.GetNDCNamed("default", "{n} message", "{n} messages", 11, "Inbox", map[string]interface{}{}), "11 сообщений"},
+
+		// Untranslated strings
+		{po.GetNNamed("{n} folder", "{n} folders", 4, vars), "4 folders"},
+		{l.GetNNamed("{n} folder", "{n} folders", 4, vars), "4 folders"},
+
+		// Explicit "n" vars are kept
+		{l.GetNNamed("{n} file in {dir}", "{n} files in {dir}", 3, map[string]interface{}{"n": "three", "dir": "docs"}), "three файла в docs"},
+	}
+
+	for _, r := range results {
+		if r.tr != r.expected {
+			t.Errorf("Expected '%s' but got '%s'", r.expected, r.tr)
+		}
+	}
+
+	// The given vars aren't changed
+	if _, ok := vars["n"]; ok || len(vars) != 1 {
+		t.Errorf("Unexpected vars: %v", vars)
+	}
+}
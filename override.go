@@ -0,0 +1,84 @@
+package gotext
+
+/*
+Set overrides the translation of the given string (id) in the given domain (dom) with the given text (str) at runtime,
+so apps can inject translations that aren't on the catalog files, like tenant-specific terminology loaded from a database:
+
+    l := gotext.NewLocale("/path/to/i18n/dir", "es")
+    l.AddDomain("default")
+
+    // Use "Cliente" instead of the "Usuario" translation on the catalog
+    l.Set("default", "User", "Cliente")
+
+Overrides take precedence over the domain catalog and the fallback chain on every lookup,
+and they're kept when the domain is reloaded or replaced, as they aren't stored on its catalog.
+The domain doesn't need to be loaded to set overrides for it.
+Plural overrides use the Plural-Forms rule of the domain catalog when it's loaded.
+*/
+func (l *Locale) Set(dom, id, str string) {
+	l.SetNC(dom, id, "", 0, "", str)
+}
+
+// SetN overrides the given plural form index (form) of the given string (id) in the given domain (dom)
+// with the given text (str), also setting its msgid_plural (plural), as described on Set.
+// Each plural form is set on its own, so the forms not set return the msgid_plural.
+func (l *Locale) SetN(dom, id, plural string, form int, str string) {
+	l.SetNC(dom, id, plural, form, "", str)
+}
+
+// SetC overrides the translation of the given string (id) in the given context (ctx) and domain (dom)
+// with the given text (str), as described on Set.
+func (l *Locale) SetC(dom, id, ctx, str string) {
+	l.SetNC(dom, id, "", 0, ctx, str)
+}
+
+// SetNC overrides the given plural form index (form) of the given string (id) in the given context (ctx) and domain (dom)
+// with the given text (str), also setting its msgid_plural (plural) when it isn't empty, as described on Set.
+func (l *Locale) SetNC(dom, id, plural string, form int, ctx, str string) {
+	o := l.newPo()
+
+	l.Lock()
+	if l.overrides == nil {
+		l.overrides = make(map[string]*Po)
+	}
+	if l.overrides[dom] == nil {
+		l.overrides[dom] = o
+		l.changed()
+	}
+	o = l.overrides[dom]
+	l.Unlock()
+
+	o.SetNC(id, plural, form, ctx, str)
+}
+
+// ClearOverrides removes the overrides set for the given domain (dom), so lookups use its catalog again.
+func (l *Locale) ClearOverrides(dom string) {
+	l.Lock()
+	defer l.Unlock()
+	l.changed()
+
+	delete(l.overrides, dom)
+}
+
+// override returns the override set for the given string in the given context and domain, if any,
+// along with the catalog whose plural rule applies to it: the domain one (po) when it's loaded.
+func (l *Locale) override(dom, str, ctx string, hasCtx bool, po *Po) (*Translation, *Po) {
+	o := l.view().overrides[dom]
+	if o == nil {
+		return nil, nil
+	}
+
+	t := o.findC(str, ctx)
+	if !hasCtx {
+		t = o.find(str)
+	}
+	if t == nil {
+		return nil, nil
+	}
+
+	if po != nil {
+		return t, po
+	}
+
+	return t, o
+}
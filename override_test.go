@@ -0,0 +1,92 @@
+package gotext
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestLocaleSet(t *testing.T) {
+	// Create Locale directory
+	dirname := path.Clean("/tmp" + string(os.PathSeparator) + "ru")
+	err := os.MkdirAll(dirname, os.ModePerm)
+	if err != nil {
+		t.Fatalf("Can't create test directory: %s", err.Error())
+	}
+
+	// Set PO content
+	str := `
+msgid ""
+msgstr "Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+msgid "User"
+msgstr "Пользователь"
+
+msgid "Project"
+msgstr "Проект"
+`
+	if err := ioutil.WriteFile(path.Join(dirname, "override.po"), []byte(str), 0644); err != nil {
+		t.Fatalf("Can't write to test file: %s", err.Error())
+	}
+
+	l := NewLocale("/tmp", "ru")
+	l.AddDomain("override")
+	l.SetDomain("override")
+
+	l.Set("override", "User", "Клиент")
+	l.SetC("override", "Open", "Menu", "Открыть")
+	l.SetN("override", "One task", "%d tasks", 0, "%d задача")
+	l.SetN("override", "One task", "%d tasks", 1, "%d задачи")
+	l.SetN("override", "One task", "%d tasks", 2, "%d задач")
+
+	if tr := l.Get("User"); tr != "Клиент" {
+		t.Errorf("Expected 'Клиент' but got '%s'", tr)
+	}
+	if tr := l.Get("Project"); tr != "Проект" {
+		t.Errorf("Expected 'Проект' but got '%s'", tr)
+	}
+	if tr := l.GetC("Open", "Menu"); tr != "Открыть" {
+		t.Errorf("Expected 'Открыть' but got '%s'", tr)
+	}
+	if tr := l.Get("Open"); tr != "Open" {
+		t.Errorf("Expected 'Open' but got '%s'", tr)
+	}
+
+	// Plural forms use the domain rule
+	plurals := map[int]string{1: "1 задача", 3: "3 задачи", 5: "5 задач"}
+	for n, expected := range plurals {
+		if tr := l.GetN("One task", "%d tasks", n, n); tr != expected {
+			t.Errorf("Expected '%s' but got '%s'", expected, tr)
+		}
+	}
+
+	// Overrides are kept on reload
+	if errs := l.Reload(); len(errs) != 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	if tr := l.Get("User"); tr != "Клиент" {
+		t.Errorf("Expected 'Клиент' after reload but got '%s'", tr)
+	}
+
+	// Overrides take precedence over the fallback chain
+	fb := NewLocale("/tmp", "ru")
+	fb.AddDomain("override")
+
+	nl := NewLocale("/tmp", "xx")
+	nl.SetFallback(fb)
+	nl.Set("override", "Project", "Задача")
+
+	if tr := nl.GetD("override", "Project"); tr != "Задача" {
+		t.Errorf("Expected 'Задача' but got '%s'", tr)
+	}
+	if tr := nl.GetD("override", "User"); tr != "Пользователь" {
+		t.Errorf("Expected 'Пользователь' but got '%s'", tr)
+	}
+
+	// Clear
+	l.ClearOverrides("override")
+	if tr := l.Get("User"); tr != "Пользователь" {
+		t.Errorf("Expected 'Пользователь' but got '%s'", tr)
+	}
+}
@@ -0,0 +1,341 @@
+package gotext
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// pluralRule returns the plural form index for the count n.
+type pluralRule func(n int) int
+
+// pluralDefault is the rule used when the catalog doesn't declare a valid Plural-Forms header: "n != 1".
+var pluralDefault pluralRule = func(n int) int {
+	if n != 1 {
+		return 1
+	}
+	return 0
+}
+
+// headerPluralRule returns the rule declared by the plural expression of the Plural-Forms field
+// of the given header fields, or nil if it's not declared or it can't be parsed.
+// Like GNU gettext, forms out of the nplurals range declared by the same field are replaced by the first one.
+func headerPluralRule(fields map[string]string) pluralRule {
+	for _, param := range strings.Split(fields["Plural-Forms"], ";") {
+		param = strings.TrimSpace(param)
+		if !strings.HasPrefix(param, "plural") {
+			continue
+		}
+
+		value := strings.TrimSpace(strings.TrimPrefix(param, "plural"))
+		if !strings.HasPrefix(value, "=") {
+			continue
+		}
+
+		rule, err := parsePluralRule(value[1:])
+		if err != nil {
+			return nil
+		}
+
+		nplurals := headerNPlurals(fields)
+		if nplurals == 0 {
+			return rule
+		}
+
+		return func(n int) int {
+			if form := rule(n); form >= 0 && form < nplurals {
+				return form
+			}
+			return 0
+		}
+	}
+
+	return nil
+}
+
+// pluralParser reads the C-style expressions used by the Plural-Forms header, like "(n%10==1 && n%100!=11) ? 0 : 1".
+// It supports the n variable, integer literals, parentheses, the ternary operator,
+// and the logical, comparison and arithmetic operators, with the C precedence.
+type pluralParser struct {
+	tokens []string
+	pos    int
+}
+
+// Operators supported by the plural expressions, longest first so they are matched greedily.
+var pluralOperators = []string{"||", "&&", "==", "!=", "<=", ">=", "<", ">", "+", "-", "*", "/", "%", "!", "?", ":", "(", ")"}
+
+// Binary operators by precedence level, from the lowest to the highest one.
+var pluralLevels = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+// parsePluralRule parses the given plural expression (str).
+func parsePluralRule(str string) (pluralRule, error) {
+	tokens, err := pluralTokens(str)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &pluralParser{tokens: tokens}
+
+	expr, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, errors.New("unexpected " + strconv.Quote(p.tokens[p.pos]))
+	}
+
+	return expr, nil
+}
+
+// pluralTokens splits the given plural expression (str) into numbers, the n variable and operators.
+func pluralTokens(str string) ([]string, error) {
+	var tokens []string
+
+	for i := 0; i < len(str); {
+		c := str[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case c == 'n':
+			tokens = append(tokens, "n")
+			i++
+
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(str) && str[j] >= '0' && str[j] <= '9' {
+				j++
+			}
+			tokens = append(tokens, str[i:j])
+			i = j
+
+		default:
+			op := ""
+			for _, o := range pluralOperators {
+				if strings.HasPrefix(str[i:], o) {
+					op = o
+					break
+				}
+			}
+
+			if op == "" {
+				return nil, errors.New("invalid character " + strconv.Quote(string(c)))
+			}
+
+			tokens = append(tokens, op)
+			i += len(op)
+		}
+	}
+
+	return tokens, nil
+}
+
+// peek returns the next token, or an empty string at the end of the expression.
+func (p *pluralParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+
+	return ""
+}
+
+// ternary parses a conditional expression: "cond ? a : b", or any expression with higher precedence.
+func (p *pluralParser) ternary() (pluralRule, error) {
+	cond, err := p.binary(0)
+	if err != nil || p.peek() != "?" {
+		return cond, err
+	}
+	p.pos++
+
+	a, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek() != ":" {
+		return nil, errors.New("missing ':' on conditional expression")
+	}
+	p.pos++
+
+	b, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(n int) int {
+		if cond(n) != 0 {
+			return a(n)
+		}
+		return b(n)
+	}, nil
+}
+
+// binary parses a left-associative chain of the binary operators on the given precedence level.
+func (p *pluralParser) binary(level int) (pluralRule, error) {
+	if level == len(pluralLevels) {
+		return p.unary()
+	}
+
+	x, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op := p.peek()
+		if !pluralOperatorIn(op, pluralLevels[level]) {
+			return x, nil
+		}
+		p.pos++
+
+		y, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		x = pluralBinary(op, x, y)
+	}
+}
+
+// unary parses a negated expression: "!x" or "-x", or a primary expression.
+func (p *pluralParser) unary() (pluralRule, error) {
+	switch p.peek() {
+	case "!":
+		p.pos++
+
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		return func(n int) int {
+			return pluralBool(x(n) == 0)
+		}, nil
+
+	case "-":
+		p.pos++
+
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		return func(n int) int {
+			return -x(n)
+		}, nil
+	}
+
+	return p.primary()
+}
+
+// primary parses the n variable, an integer or an expression between parentheses.
+func (p *pluralParser) primary() (pluralRule, error) {
+	tok := p.peek()
+	p.pos++
+
+	switch {
+	case tok == "":
+		return nil, errors.New("unexpected end of expression")
+
+	case tok == "n":
+		return func(n int) int {
+			return n
+		}, nil
+
+	case tok == "(":
+		x, err := p.ternary()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.peek() != ")" {
+			return nil, errors.New("missing ')'")
+		}
+		p.pos++
+
+		return x, nil
+	}
+
+	v, err := strconv.Atoi(tok)
+	if err != nil {
+		return nil, errors.New("unexpected " + strconv.Quote(tok))
+	}
+
+	return func(n int) int {
+		return v
+	}, nil
+}
+
+// pluralOperatorIn returns true if the given operator (op) is one of the given ones (ops).
+func pluralOperatorIn(op string, ops []string) bool {
+	for _, o := range ops {
+		if op == o {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pluralBinary returns the expression applying the binary operator (op) to the given operands (x, y).
+// Division and modulo by zero result in 0, so an invalid expression can't panic.
+func pluralBinary(op string, x, y pluralRule) pluralRule {
+	return func(n int) int {
+		a, b := x(n), y(n)
+
+		switch op {
+		case "||":
+			return pluralBool(a != 0 || b != 0)
+		case "&&":
+			return pluralBool(a != 0 && b != 0)
+		case "==":
+			return pluralBool(a == b)
+		case "!=":
+			return pluralBool(a != b)
+		case "<":
+			return pluralBool(a < b)
+		case "<=":
+			return pluralBool(a <= b)
+		case ">":
+			return pluralBool(a > b)
+		case ">=":
+			return pluralBool(a >= b)
+		case "+":
+			return a + b
+		case "-":
+			return a - b
+		case "*":
+			return a * b
+		case "/":
+			if b == 0 {
+				return 0
+			}
+			return a / b
+		case "%":
+			if b == 0 {
+				return 0
+			}
+			return a % b
+		}
+
+		return 0
+	}
+}
+
+// pluralBool returns the C integer value of the given boolean (b).
+func pluralBool(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,135 @@
+package gotext
+
+import (
+	"testing"
+)
+
+func TestParsePluralRule(t *testing.T) {
+	for expr, expected := range map[string][]int{
+		// Forms for n = 0, 1, 2, 3, 5, 11, 21, 22, 25, 101, 112
+		"0":                                   {0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		"(n != 1)":                            {1, 0, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+		"n>1":                                 {0, 0, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+		"(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2": {2, 0, 1, 1, 2, 2, 2, 2, 2, 2, 2},
+		"(n==1 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2)": {2, 0, 1, 1, 2, 2, 2, 1, 2, 2, 2},
+		"n%10==1 && n%100!=11 ? 0 : n != 0 ? 1 : 2":                          {2, 0, 1, 1, 1, 1, 0, 1, 1, 0, 1},
+		"!(n == 1) + 2 * 0 - -0":                                             {1, 0, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+		"n / 0 + n % 0":                                                      {0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+	} {
+		rule, err := parsePluralRule(expr)
+		if err != nil {
+			t.Errorf("Unexpected error for '%s': %s", expr, err.Error())
+			continue
+		}
+
+		for i, n := range []int{0, 1, 2, 3, 5, 11, 21, 22, 25, 101, 112} {
+			if form := rule(n); form != expected[i] {
+				t.Errorf("Expected form %d for n = %d on '%s' but got %d", expected[i], n, expr, form)
+			}
+		}
+	}
+
+	// Invalid expressions
+	for _, expr := range []string{"", "n ==", "(n != 1", "n ? 1", "n != 1)", "x > 1", "n 1"} {
+		if _, err := parsePluralRule(expr); err == nil {
+			t.Errorf("Expected error for '%s'", expr)
+		}
+	}
+}
+
+func TestPoPluralForms(t *testing.T) {
+	// Set PO content
+	str := `
+msgid ""
+msgstr ""
+"Language: cs\n"
+"Plural-Forms: nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;\n"
+
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "%d soubor"
+msgstr[1] "%d soubory"
+msgstr[2] "%d souborů"
+
+msgctxt "Ctx"
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "%d soubor v kontextu"
+msgstr[1] "%d soubory v kontextu"
+msgstr[2] "%d souborů v kontextu"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	for n, expected := range map[int]string{1: "1 soubor", 3: "3 soubory", 5: "5 souborů", 0: "0 souborů"} {
+		if tr := po.GetN("One file", "%d files", n, n); tr != expected {
+			t.Errorf("Expected '%s' but got '%s'", expected, tr)
+		}
+	}
+
+	if tr := po.GetNC("One file", "%d files", 4, "Ctx", 4); tr != "4 soubory v kontextu" {
+		t.Errorf("Expected '4 soubory v kontextu' but got '%s'", tr)
+	}
+
+	// Through a Locale
+	l := NewLocale("/tmp", "cs")
+	l.AttachDomain("default", po)
+
+	if tr := l.GetN("One file", "%d files", 2, 2); tr != "2 soubory" {
+		t.Errorf("Expected '2 soubory' but got '%s'", tr)
+	}
+
+	if tr := l.GetNC("One file", "%d files", 7, "Ctx", 7); tr != "7 souborů v kontextu" {
+		t.Errorf("Expected '7 souborů v kontextu' but got '%s'", tr)
+	}
+
+	// Invalid expressions use the default rule
+	po = new(Po)
+	po.Parse(`
+msgid ""
+msgstr "Plural-Forms: nplurals=2; plural=n ?? 1;\n"
+
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "%d archivo"
+msgstr[1] "%d archivos"
+`)
+
+	for n, expected := range map[int]string{1: "1 archivo", 0: "0 archivos", 2: "2 archivos"} {
+		if tr := po.GetN("One file", "%d files", n, n); tr != expected {
+			t.Errorf("Expected '%s' but got '%s'", expected, tr)
+		}
+	}
+}
+
+func TestHeaderPluralRule(t *testing.T) {
+	for header, expected := range map[string][]int{
+		// Forms for n = 0, 1, 2, 3, 5, 11, 21, 22, 25, 101, 112
+		"nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);": {2, 0, 1, 1, 2, 2, 0, 1, 2, 0, 2},
+		"nplurals=3; plural=(n==1 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);":                 {2, 0, 1, 1, 2, 2, 2, 1, 2, 2, 2},
+		"nplurals=6; plural=(n==0 ? 0 : n==1 ? 1 : n==2 ? 2 : n%100>=3 && n%100<=10 ? 3 : n%100>=11 ? 4 : 5);":   {0, 1, 2, 3, 3, 4, 4, 4, 4, 5, 4},
+		"nplurals=2; plural=n == 1 ? 0 : 5;": {0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		"plural=n % 10; nplurals=4":          {0, 1, 2, 3, 0, 1, 1, 2, 0, 1, 2},
+		"nplurals=2; plural=(n != 1)":        {1, 0, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+	} {
+		rule := headerPluralRule(map[string]string{"Plural-Forms": header})
+		if rule == nil {
+			t.Errorf("Expected rule for '%s'", header)
+			continue
+		}
+
+		for i, n := range []int{0, 1, 2, 3, 5, 11, 21, 22, 25, 101, 112} {
+			if form := rule(n); form != expected[i] {
+				t.Errorf("Expected form %d for n = %d on '%s' but got %d", expected[i], n, header, form)
+			}
+		}
+	}
+
+	// Missing or invalid expressions
+	for _, header := range []string{"", "nplurals=2;", "nplurals=2; plural=n >;", "nplurals=2; plurals=n != 1;"} {
+		if rule := headerPluralRule(map[string]string{"Plural-Forms": header}); rule != nil {
+			t.Errorf("Expected no rule for '%s'", header)
+		}
+	}
+}
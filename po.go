@@ -1,51 +1,219 @@
 package gotext
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"io/fs"
 	"io/ioutil"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"unicode"
 )
 
-type translation struct {
-	id       string
-	pluralId string
-	trs      map[int]string
+// Translation holds a single catalog entry: its msgid, msgid_plural and the translated forms.
+type Translation struct {
+	// Context (msgctxt) of the entry, empty for context-less entries.
+	Context string
+
+	ID       string
+	PluralID string
+
+	// Translated forms (msgstr) by plural index. The singular form is stored under index 0.
+	Trs map[int]string
+
+	// Line number where the entry msgid starts on the parsed content, or 0 if it wasn't parsed.
+	Line int
+
+	// Flags declared on "#," comments, in the order they were found, like "fuzzy" or "range: 0..10".
+	Flags []string
+
+	// Translator comments ("# "), extracted comments ("#.") and source references ("#:", like "main.go:12"),
+	// in the order they were found. References declared on a single line are split, one per element.
+	Comments          []string
+	ExtractedComments []string
+	References        []string
+
+	// Position of the entry on the catalog.
+	seq int
 }
 
-func newTranslation() *translation {
-	tr := new(translation)
-	tr.trs = make(map[int]string)
+// NewTranslation creates and initializes a new Translation object.
+func NewTranslation() *Translation {
+	tr := new(Translation)
+	tr.Trs = make(map[int]string)
 
 	return tr
 }
 
-func (t *translation) get() string {
+// copy returns a copy of the translation, so it can be handed to callers without exposing the catalog storage.
+func (t *Translation) copy() *Translation {
+	c := *t
+	c.Trs = make(map[int]string, len(t.Trs))
+	for i, str := range t.Trs {
+		c.Trs[i] = str
+	}
+
+	if t.Flags != nil {
+		c.Flags = append([]string(nil), t.Flags...)
+	}
+	if t.Comments != nil {
+		c.Comments = append([]string(nil), t.Comments...)
+	}
+	if t.ExtractedComments != nil {
+		c.ExtractedComments = append([]string(nil), t.ExtractedComments...)
+	}
+	if t.References != nil {
+		c.References = append([]string(nil), t.References...)
+	}
+
+	return &c
+}
+
+// setComments sets the translator comments, extracted comments and references
+// found on the given comment lines (lines), including their "#" prefix.
+func (t *Translation) setComments(lines []string) {
+	for _, l := range lines {
+		switch {
+		case strings.HasPrefix(l, "#."):
+			t.ExtractedComments = append(t.ExtractedComments, strings.TrimSpace(l[2:]))
+		case strings.HasPrefix(l, "#:"):
+			t.References = append(t.References, strings.Fields(l[2:])...)
+		default:
+			t.Comments = append(t.Comments, strings.TrimPrefix(l[1:], " "))
+		}
+	}
+}
+
+func (t *Translation) get() string {
 	// Look for translation index 0
-	if _, ok := t.trs[0]; ok {
-		return t.trs[0]
+	if _, ok := t.Trs[0]; ok {
+		return t.Trs[0]
 	}
 
 	// Return unstranlated id by default
-	return t.id
+	return t.ID
 }
 
-func (t *translation) getN(n int) string {
+func (t *Translation) getN(n int) string {
 	// Look for translation index
-	if _, ok := t.trs[n]; ok {
-		return t.trs[n]
+	if _, ok := t.Trs[n]; ok {
+		return t.Trs[n]
 	}
 
 	// Return unstranlated plural by default
-	return t.pluralId
+	return t.PluralID
+}
+
+// clampIndex returns the available plural form index closest to n: the highest index below it,
+// or the lowest index if there isn't any. It returns false if the translation doesn't have any form.
+func (t *Translation) clampIndex(n int) (int, bool) {
+	if _, ok := t.Trs[n]; ok {
+		return n, true
+	}
+
+	indexes := make([]int, 0, len(t.Trs))
+	for i := range t.Trs {
+		indexes = append(indexes, i)
+	}
+	if len(indexes) == 0 {
+		return 0, false
+	}
+	sort.Ints(indexes)
+
+	res := indexes[0]
+	for _, i := range indexes {
+		if i < n {
+			res = i
+		}
+	}
+
+	return res, true
+}
+
+// HasFlag reports whether the entry declares the given flag (name).
+// Flags with parameters, like "range: 0..10", are matched by their name ("range").
+func (t *Translation) HasFlag(name string) bool {
+	_, ok := t.flagValue(name)
+	return ok
+}
+
+// flagValue returns the parameter of the given flag (name), if declared.
+// Flags without parameters return an empty value.
+func (t *Translation) flagValue(name string) (string, bool) {
+	for _, flag := range t.Flags {
+		parts := strings.SplitN(flag, ":", 2)
+		if strings.TrimSpace(parts[0]) != name {
+			continue
+		}
+
+		if len(parts) == 1 {
+			return "", true
+		}
+
+		return strings.TrimSpace(parts[1]), true
+	}
+
+	return "", false
+}
+
+// empty reports whether the translation buffer is still empty, with no msgid nor msgstr set.
+func (t *Translation) empty() bool {
+	return t.ID == "" && t.PluralID == "" && len(t.Trs) == 0
+}
+
+// requiredArgs returns the number of format arguments needed by the translation.
+// It uses the value declared by the "args:N" flag, or infers it from the format verbs on the msgid and msgid_plural.
+func (t *Translation) requiredArgs() int {
+	if v, ok := t.flagValue("args"); ok {
+		if a, err := strconv.Atoi(v); err == nil && a >= 0 {
+			return a
+		}
+	}
+
+	args := countVerbs(t.ID)
+	if n := countVerbs(t.PluralID); n > args {
+		args = n
+	}
+
+	return args
+}
+
+// entryKey identifies an entry on a catalog by its context and msgid.
+type entryKey struct {
+	ctx string
+	id  string
+}
+
+// String returns the entry key in PO format.
+func (k entryKey) String() string {
+	if k.ctx == "" {
+		return "msgid " + strconv.Quote(k.id)
+	}
+
+	return "msgctxt " + strconv.Quote(k.ctx) + " msgid " + strconv.Quote(k.id)
+}
+
+// sortEntryKeys sorts the given keys by context and msgid, with context-less entries first.
+func sortEntryKeys(keys []entryKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].ctx != keys[j].ctx {
+			return keys[i].ctx < keys[j].ctx
+		}
+
+		return keys[i].id < keys[j].id
+	})
 }
 
 /*
 Po parses the content of any PO file and provides all the translation functions needed.
 It's the base object used by all packafe methods.
 And it's safe for concurrent use by multiple goroutines by using the sync package for write locking.
+Lookups read an immutable copy of the catalog, published again after each change, so they don't wait for each other.
 
 Example:
 
@@ -65,13 +233,182 @@ Example:
 */
 type Po struct {
 	// Storage
-	translations map[string]*translation
-	contexts     map[string]map[string]*translation
+	translations map[string]*Translation
+	contexts     map[string]map[string]*Translation
+
+	// Collapse runs of whitespace on msgids and looked up strings.
+	collapse bool
+
+	// Amount of entries saved, used to keep track of their order.
+	seq int
+
+	// Function called for each untranslated or fuzzy entry parsed.
+	warn func(Warning)
+
+	// Fingerprint of the parsed content.
+	fingerprint string
+
+	// Obsolete entries, kept apart from the storage.
+	obsolete []*Translation
+
+	// Context used by lookups without an explicit context.
+	defaultContext string
+
+	// Duplicate entries found while parsing that declare a different msgid_plural.
+	pluralConflicts []Warning
+
+	// Position of the first entry of the content being parsed, to look for duplicates among its entries only.
+	parseSeq int
+
+	// Rule declared by the Plural-Forms header, or nil to use the default one.
+	plural pluralRule
+
+	// Use the entries flagged as fuzzy on lookups.
+	fuzzy bool
+
+	// Reject content that can't be converted to UTF-8 from its declared charset.
+	strictCharset bool
+
+	// Lookup state published for the lookups, or a nil *poView when it has to be built again.
+	published atomic.Value
 
 	// Sync Mutex
 	sync.RWMutex
 }
 
+/*
+SetLoadWarnHandler sets a function (h) to be called while parsing, for each entry that is untranslated or marked as fuzzy.
+It allows to check a catalog completeness on load, without a separate pass over the parsed entries:
+
+    po := new(gotext.Po)
+    po.SetLoadWarnHandler(func(w gotext.Warning) {
+        log.Printf("%s: %s", filename, w)
+    })
+    po.ParseFile(filename)
+
+Warnings include the line of the entry msgid on the parsed content.
+An entry with every msgstr empty produces a single warning for the whole entry,
+while a partially translated plural entry produces one for each empty form.
+Duplicate entries declaring a different msgid_plural are reported too, as described on CheckPluralConsistency.
+The header entry is never reported. Use nil to remove the handler.
+*/
+func (po *Po) SetLoadWarnHandler(h func(Warning)) {
+	po.Lock()
+	defer po.Unlock()
+
+	po.warn = h
+}
+
+// SetDefaultContext sets the context (ctx) used by the lookups without an explicit context, like Get and GetN,
+// for catalogs where most entries share a single context.
+// Those lookups look for the entry in the default context first, and then for the context-less entry,
+// so context-less entries keep working for strings missing on the default context.
+// Lookups with an explicit context, like GetC, aren't affected. Use an empty context (default) to remove it.
+func (po *Po) SetDefaultContext(ctx string) {
+	po.Lock()
+	defer po.Unlock()
+
+	po.defaultContext = ctx
+	po.changed()
+}
+
+// SetFuzzyEnabled enables or disables the use of the entries flagged as "fuzzy" on lookups.
+// Fuzzy entries need to be reviewed by a translator, so by default lookups like Get and GetN ignore them,
+// returning the source strings as if the entries were missing.
+// Entries are still parsed and returned by GetEntry and Write, regardless of this setting.
+func (po *Po) SetFuzzyEnabled(enabled bool) {
+	po.Lock()
+	defer po.Unlock()
+
+	po.fuzzy = enabled
+	po.changed()
+}
+
+/*
+SetStrictCharset enables or disables the strict charset mode, used when parsing content that isn't UTF-8.
+
+Parsed content is converted to UTF-8 from the charset declared on the Content-Type field of its header,
+like "text/plain; charset=ISO-8859-1", so legacy catalogs are looked up and returned as UTF-8 strings.
+Content without a declared charset is converted from the one detected as described on DetectCharset,
+and byte order marks are removed. ISO-8859-1, ISO-8859-15, Windows-1252, US-ASCII, UTF-16LE and UTF-16BE
+are supported, and other charsets can be added with RegisterCharset. Once converted, the header declares the UTF-8 charset,
+so the catalog is written back with a header matching its content.
+
+When disabled (default), content that can't be converted, like invalid UTF-8 or a charset without decoder,
+is parsed as is. When enabled, it isn't parsed at all, and the conversion error is returned
+by ParseReader and ParseFS, and by the Locale methods loading files.
+*/
+func (po *Po) SetStrictCharset(strict bool) {
+	po.Lock()
+	defer po.Unlock()
+
+	po.strictCharset = strict
+}
+
+// SetCollapseWhitespace enables or disables the collapsing of consecutive whitespace characters
+// into a single space, both on the msgids stored in the catalog and on the strings being looked up,
+// so Get("Hello  world") matches an entry with msgid "Hello world".
+// Leading and trailing whitespace is collapsed the same way but never trimmed.
+// It's disabled by default. Entries already parsed are re-indexed when the setting changes.
+func (po *Po) SetCollapseWhitespace(collapse bool) {
+	po.Lock()
+	defer po.Unlock()
+
+	po.collapse = collapse
+	po.changed()
+
+	if po.translations == nil {
+		return
+	}
+
+	// Re-index storage
+	translations := make(map[string]*Translation)
+	for _, t := range po.translations {
+		translations[po.key(t.ID)] = t
+	}
+	po.translations = translations
+
+	for ctx := range po.contexts {
+		entries := make(map[string]*Translation)
+		for _, t := range po.contexts[ctx] {
+			entries[po.key(t.ID)] = t
+		}
+		po.contexts[ctx] = entries
+	}
+}
+
+// key returns the storage key for the given string (str) following the catalog normalization settings.
+// It has to be called while holding the lock.
+func (po *Po) key(str string) string {
+	if po.collapse {
+		return collapseWhitespace(str)
+	}
+
+	return str
+}
+
+// snapshot returns a copy of the catalog entries keyed by context and msgid.
+// Translation objects aren't modified once stored, so they are shared with the catalog.
+func (po *Po) snapshot() map[entryKey]*Translation {
+	entries := make(map[entryKey]*Translation)
+
+	// Sync read
+	po.RLock()
+	defer po.RUnlock()
+
+	for _, t := range po.translations {
+		entries[entryKey{id: t.ID}] = t
+	}
+
+	for ctx := range po.contexts {
+		for _, t := range po.contexts[ctx] {
+			entries[entryKey{ctx: ctx, id: t.ID}] = t
+		}
+	}
+
+	return entries
+}
+
 // ParseFile tries to read the file by its provided path (f) and parse its content as a .po file.
 func (po *Po) ParseFile(f string) {
 	// Check if file exists
@@ -86,35 +423,120 @@ func (po *Po) ParseFile(f string) {
 	}
 
 	// Parse file content
-	data, err := ioutil.ReadFile(f)
+	file, err := os.Open(f)
 	if err != nil {
 		return
 	}
+	defer file.Close()
 
-	po.Parse(string(data))
+	po.ParseReader(file)
 }
 
-// Parse loads the translations specified in the provided string (str)
+// ParseReader reads the content from the provided reader (r) and parses it as a .po file,
+// like ParseFile, so catalogs can be loaded from embedded files, network responses or any other source.
+// The content is parsed only if it's read completely, otherwise the read error is returned,
+// along with the charset conversion errors on the strict charset mode (see SetStrictCharset).
+func (po *Po) ParseReader(r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return po.parse(string(data))
+}
+
+// ParseFS reads the file at the given path (name) of the given file system (fsys) and parses it as a .po file,
+// so catalogs can be loaded from an embed.FS, a zip archive or any other fs.FS implementation.
+// It returns the error if the file can't be read, or can't be converted to UTF-8 on the strict charset mode.
+func (po *Po) ParseFS(fsys fs.FS, name string) error {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+
+	return po.parse(string(data))
+}
+
+// Parse loads the translations specified in the provided string (str).
+// Content declaring a charset other than UTF-8 on its header is converted to UTF-8 first, as described on SetStrictCharset.
 func (po *Po) Parse(str string) {
-	// Init storage
-	if po.translations == nil {
-		po.Lock()
-		po.translations = make(map[string]*translation)
-		po.contexts = make(map[string]map[string]*translation)
-		po.Unlock()
+	po.parse(str)
+}
+
+// parse converts the given PO formatted string (str) to UTF-8 and loads its translations.
+// Content that can't be converted is loaded as is, unless the strict charset mode is enabled,
+// returning the error without loading anything instead.
+func (po *Po) parse(str string) error {
+	converted, err := toUTF8(str)
+	if err != nil {
+		po.RLock()
+		strict := po.strictCharset
+		po.RUnlock()
+
+		if strict {
+			return err
+		}
+		converted = str
+	}
+
+	// Keep track of the parsed content
+	po.Lock()
+	po.fingerprint = SourceFingerprint(po.fingerprint + str)
+	po.Unlock()
+
+	po.parseUTF8(converted)
+
+	// Declare the charset of the stored content, so it's written back as UTF-8
+	if err == nil && converted != str {
+		po.declareUTF8()
+	}
+
+	return nil
+}
+
+// declareUTF8 replaces the charset declared on the header entry, if any, with UTF-8.
+func (po *Po) declareUTF8() {
+	h := po.stored("", "")
+	if h == nil {
+		return
 	}
 
+	po.RLock()
+	c := h.copy()
+	po.RUnlock()
+
+	if content := utf8Header(c.Trs[0]); content != c.Trs[0] {
+		c.Trs[0] = content
+		po.replace("", c)
+	}
+}
+
+// parseUTF8 loads the translations specified in the provided UTF-8 string (str).
+func (po *Po) parseUTF8(str string) {
+	// Init storage
+	po.initParse()
+
 	// Get lines
 	lines := strings.Split(str, "\n")
 
 	// Translation buffer
-	tr := newTranslation()
+	tr := NewTranslation()
 
 	// Context buffer
 	ctx := ""
 
-	for _, l := range lines {
-		// Trim spaces
+	// Flags and comment lines buffers
+	var flags, comments []string
+
+	// Last keyword read and its msgstr index, used to append continuation lines
+	field := ""
+	index := 0
+
+	// Obsolete entries buffer
+	var obsolete []string
+
+	for n, l := range lines {
+		// Trim spaces, including the indentation before continuation lines
 		l = strings.TrimSpace(l)
 
 		// Skip empty lines
@@ -122,6 +544,69 @@ func (po *Po) Parse(str string) {
 			continue
 		}
 
+		// Append continuation lines to the last keyword read
+		if strings.HasPrefix(l, "\"") {
+			s, err := strconv.Unquote(l)
+			if err != nil {
+				// Skip badly formatted strings
+				continue
+			}
+
+			switch field {
+			case "msgctxt":
+				ctx += s
+			case "msgid":
+				tr.ID += s
+			case "msgid_plural":
+				tr.PluralID += s
+			case "msgstr":
+				tr.Trs[index] += s
+			}
+
+			// Loop
+			continue
+		}
+
+		// Any other line ends the last keyword
+		field = ""
+
+		// Buffer obsolete entries, with their flags and comments, and continue
+		if strings.HasPrefix(l, "#~") {
+			if strings.HasPrefix(l, "#~|") {
+				continue
+			}
+
+			obsolete = append(obsolete, comments...)
+			comments = nil
+			if len(flags) > 0 {
+				obsolete = append(obsolete, "#, "+strings.Join(flags, ", "))
+				flags = nil
+			}
+			obsolete = append(obsolete, strings.TrimPrefix(l, "#~"))
+
+			continue
+		}
+
+		// Buffer flags for the next entry and continue
+		if strings.HasPrefix(l, "#,") {
+			for _, flag := range strings.Split(strings.TrimPrefix(l, "#,"), ",") {
+				if flag = strings.TrimSpace(flag); flag != "" {
+					flags = append(flags, flag)
+				}
+			}
+
+			continue
+		}
+
+		// Buffer comments for the next entry and continue, skipping previous msgid ("#|") comments
+		if strings.HasPrefix(l, "#") {
+			if !strings.HasPrefix(l, "#|") {
+				comments = append(comments, l)
+			}
+
+			continue
+		}
+
 		// Skip invalid lines
 		if !strings.HasPrefix(l, "msgctxt") && !strings.HasPrefix(l, "msgid") && !strings.HasPrefix(l, "msgid_plural") && !strings.HasPrefix(l, "msgstr") {
 			continue
@@ -130,25 +615,14 @@ func (po *Po) Parse(str string) {
 		// Buffer context and continue
 		if strings.HasPrefix(l, "msgctxt") {
 			// Save current translation buffer.
-			po.Lock()
-			// No context
-			if ctx == "" {
-				po.translations[tr.id] = tr
-			} else {
-				// Save context
-				if _, ok := po.contexts[ctx]; !ok {
-					po.contexts[ctx] = make(map[string]*translation)
-				}
-				po.contexts[ctx][tr.id] = tr
-			}
-			po.Unlock()
+			po.save(ctx, tr)
 
 			// Flush buffer
-			tr = newTranslation()
-			ctx = ""
+			tr = NewTranslation()
 
 			// Buffer context
 			ctx, _ = strconv.Unquote(strings.TrimSpace(strings.TrimPrefix(l, "msgctxt")))
+			field = "msgctxt"
 
 			// Loop
 			continue
@@ -156,29 +630,25 @@ func (po *Po) Parse(str string) {
 
 		// Buffer msgid and continue
 		if strings.HasPrefix(l, "msgid") && !strings.HasPrefix(l, "msgid_plural") {
-			// Save current translation buffer if not inside a context.
-			if ctx == "" {
-				po.Lock()
-				po.translations[tr.id] = tr
-				po.Unlock()
-
-				// Flush buffer
-				tr = newTranslation()
-				ctx = ""
-			} else if ctx != "" && tr.id != "" {
-				// Save current translation buffer inside a context
-				if _, ok := po.contexts[ctx]; !ok {
-					po.contexts[ctx] = make(map[string]*translation)
-				}
-				po.contexts[ctx][tr.id] = tr
+			// Save current translation buffer, unless it's the one started by a context.
+			if ctx == "" || tr.ID != "" {
+				po.save(ctx, tr)
 
 				// Flush buffer
-				tr = newTranslation()
+				tr = NewTranslation()
 				ctx = ""
 			}
 
 			// Set id
-			tr.id, _ = strconv.Unquote(strings.TrimSpace(strings.TrimPrefix(l, "msgid")))
+			tr.ID, _ = strconv.Unquote(strings.TrimSpace(strings.TrimPrefix(l, "msgid")))
+			tr.Line = n + 1
+			field = "msgid"
+
+			// Set flags and comments
+			tr.Flags = flags
+			flags = nil
+			tr.setComments(comments)
+			comments = nil
 
 			// Loop
 			continue
@@ -186,7 +656,8 @@ func (po *Po) Parse(str string) {
 
 		// Check for plural form
 		if strings.HasPrefix(l, "msgid_plural") {
-			tr.pluralId, _ = strconv.Unquote(strings.TrimSpace(strings.TrimPrefix(l, "msgid_plural")))
+			tr.PluralID, _ = strconv.Unquote(strings.TrimSpace(strings.TrimPrefix(l, "msgid_plural")))
+			field = "msgid_plural"
 
 			// Loop
 			continue
@@ -212,107 +683,541 @@ func (po *Po) Parse(str string) {
 				}
 
 				// Parse translation string
-				tr.trs[i], _ = strconv.Unquote(strings.TrimSpace(l[in+1:]))
+				tr.Trs[i], _ = strconv.Unquote(strings.TrimSpace(l[in+1:]))
+				field = "msgstr"
+				index = i
 
 				// Loop
 				continue
 			}
 
 			// Save single translation form under 0 index
-			tr.trs[0], _ = strconv.Unquote(l)
+			tr.Trs[0], _ = strconv.Unquote(l)
+			field = "msgstr"
+			index = 0
 		}
 	}
 
 	// Save last translation buffer.
-	if tr.id != "" {
-		po.Lock()
-		if ctx == "" {
-			po.translations[tr.id] = tr
-		} else {
-			// Save context
-			if _, ok := po.contexts[ctx]; !ok {
-				po.contexts[ctx] = make(map[string]*translation)
-			}
-			po.contexts[ctx][tr.id] = tr
+	po.save(ctx, tr)
+
+	// Parse obsolete entries
+	if len(obsolete) > 0 {
+		po.parseObsolete(strings.Join(obsolete, "\n"))
+	}
+}
+
+// parseObsolete parses the content of the obsolete entries found on a catalog (str), without their "#~" prefix,
+// and keeps them apart from the active entries.
+func (po *Po) parseObsolete(str string) {
+	parsed := new(Po)
+
+	po.RLock()
+	parsed.collapse = po.collapse
+	po.RUnlock()
+
+	parsed.parseUTF8(str)
+	entries := parsed.snapshot()
+
+	po.Lock()
+	defer po.Unlock()
+
+	for _, k := range seqOrder(entries) {
+		if k.id != "" {
+			po.obsolete = append(po.obsolete, entries[k])
 		}
+	}
+}
+
+// Obsolete returns a copy of the obsolete entries of the catalog (the ones commented out with "#~"), in their parsed order.
+// Obsolete entries are kept so they can be written back, but they are never used by the translation functions.
+func (po *Po) Obsolete() []*Translation {
+	po.RLock()
+	defer po.RUnlock()
+
+	res := make([]*Translation, len(po.obsolete))
+	for i, t := range po.obsolete {
+		res[i] = t.copy()
+	}
+
+	return res
+}
+
+// init initializes the storage if needed.
+func (po *Po) init() {
+	po.Lock()
+	defer po.Unlock()
+
+	po.initStorage()
+}
+
+// initParse initializes the storage if needed before parsing new content,
+// discarding the msgid_plural conflicts found on the content parsed before, so they aren't reported twice.
+func (po *Po) initParse() {
+	po.Lock()
+	defer po.Unlock()
+
+	po.pluralConflicts = nil
+	po.parseSeq = po.seq
+	po.initStorage()
+}
+
+// initStorage initializes the storage if needed, for callers already holding the lock.
+func (po *Po) initStorage() {
+	if po.translations == nil {
+		po.translations = make(map[string]*Translation)
+		po.contexts = make(map[string]map[string]*Translation)
+		po.changed()
+	}
+}
+
+// save stores the translation buffer (tr) in the given context, or as a context-less translation if ctx is empty,
+// and reports it to the load warning handler if needed, along with any msgid_plural conflict with a duplicate entry.
+// Empty buffers are discarded.
+func (po *Po) save(ctx string, tr *Translation) {
+	if tr.empty() {
+		return
+	}
+
+	// Look for a duplicate of the parsed content declaring a different msgid_plural
+	po.RLock()
+	start := po.parseSeq
+	po.RUnlock()
+
+	var warnings []Warning
+	if prev := po.stored(ctx, tr.ID); prev != nil && prev.seq >= start && prev.PluralID != tr.PluralID {
+		w := Warning{
+			Context: ctx,
+			MsgID:   tr.ID,
+			Index:   -1,
+			Line:    tr.Line,
+			Message: fmt.Sprintf("msgid_plural %q doesn't match %q declared by the duplicate entry on line %d", tr.PluralID, prev.PluralID, prev.Line),
+		}
+
+		po.Lock()
+		po.pluralConflicts = append(po.pluralConflicts, w)
 		po.Unlock()
+
+		warnings = append(warnings, w)
+	}
+
+	po.store(ctx, tr)
+
+	// Report untranslated and fuzzy entries
+	po.RLock()
+	warn := po.warn
+	po.RUnlock()
+
+	if warn != nil {
+		for _, w := range append(warnings, tr.loadWarnings()...) {
+			warn(w)
+		}
 	}
 }
 
-// Get retrieves the corresponding translation for the given string.
-// Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
-func (po *Po) Get(str string, vars ...interface{}) string {
-	// Sync read
+// stored returns the translation saved in the given storage context (ctx) for the given msgid (id), or nil if there isn't any.
+func (po *Po) stored(ctx, id string) *Translation {
 	po.RLock()
 	defer po.RUnlock()
 
-	if po.translations != nil {
-		if _, ok := po.translations[str]; ok {
-			return fmt.Sprintf(po.translations[str].get(), vars...)
+	if ctx == "" {
+		return po.translations[po.key(id)]
+	}
+
+	return po.contexts[ctx][po.key(id)]
+}
+
+// store saves the translation (tr) in the given storage context (ctx).
+func (po *Po) store(ctx string, tr *Translation) {
+	po.storeEntry(ctx, tr, false)
+}
+
+// replace saves the translation (tr) in the given storage context (ctx),
+// at the position of the entry it replaces, if any.
+func (po *Po) replace(ctx string, tr *Translation) {
+	po.storeEntry(ctx, tr, true)
+}
+
+// storeEntry saves the translation (tr) in the given storage context (ctx), after the stored entries,
+// or at the position of the entry it replaces when keep is set.
+// The position is set before publishing the translation, as stored translations aren't changed afterwards.
+func (po *Po) storeEntry(ctx string, tr *Translation, keep bool) {
+	po.Lock()
+	defer po.Unlock()
+
+	po.changed()
+
+	// Keep track of entries order
+	var prev *Translation
+	if keep && ctx == "" {
+		prev = po.translations[po.key(tr.ID)]
+	} else if keep {
+		prev = po.contexts[ctx][po.key(tr.ID)]
+	}
+
+	if prev != nil {
+		tr.seq = prev.seq
+	} else {
+		tr.seq = po.seq
+		po.seq++
+	}
+
+	tr.Context = ctx
+
+	// No context
+	if ctx == "" {
+		po.translations[po.key(tr.ID)] = tr
+
+		// Get plural rule from header
+		if tr.ID == "" {
+			po.plural = headerPluralRule(parseHeader(tr.Trs[0]))
 		}
+
+		return
+	}
+
+	// Save context
+	if _, ok := po.contexts[ctx]; !ok {
+		po.contexts[ctx] = make(map[string]*Translation)
+	}
+	po.contexts[ctx][po.key(tr.ID)] = tr
+}
+
+// Get retrieves the corresponding translation for the given string.
+// Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
+func (po *Po) Get(str string, vars ...interface{}) string {
+	if t := po.translation(po.find(str)); t != nil {
+		return fmt.Sprintf(t.get(), vars...)
 	}
 
 	// Return the same we received by default
 	return fmt.Sprintf(str, vars...)
 }
 
-// GetN retrieves the (N)th plural form translation for the given string.
-// If n == 0, usually the singular form of the string is returned as defined in the PO file.
+// GetN retrieves the plural form translation for the given string and count (n).
+// The form is chosen by the plural expression of the Plural-Forms header, or by the "n != 1" rule if there isn't a valid one.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func (po *Po) GetN(str, plural string, n int, vars ...interface{}) string {
-	// Sync read
-	po.RLock()
-	defer po.RUnlock()
-
-	if po.translations != nil {
-		if _, ok := po.translations[str]; ok {
-			return fmt.Sprintf(po.translations[str].getN(n), vars...)
-		}
+	if t := po.translation(po.find(str)); t != nil {
+		return fmt.Sprintf(t.getN(po.pluralForm(n)), vars...)
 	}
 
 	// Return the plural string we received by default
 	return fmt.Sprintf(plural, vars...)
 }
 
+// pluralForm returns the plural form index for the count n,
+// using the rule declared by the Plural-Forms header or the default "n != 1" rule.
+func (po *Po) pluralForm(n int) int {
+	rule := po.view().plural
+	if rule == nil {
+		rule = pluralDefault
+	}
+
+	return rule(n)
+}
+
+// find returns the translation object for the given string, or nil if the string doesn't exist in the catalog.
+func (po *Po) find(str string) *Translation {
+	v := po.view()
+	str = v.key(str)
+
+	// Look at the default context first
+	if v.defaultContext != "" {
+		if t, ok := v.contexts[v.defaultContext][str]; ok {
+			return t
+		}
+	}
+
+	return v.translations[str]
+}
+
+// translation returns the given entry (t) if it can be used by lookups, or nil if it's a fuzzy entry
+// and fuzzy entries aren't enabled.
+func (po *Po) translation(t *Translation) *Translation {
+	if t == nil || !t.HasFlag("fuzzy") || po.view().fuzzy {
+		return t
+	}
+
+	return nil
+}
+
 // GetC retrieves the corresponding translation for a given string in the given context.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func (po *Po) GetC(str, ctx string, vars ...interface{}) string {
-	// Sync read
-	po.RLock()
-	defer po.RUnlock()
-
-	if po.contexts != nil {
-		if _, ok := po.contexts[ctx]; ok {
-			if po.contexts[ctx] != nil {
-				if _, ok := po.contexts[ctx][str]; ok {
-					return fmt.Sprintf(po.contexts[ctx][str].get(), vars...)
-				}
-			}
-		}
+	if t := po.translation(po.findC(str, ctx)); t != nil {
+		return fmt.Sprintf(t.get(), vars...)
 	}
 
 	// Return the string we received by default
 	return fmt.Sprintf(str, vars...)
 }
 
-// GetNC retrieves the (N)th plural form translation for the given string in the given context.
-// If n == 0, usually the singular form of the string is returned as defined in the PO file.
+// GetNC retrieves the plural form translation for the given string and count (n) in the given context.
+// The form is chosen the same way as on GetN.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func (po *Po) GetNC(str, plural string, n int, ctx string, vars ...interface{}) string {
-	// Sync read
-	po.RLock()
-	defer po.RUnlock()
+	if t := po.translation(po.findC(str, ctx)); t != nil {
+		return fmt.Sprintf(t.getN(po.pluralForm(n)), vars...)
+	}
+
+	// Return the plural string we received by default
+	return fmt.Sprintf(plural, vars...)
+}
+
+// findC returns the translation object for the given string in the given context,
+// or nil if the string doesn't exist in the context.
+func (po *Po) findC(str, ctx string) *Translation {
+	v := po.view()
+
+	return v.contexts[ctx][v.key(str)]
+}
+
+// GetEntry returns a copy of the entry for the given string (str), or nil if the string doesn't exist in the catalog.
+// Changes to the returned object don't affect the catalog.
+func (po *Po) GetEntry(str string) *Translation {
+	if t := po.find(str); t != nil {
+		return t.copy()
+	}
+
+	return nil
+}
+
+// GetEntryC returns a copy of the entry for the given string (str) in the given context (ctx),
+// or nil if the string doesn't exist in the context.
+// Changes to the returned object don't affect the catalog.
+func (po *Po) GetEntryC(str, ctx string) *Translation {
+	if t := po.findC(str, ctx); t != nil {
+		return t.copy()
+	}
+
+	return nil
+}
+
+// IsFuzzy reports whether the entry for the given string (str) is flagged as "fuzzy", so catalog maintenance tools
+// can find the entries to review. It doesn't depend on SetFuzzyEnabled, and it's false if the string doesn't exist.
+func (po *Po) IsFuzzy(str string) bool {
+	t := po.find(str)
+
+	return t != nil && t.HasFlag("fuzzy")
+}
+
+// IsFuzzyC reports whether the entry for the given string (str) in the given context (ctx) is flagged as "fuzzy",
+// like IsFuzzy.
+func (po *Po) IsFuzzyC(str, ctx string) bool {
+	t := po.findC(str, ctx)
+
+	return t != nil && t.HasFlag("fuzzy")
+}
+
+// TranslationInfo holds the comments and flags of a catalog entry, as returned by GetTranslationInfo.
+type TranslationInfo struct {
+	// Source references ("#:"), like "main.go:12", one per element.
+	References []string
+
+	// Comments extracted from the source code ("#.") and comments written by translators ("# ").
+	ExtractedComments []string
+	Comments          []string
+
+	// Flags ("#,"), like "fuzzy" or "c-format".
+	Flags []string
+}
+
+// GetTranslationInfo returns the references, comments and flags of the entry for the given string (str),
+// or an empty TranslationInfo if the string doesn't exist in the catalog.
+func (po *Po) GetTranslationInfo(str string) TranslationInfo {
+	return po.GetEntry(str).info()
+}
+
+// GetTranslationInfoC returns the references, comments and flags of the entry for the given string (str)
+// in the given context (ctx), or an empty TranslationInfo if the string doesn't exist in the context.
+func (po *Po) GetTranslationInfoC(str, ctx string) TranslationInfo {
+	return po.GetEntryC(str, ctx).info()
+}
+
+// info returns the comments and flags of a translation copy (t), which can be nil.
+func (t *Translation) info() TranslationInfo {
+	if t == nil {
+		return TranslationInfo{}
+	}
+
+	return TranslationInfo{
+		References:        t.References,
+		ExtractedComments: t.ExtractedComments,
+		Comments:          t.Comments,
+		Flags:             t.Flags,
+	}
+}
+
+// GetTranslations returns the singular translation (msgstr, or msgstr[0] for plural entries) of every catalog entry,
+// keyed by msgid. Entries with a context are keyed by their context and msgid separated by "\x04",
+// like "Menu\x04Open", following the gettext convention. The header entry isn't included.
+// The returned map is a copy, so changing it doesn't affect the catalog.
+func (po *Po) GetTranslations() map[string]string {
+	entries := po.snapshot()
+
+	trs := make(map[string]string, len(entries))
+	for k, t := range entries {
+		if k.id == "" && k.ctx == "" {
+			continue
+		}
+
+		key := k.id
+		if k.ctx != "" {
+			key = k.ctx + "\x04" + k.id
+		}
+		trs[key] = t.Trs[0]
+	}
+
+	return trs
+}
 
-	if po.contexts != nil {
-		if _, ok := po.contexts[ctx]; ok {
-			if po.contexts[ctx] != nil {
-				if _, ok := po.contexts[ctx][str]; ok {
-					return fmt.Sprintf(po.contexts[ctx][str].getN(n), vars...)
+// Translations returns a copy of every catalog entry, including the ones with a context and their plural forms,
+// in their catalog order, so tools like translation editors can range over the whole catalog.
+// Fuzzy entries are included with their flags. The header entry isn't included.
+// Changes to the returned objects don't affect the catalog.
+func (po *Po) Translations() []*Translation {
+	entries := po.snapshot()
+
+	res := make([]*Translation, 0, len(entries))
+	for _, k := range seqOrder(entries) {
+		if k.id == "" && k.ctx == "" {
+			continue
+		}
+
+		res = append(res, entries[k].copy())
+	}
+
+	return res
+}
+
+// SetEntry saves a copy of the given entry (t) on the catalog, in the entry context,
+// replacing the existing entry with the same context and msgid, if any, at its same position.
+// New entries are added after the existing ones, so catalogs can be updated before writing them back with Write.
+func (po *Po) SetEntry(t *Translation) {
+	po.init()
+
+	c := t.copy()
+	po.replace(c.Context, c)
+}
+
+// Set sets the translation (str) of the given string (id), adding the entry if it doesn't exist.
+// Existing entries keep their comments and flags.
+func (po *Po) Set(id, str string) {
+	po.SetNC(id, "", 0, "", str)
+}
+
+// SetN sets the translation (str) of the given plural form index (form) of the given string (id),
+// also setting its msgid_plural (plural), adding the entry if it doesn't exist.
+// Existing entries keep their comments and flags.
+func (po *Po) SetN(id, plural string, form int, str string) {
+	po.SetNC(id, plural, form, "", str)
+}
+
+// SetC sets the translation (str) of the given string (id) in the given context (ctx), adding the entry if it doesn't exist.
+// Existing entries keep their comments and flags.
+func (po *Po) SetC(id, ctx, str string) {
+	po.SetNC(id, "", 0, ctx, str)
+}
+
+// SetNC sets the translation (str) of the given plural form index (form) of the given string (id) in the given context (ctx),
+// also setting its msgid_plural (plural) when it isn't empty, adding the entry if it doesn't exist.
+// Existing entries keep their comments and flags.
+func (po *Po) SetNC(id, plural string, form int, ctx, str string) {
+	t := NewTranslation()
+	t.ID = id
+	if prev := po.stored(ctx, id); prev != nil {
+		po.RLock()
+		t = prev.copy()
+		po.RUnlock()
+	}
+
+	t.Context = ctx
+	if plural != "" {
+		t.PluralID = plural
+	}
+	t.Trs[form] = str
+
+	po.SetEntry(t)
+}
+
+// countVerbs returns the number of arguments consumed by the fmt verbs in the given format string.
+// Explicit argument indexes (%[2]s) and star widths/precisions (%*d) are taken into account.
+func countVerbs(format string) int {
+	max := 0
+	for n := range formatArgs(format) {
+		if n > max {
+			max = n
+		}
+	}
+
+	return max
+}
+
+// formatArgs returns the verb consuming each argument of the given format string, keyed by argument number (starting at 1).
+// Star widths and precisions are returned as a '*' verb. If an argument is consumed more than once, the last verb is kept.
+func formatArgs(format string) map[int]byte {
+	args := make(map[int]byte)
+	argNum := 0
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+
+	verb:
+		for i++; i < len(format); i++ {
+			c := format[i]
+
+			switch {
+			case c == '%':
+				break verb
+
+			case c == '[':
+				end := strings.IndexByte(format[i:], ']')
+				if end == -1 {
+					break verb
 				}
+				if n, err := strconv.Atoi(format[i+1 : i+end]); err == nil && n > 0 {
+					argNum = n - 1
+				}
+				i += end
+
+			case c == '*':
+				argNum++
+				args[argNum] = '*'
+
+			case strings.IndexByte("+-# 0123456789.", c) != -1:
+				// Flags, width and precision
+
+			default:
+				argNum++
+				args[argNum] = c
+				break verb
 			}
 		}
 	}
 
-	// Return the plural string we received by default
-	return fmt.Sprintf(plural, vars...)
+	return args
+}
+
+// collapseWhitespace replaces every run of whitespace characters on the given string with a single space.
+func collapseWhitespace(str string) string {
+	var buf bytes.Buffer
+	space := false
+
+	for _, r := range str {
+		if unicode.IsSpace(r) {
+			if !space {
+				buf.WriteByte(' ')
+			}
+			space = true
+			continue
+		}
+
+		space = false
+		buf.WriteRune(r)
+	}
+
+	return buf.String()
 }
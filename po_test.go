@@ -1,9 +1,14 @@
 package gotext
 
 import (
+	"bytes"
 	"os"
 	"path"
+	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
+	"testing/iotest"
 )
 
 func TestPo(t *testing.T) {
@@ -84,8 +89,8 @@ msgstr "More translation"
 
 	// Test plural
 	tr = po.GetN("One with var: %s", "Several with vars: %s", 2, v)
-	if tr != "And this is the second plural form: Variable" {
-		t.Errorf("Expected 'And this is the second plural form: Variable' but got '%s'", tr)
+	if tr != "This one is the plural: Variable" {
+		t.Errorf("Expected 'This one is the plural: Variable' but got '%s'", tr)
 	}
 
 	// Test inexistent translations
@@ -105,7 +110,7 @@ msgstr "More translation"
 		t.Errorf("Expected '' but got '%s'", tr)
 	}
 
-	tr = po.GetN("This one has invalid syntax translations", "This are tests", 1)
+	tr = po.GetN("This one has invalid syntax translations", "This are tests", 2)
 	if tr != "Plural index" {
 		t.Errorf("Expected 'Plural index' but got '%s'", tr)
 	}
@@ -118,7 +123,7 @@ msgstr "More translation"
 	}
 
 	// Test plural
-	tr = po.GetNC("One with var: %s", "Several with vars: %s", 1, "Ctx", v)
+	tr = po.GetNC("One with var: %s", "Several with vars: %s", 2, "Ctx", v)
 	if tr != "This one is the plural in a Ctx context: Test" {
 		t.Errorf("Expected 'This one is the plural in a Ctx context: Test' but got '%s'", tr)
 	}
@@ -132,7 +137,7 @@ msgstr "More translation"
 }
 
 func TestTranslationObject(t *testing.T) {
-	tr := newTranslation()
+	tr := NewTranslation()
 	str := tr.get()
 
 	if str != "" {
@@ -140,7 +145,7 @@ func TestTranslationObject(t *testing.T) {
 	}
 
 	// Set id
-	tr.id = "Text"
+	tr.ID = "Text"
 
 	// Get again
 	str = tr.get()
@@ -194,3 +199,816 @@ msgstr[2] "And this is the second plural form: %s"
 	<-pc
 	<-rc
 }
+
+func TestCountVerbs(t *testing.T) {
+	for format, want := range map[string]int{
+		"":                    0,
+		"No verbs":            0,
+		"100%% free":          0,
+		"Hello %s":            1,
+		"%d of %d":            2,
+		"%-10s|%5.2f|%+d":     3,
+		"%[2]s before %[1]s":  2,
+		"%[3]d":               3,
+		"%*d":                 2,
+		"Trailing percent %":  0,
+		"Unclosed %[2 index":  0,
+		"Mixed %s, %%, %v %x": 3,
+	} {
+		if got := countVerbs(format); got != want {
+			t.Errorf("Expected %d verbs on '%s' but got %d", want, format, got)
+		}
+	}
+}
+
+func TestPoCollapseWhitespace(t *testing.T) {
+	// Set PO content
+	str := `
+msgid "Hello world"
+msgstr "Hola mundo"
+
+msgid "Some   spaced	text"
+msgstr "Texto espaciado"
+
+msgctxt "Ctx"
+msgid "Hello  world"
+msgstr "Hola mundo en un contexto"
+    `
+
+	// Create po object
+	po := new(Po)
+	po.Parse(str)
+
+	// Test default behaviour
+	tr := po.Get("Hello  world")
+	if tr != "Hello  world" {
+		t.Errorf("Expected 'Hello  world' but got '%s'", tr)
+	}
+
+	// Enable collapsing on parsed entries
+	po.SetCollapseWhitespace(true)
+
+	tr = po.Get("Hello  world")
+	if tr != "Hola mundo" {
+		t.Errorf("Expected 'Hola mundo' but got '%s'", tr)
+	}
+
+	tr = po.Get("Some spaced \t text")
+	if tr != "Texto espaciado" {
+		t.Errorf("Expected 'Texto espaciado' but got '%s'", tr)
+	}
+
+	tr = po.GetC("Hello world", "Ctx")
+	if tr != "Hola mundo en un contexto" {
+		t.Errorf("Expected 'Hola mundo en un contexto' but got '%s'", tr)
+	}
+
+	// Test no trimming
+	tr = po.Get(" Hello world")
+	if tr != " Hello world" {
+		t.Errorf("Expected ' Hello world' but got '%s'", tr)
+	}
+
+	// Test entries parsed after enabling
+	po = new(Po)
+	po.SetCollapseWhitespace(true)
+	po.Parse(str)
+
+	tr = po.Get("Some spaced text")
+	if tr != "Texto espaciado" {
+		t.Errorf("Expected 'Texto espaciado' but got '%s'", tr)
+	}
+
+	// Disable collapsing
+	po.SetCollapseWhitespace(false)
+
+	tr = po.Get("Some spaced text")
+	if tr != "Some spaced text" {
+		t.Errorf("Expected 'Some spaced text' but got '%s'", tr)
+	}
+
+	tr = po.Get("Some   spaced	text")
+	if tr != "Texto espaciado" {
+		t.Errorf("Expected 'Texto espaciado' but got '%s'", tr)
+	}
+}
+
+func TestPoContinuationLines(t *testing.T) {
+	// Set PO content with multiline strings, some of them indented
+	str := `
+msgid ""
+"My "
+"text"
+msgstr ""
+"Translated "
+"text"
+
+msgid "Indented"
+msgstr ""
+	"Indented with "
+	"tabs"
+
+msgid "One with var: %s"
+msgid_plural ""
+    "Several with "
+    "vars: %s"
+msgstr[0] ""
+    "This one is the singular: %s"
+msgstr[1] "This one is "
+    "the plural: %s"
+
+msgctxt ""
+  "Long "
+  "Ctx"
+msgid "Some random in a context"
+msgstr "Some random "
+	"translation in a context"
+
+msgid "Badly formatted continuation"
+msgstr "Good part"
+	"Bad part'
+	" and more"
+    `
+
+	// Create po object
+	po := new(Po)
+	po.Parse(str)
+
+	tr := po.Get("My text")
+	if tr != "Translated text" {
+		t.Errorf("Expected 'Translated text' but got '%s'", tr)
+	}
+
+	tr = po.Get("Indented")
+	if tr != "Indented with tabs" {
+		t.Errorf("Expected 'Indented with tabs' but got '%s'", tr)
+	}
+
+	v := "Variable"
+	tr = po.GetN("One with var: %s", "Several with vars: %s", 1, v)
+	if tr != "This one is the singular: Variable" {
+		t.Errorf("Expected 'This one is the singular: Variable' but got '%s'", tr)
+	}
+
+	tr = po.GetN("One with var: %s", "Several with vars: %s", 5, v)
+	if tr != "This one is the plural: Variable" {
+		t.Errorf("Expected 'This one is the plural: Variable' but got '%s'", tr)
+	}
+
+	if e := po.GetEntry("One with var: %s"); e == nil || e.PluralID != "Several with vars: %s" {
+		t.Errorf("Expected 'Several with vars: %%s' msgid_plural but got %v", e)
+	}
+
+	tr = po.GetC("Some random in a context", "Long Ctx")
+	if tr != "Some random translation in a context" {
+		t.Errorf("Expected 'Some random translation in a context' but got '%s'", tr)
+	}
+
+	tr = po.Get("Badly formatted continuation")
+	if tr != "Good part and more" {
+		t.Errorf("Expected 'Good part and more' but got '%s'", tr)
+	}
+}
+
+func TestPoEntryLine(t *testing.T) {
+	// Set PO content
+	str := `# Some comment
+msgid "My text"
+msgstr "Translated text"
+
+msgid ""
+"Multiline "
+"text"
+msgstr "Translated multiline text"
+
+#, args:1
+msgctxt "Ctx"
+msgid "One with var: %s"
+msgid_plural "Several with vars: %s"
+msgstr[0] "This one is the singular in a Ctx context: %s"
+msgstr[1] "This one is the plural in a Ctx context: %s"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	e := po.GetEntry("My text")
+	if e == nil {
+		t.Fatal("Expected entry for 'My text'")
+	}
+	if e.Line != 2 {
+		t.Errorf("Expected entry on line 2 but got %d", e.Line)
+	}
+
+	e = po.GetEntry("Multiline text")
+	if e == nil {
+		t.Fatal("Expected entry for 'Multiline text'")
+	}
+	if e.Line != 5 {
+		t.Errorf("Expected entry on line 5 but got %d", e.Line)
+	}
+
+	e = po.GetEntryC("One with var: %s", "Ctx")
+	if e == nil {
+		t.Fatal("Expected entry for 'One with var' in 'Ctx' context")
+	}
+	if e.Line != 12 {
+		t.Errorf("Expected entry on line 12 but got %d", e.Line)
+	}
+	if e.PluralID != "Several with vars: %s" {
+		t.Errorf("Expected plural id 'Several with vars: %%s' but got '%s'", e.PluralID)
+	}
+
+	// Test the entry is a copy
+	e.Trs[0] = "Changed"
+
+	tr := po.GetC("One with var: %s", "Ctx", "Test")
+	if tr != "This one is the singular in a Ctx context: Test" {
+		t.Errorf("Expected 'This one is the singular in a Ctx context: Test' but got '%s'", tr)
+	}
+
+	// Test inexistent entries
+	if po.GetEntry("This is a test") != nil {
+		t.Error("Expected nil entry for inexistent string")
+	}
+	if po.GetEntryC("My text", "Ctx") != nil {
+		t.Error("Expected nil entry for inexistent context")
+	}
+}
+
+func TestPoFlags(t *testing.T) {
+	// Set PO content
+	str := `#, c-format, reviewed
+msgid "One with var: %s"
+msgstr "This one is with var: %s"
+
+#, range: 0..10
+#, no-wrap
+msgid "Count %d"
+msgid_plural "Counts %d"
+msgstr[0] "Cuenta %d"
+msgstr[1] "Cuentas %d"
+
+msgid "No flags"
+msgstr "Sin flags"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	e := po.GetEntry("One with var: %s")
+	if e == nil {
+		t.Fatal("Expected entry for 'One with var'")
+	}
+	for _, flag := range []string{"c-format", "reviewed"} {
+		if !e.HasFlag(flag) {
+			t.Errorf("Expected entry to have the '%s' flag", flag)
+		}
+	}
+	if e.HasFlag("fuzzy") {
+		t.Error("Expected entry not to have the 'fuzzy' flag")
+	}
+
+	e = po.GetEntry("Count %d")
+	if e == nil {
+		t.Fatal("Expected entry for 'Count'")
+	}
+	if !e.HasFlag("range") || !e.HasFlag("no-wrap") {
+		t.Errorf("Expected entry to have the 'range' and 'no-wrap' flags, but got %v", e.Flags)
+	}
+	if v, _ := e.flagValue("range"); v != "0..10" {
+		t.Errorf("Expected range flag value '0..10' but got '%s'", v)
+	}
+
+	e = po.GetEntry("No flags")
+	if e == nil {
+		t.Fatal("Expected entry for 'No flags'")
+	}
+	if len(e.Flags) != 0 {
+		t.Errorf("Expected no flags but got %v", e.Flags)
+	}
+
+	// Test flags round trip
+	var buf bytes.Buffer
+	err := po.Write(&buf, WriteOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	expected := `#, c-format, reviewed
+msgid "One with var: %s"
+msgstr "This one is with var: %s"
+
+#, range: 0..10, no-wrap
+msgid "Count %d"
+msgid_plural "Counts %d"
+msgstr[0] "Cuenta %d"
+msgstr[1] "Cuentas %d"
+
+msgid "No flags"
+msgstr "Sin flags"
+`
+
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, buf.String())
+	}
+}
+
+func TestPoLoadWarnHandler(t *testing.T) {
+	// Set PO content
+	str := `msgid ""
+msgstr ""
+"Language: es\n"
+
+msgid "My text"
+msgstr "Mi texto"
+
+msgid "Untranslated"
+msgstr ""
+
+#, fuzzy
+msgid "Fuzzy text"
+msgstr "Texto difuso"
+
+#, fuzzy
+msgid "Untranslated fuzzy"
+msgstr ""
+
+msgctxt "Ctx"
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Un archivo"
+msgstr[1] ""
+msgstr[2] ""
+`
+
+	// Parse collecting warnings
+	var warnings []string
+
+	po := new(Po)
+	po.SetLoadWarnHandler(func(w Warning) {
+		warnings = append(warnings, w.String())
+	})
+	po.Parse(str)
+
+	expected := []string{
+		`line 8: msgid "Untranslated": untranslated entry`,
+		`line 12: msgid "Fuzzy text": fuzzy entry`,
+		`line 16: msgid "Untranslated fuzzy": untranslated entry`,
+		`line 20: msgctxt "Ctx" msgid "One file" (msgstr[1]): untranslated plural form`,
+		`line 20: msgctxt "Ctx" msgid "One file" (msgstr[2]): untranslated plural form`,
+	}
+
+	if len(warnings) != len(expected) {
+		t.Fatalf("Expected %d warnings but got %d: %v", len(expected), len(warnings), warnings)
+	}
+
+	for i, w := range warnings {
+		if w != expected[i] {
+			t.Errorf("Expected warning '%s' but got '%s'", expected[i], w)
+		}
+	}
+
+	// Remove handler
+	po.SetLoadWarnHandler(nil)
+	po.Parse(str)
+
+	if len(warnings) != len(expected) {
+		t.Errorf("Expected no more warnings after removing the handler but got %v", warnings[len(expected):])
+	}
+}
+
+func TestPoObsolete(t *testing.T) {
+	// Set PO content
+	str := `
+msgid "My text"
+msgstr "Translated text"
+
+#, fuzzy
+#~| msgid "Previous text"
+#~ msgctxt "Ctx"
+#~ msgid "Old text"
+#~ msgstr ""
+#~ "Old translated text"
+
+#~ msgid "One file"
+#~ msgid_plural "%d files"
+#~ msgstr[0] "One old file"
+#~ msgstr[1] "%d old files"
+
+msgid "Another text"
+msgstr "Another translated text"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	// Obsolete entries aren't used
+	if tr := po.GetC("Old text", "Ctx"); tr != "Old text" {
+		t.Errorf("Expected 'Old text' but got '%s'", tr)
+	}
+
+	if tr := po.Get("Another text"); tr != "Another translated text" {
+		t.Errorf("Expected 'Another translated text' but got '%s'", tr)
+	}
+
+	if e := po.GetEntry("Another text"); e == nil || len(e.Flags) != 0 {
+		t.Errorf("Expected obsolete flags to be kept apart but got %v", e)
+	}
+
+	obsolete := po.Obsolete()
+	if len(obsolete) != 2 {
+		t.Fatalf("Expected 2 obsolete entries but got %d", len(obsolete))
+	}
+
+	if e := obsolete[0]; e.Context != "Ctx" || e.ID != "Old text" || e.Trs[0] != "Old translated text" || !e.HasFlag("fuzzy") {
+		t.Errorf("Unexpected obsolete entry %v", e)
+	}
+
+	if e := obsolete[1]; e.ID != "One file" || e.PluralID != "%d files" || e.Trs[1] != "%d old files" {
+		t.Errorf("Unexpected obsolete entry %v", e)
+	}
+
+	// Returned entries are copies
+	obsolete[0].Trs[0] = "Changed"
+	if po.Obsolete()[0].Trs[0] != "Old translated text" {
+		t.Error("Expected obsolete entries to be copies")
+	}
+}
+
+func TestPoDefaultContext(t *testing.T) {
+	// Set PO content
+	str := `
+msgid "My text"
+msgstr "Context-less text"
+
+msgid "Only context-less"
+msgstr "Only context-less text"
+
+msgctxt "app"
+msgid "My text"
+msgstr "App text"
+
+msgctxt "app"
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "One app file"
+msgstr[1] "%d app files"
+
+msgctxt "other"
+msgid "My text"
+msgstr "Other text"
+`
+
+	po := new(Po)
+	po.Parse(str)
+	po.SetDefaultContext("app")
+
+	if tr := po.Get("My text"); tr != "App text" {
+		t.Errorf("Expected 'App text' but got '%s'", tr)
+	}
+
+	if tr := po.GetN("One file", "%d files", 3, 3); tr != "3 app files" {
+		t.Errorf("Expected '3 app files' but got '%s'", tr)
+	}
+
+	if tr := po.Get("Only context-less"); tr != "Only context-less text" {
+		t.Errorf("Expected 'Only context-less text' but got '%s'", tr)
+	}
+
+	// Explicit context
+	if tr := po.GetC("My text", "other"); tr != "Other text" {
+		t.Errorf("Expected 'Other text' but got '%s'", tr)
+	}
+
+	// Through a Locale
+	l := NewLocale("/tmp", "en_US")
+	l.AttachDomain("default", po)
+
+	if tr := l.Get("My text"); tr != "App text" {
+		t.Errorf("Expected 'App text' but got '%s'", tr)
+	}
+
+	// Remove default context
+	po.SetDefaultContext("")
+
+	if tr := po.Get("My text"); tr != "Context-less text" {
+		t.Errorf("Expected 'Context-less text' but got '%s'", tr)
+	}
+}
+
+func TestPoParseReader(t *testing.T) {
+	// Set PO content
+	str := `
+msgid "My text"
+msgstr "Mi texto"
+
+msgctxt "Ctx"
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Un archivo"
+msgstr[1] "%d archivos"
+`
+
+	po := new(Po)
+	if err := po.ParseReader(strings.NewReader(str)); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	if tr := po.Get("My text"); tr != "Mi texto" {
+		t.Errorf("Expected 'Mi texto' but got '%s'", tr)
+	}
+
+	if tr := po.GetNC("One file", "%d files", 2, "Ctx", 2); tr != "2 archivos" {
+		t.Errorf("Expected '2 archivos' but got '%s'", tr)
+	}
+
+	if po.Fingerprint() != SourceFingerprint(str) {
+		t.Errorf("Expected fingerprint of the parsed content but got '%s'", po.Fingerprint())
+	}
+
+	// Read errors
+	po = new(Po)
+	if err := po.ParseReader(iotest.TimeoutReader(strings.NewReader(str))); err == nil {
+		t.Error("Expected read error")
+	}
+}
+
+func TestPoTranslationInfo(t *testing.T) {
+	// Set PO content
+	str := `
+# Translator comment
+#
+#. Extracted comment
+#: main.go:12 util.go:30
+#: templates/index.html:4
+#, fuzzy
+#| msgid "Old text"
+msgid "My text"
+msgstr "Mi texto"
+
+#. Menu entry
+#: menu.go:8
+msgctxt "Menu"
+msgid "Open"
+msgstr "Abrir"
+
+msgid "No comments"
+msgstr "Sin comentarios"
+
+# Obsolete comment
+#~ msgid "Old text"
+#~ msgstr "Texto viejo"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	// Get is unchanged, ignoring the fuzzy entry
+	if tr := po.Get("My text"); tr != "My text" {
+		t.Errorf("Expected 'My text' but got '%s'", tr)
+	}
+
+	info := po.GetTranslationInfo("My text")
+	if strings.Join(info.References, ",") != "main.go:12,util.go:30,templates/index.html:4" {
+		t.Errorf("Unexpected references: %v", info.References)
+	}
+	if len(info.ExtractedComments) != 1 || info.ExtractedComments[0] != "Extracted comment" {
+		t.Errorf("Unexpected extracted comments: %v", info.ExtractedComments)
+	}
+	if len(info.Comments) != 2 || info.Comments[0] != "Translator comment" || info.Comments[1] != "" {
+		t.Errorf("Unexpected comments: %q", info.Comments)
+	}
+	if len(info.Flags) != 1 || info.Flags[0] != "fuzzy" {
+		t.Errorf("Unexpected flags: %v", info.Flags)
+	}
+
+	// Context
+	info = po.GetTranslationInfoC("Open", "Menu")
+	if len(info.References) != 1 || info.References[0] != "menu.go:8" {
+		t.Errorf("Unexpected references: %v", info.References)
+	}
+	if len(info.ExtractedComments) != 1 || info.ExtractedComments[0] != "Menu entry" {
+		t.Errorf("Unexpected extracted comments: %v", info.ExtractedComments)
+	}
+
+	// Entries without comments
+	info = po.GetTranslationInfo("No comments")
+	if info.References != nil || info.ExtractedComments != nil || info.Comments != nil || info.Flags != nil {
+		t.Errorf("Unexpected info: %+v", info)
+	}
+
+	// Missing entry
+	info = po.GetTranslationInfo("Missing")
+	if info.References != nil || info.Comments != nil {
+		t.Errorf("Unexpected info: %+v", info)
+	}
+
+	// Changes to the returned info don't affect the catalog
+	info = po.GetTranslationInfo("My text")
+	info.References[0] = "changed.go:1"
+	if po.GetTranslationInfo("My text").References[0] != "main.go:12" {
+		t.Error("Catalog changed through the returned info")
+	}
+
+	// Obsolete entries keep their comments
+	obs := po.Obsolete()
+	if len(obs) != 1 || len(obs[0].Comments) != 1 || obs[0].Comments[0] != "Obsolete comment" {
+		t.Errorf("Unexpected obsolete entries: %+v", obs)
+	}
+}
+
+func TestPoFuzzy(t *testing.T) {
+	// Set PO content
+	str := `#, fuzzy
+msgid ""
+msgstr "Language: es\n"
+
+#, fuzzy, c-format
+msgid "My text"
+msgstr "Mi texto"
+
+#, c-format,fuzzy
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Un archivo"
+msgstr[1] "%d archivos"
+
+#, fuzzy
+msgctxt "Ctx"
+msgid "Some random in a context"
+msgstr "Algo aleatorio en un contexto"
+
+#, c-format
+msgid "Reviewed"
+msgstr "Revisado"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	// Fuzzy entries are ignored by default
+	if tr := po.Get("My text"); tr != "My text" {
+		t.Errorf("Expected 'My text' but got '%s'", tr)
+	}
+	if tr := po.GetN("One file", "%d files", 5, 5); tr != "5 files" {
+		t.Errorf("Expected '5 files' but got '%s'", tr)
+	}
+	if tr := po.GetC("Some random in a context", "Ctx"); tr != "Some random in a context" {
+		t.Errorf("Expected 'Some random in a context' but got '%s'", tr)
+	}
+	if tr := po.Get("Reviewed"); tr != "Revisado" {
+		t.Errorf("Expected 'Revisado' but got '%s'", tr)
+	}
+
+	// Entries are still available
+	if e := po.GetEntry("My text"); e == nil || e.Trs[0] != "Mi texto" {
+		t.Errorf("Expected fuzzy entry but got %v", e)
+	}
+
+	// Fuzzy flags
+	for _, str := range []string{"My text", "One file"} {
+		if !po.IsFuzzy(str) {
+			t.Errorf("Expected '%s' to be fuzzy", str)
+		}
+	}
+	for _, str := range []string{"Reviewed", "Missing", "Some random in a context"} {
+		if po.IsFuzzy(str) {
+			t.Errorf("Expected '%s' not to be fuzzy", str)
+		}
+	}
+	if !po.IsFuzzyC("Some random in a context", "Ctx") || po.IsFuzzyC("Some random in a context", "Other") {
+		t.Error("Expected only the entry in the 'Ctx' context to be fuzzy")
+	}
+
+	// Enable fuzzy entries
+	po.SetFuzzyEnabled(true)
+
+	if tr := po.Get("My text"); tr != "Mi texto" {
+		t.Errorf("Expected 'Mi texto' but got '%s'", tr)
+	}
+	if tr := po.GetN("One file", "%d files", 5, 5); tr != "5 archivos" {
+		t.Errorf("Expected '5 archivos' but got '%s'", tr)
+	}
+	if tr := po.GetNC("Some random in a context", "", 1, "Ctx"); tr != "Algo aleatorio en un contexto" {
+		t.Errorf("Expected 'Algo aleatorio en un contexto' but got '%s'", tr)
+	}
+}
+
+func TestPoParseFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"es/default.po": {Data: []byte("msgid \"My text\"\nmsgstr \"Mi texto\"\n")},
+	}
+
+	po := new(Po)
+	if err := po.ParseFS(fsys, "es/default.po"); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	if tr := po.Get("My text"); tr != "Mi texto" {
+		t.Errorf("Expected 'Mi texto' but got '%s'", tr)
+	}
+
+	// Missing file
+	if err := new(Po).ParseFS(fsys, "es/missing.po"); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+func TestPoTranslations(t *testing.T) {
+	// Set PO content
+	str := `
+msgid ""
+msgstr "Language: es\n"
+
+msgid "My text"
+msgstr "Mi texto"
+
+msgctxt "Ctx"
+msgid "My text"
+msgstr "Mi texto en un contexto"
+
+#, fuzzy
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Un archivo"
+msgstr[1] "%d archivos"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	trs := po.Translations()
+	if len(trs) != 3 {
+		t.Fatalf("Expected 3 translations but got %d", len(trs))
+	}
+
+	// Catalog order
+	if trs[0].ID != "My text" || trs[0].Context != "" || trs[0].Trs[0] != "Mi texto" {
+		t.Errorf("Unexpected first entry: %v", trs[0])
+	}
+	if trs[1].ID != "My text" || trs[1].Context != "Ctx" || trs[1].Trs[0] != "Mi texto en un contexto" {
+		t.Errorf("Unexpected second entry: %v", trs[1])
+	}
+	if trs[2].PluralID != "%d files" || trs[2].Trs[1] != "%d archivos" || !trs[2].HasFlag("fuzzy") {
+		t.Errorf("Unexpected third entry: %v", trs[2])
+	}
+
+	// Changes don't affect the catalog
+	trs[0].Trs[0] = "Changed"
+	if tr := po.Get("My text"); tr != "Mi texto" {
+		t.Errorf("Expected 'Mi texto' but got '%s'", tr)
+	}
+
+	// Locale domains
+	l := NewLocale("/tmp", "es")
+	l.AttachDomain("extras", po)
+
+	if trs := l.Translations("extras"); len(trs) != 3 {
+		t.Errorf("Expected 3 translations but got %d", len(trs))
+	}
+	if trs := l.Translations("missing"); trs != nil {
+		t.Errorf("Expected nil translations but got %v", trs)
+	}
+}
+
+func TestPoSetEntryConcurrent(t *testing.T) {
+	// Set PO content
+	str := `
+msgid "First"
+msgstr "Primero"
+
+msgid "Second"
+msgstr "Segundo"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	// Replaced entries keep their position while the catalog is written
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				po.Set("First", "Primero")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				trs := po.Translations()
+				if len(trs) != 2 || trs[0].ID != "First" {
+					t.Errorf("Unexpected translations order: %v", trs)
+					return
+				}
+				if _, err := po.MarshalText(); err != nil {
+					t.Errorf("Unexpected error: %s", err.Error())
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
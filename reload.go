@@ -0,0 +1,331 @@
+package gotext
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+ReloadDomain parses again the file the given domain (dom) was loaded from, like Reload does,
+and replaces the loaded domain with the new content,
+only if the file can be read, its syntax is valid and the optional validator (validate) accepts it.
+It returns an error if the domain wasn't loaded from a file, like the attached ones.
+
+The new content is parsed into a separate Po object, so the loaded domain keeps working during the reload
+and is swapped in a single step once the new one is accepted.
+On any failure the loaded domain is kept untouched and the error is returned,
+so a broken deploy can't blank the translations of a running application:
+
+    err := l.ReloadDomain("default", func(po *gotext.Po) error {
+        if po.Get("Welcome") == "" {
+            return errors.New("missing welcome message")
+        }
+        return nil
+    })
+    if err != nil {
+        log.Printf("Keeping previous translations: %s", err)
+    }
+
+Unlike AddDomain, which ignores badly formatted lines, any syntax error rejects a new PO file,
+while MO, JSON and XLIFF files are rejected when they can't be loaded.
+Use nil as validator to only check the file syntax.
+*/
+func (l *Locale) ReloadDomain(dom string, validate func(*Po) error) error {
+	l.RLock()
+	filename, ok := l.files[dom]
+	if d := l.pending[dom]; d != nil {
+		filename, ok = d.filename, true
+	}
+	l.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("domain %q wasn't loaded from a file", dom)
+	}
+
+	po, err := l.reloadPo(filename)
+	if err != nil {
+		return err
+	}
+
+	// Validate
+	if validate != nil {
+		if err = validate(po); err != nil {
+			return err
+		}
+	}
+
+	// Swap domain
+	l.setDomain(dom, filename, po)
+
+	return nil
+}
+
+/*
+Reload parses again the file of every domain loaded with AddDomain or AddDomainsGlob, from the same path it was loaded from,
+and replaces each loaded domain with its new content.
+
+Each file is parsed into a separate Po object that is swapped in a single step,
+so concurrent calls to the Get* methods always see either the whole previous catalog or the whole new one.
+Domains whose file can't be read, or isn't a valid MO or JSON file, keep their loaded content and their errors are returned.
+Domains attached with AttachDomain or loaded from a tar archive aren't reloaded,
+nor the ones registered on lazy mode that weren't looked up yet, as their files are read on their first lookup.
+The fallbacks created along with this Locale by NewLocaleWithFallback are reloaded as well.
+*/
+func (l *Locale) Reload() []error {
+	var errs []error
+
+	for _, loc := range l.ownChain() {
+		for _, dom := range loc.fileDomains() {
+			if err := loc.reloadFile(dom); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errs
+}
+
+/*
+Watch checks every given interval (d) the modification time and size of the files loaded by Reload,
+and reloads the domains whose file has changed, in a separate goroutine.
+It returns the function that stops watching the files, which waits for any reload in progress to finish.
+
+    stop := l.Watch(5*time.Second, func(dom string, err error) {
+        log.Printf("Keeping previous %s translations: %s", dom, err)
+    })
+    defer stop()
+
+When a changed file can't be reloaded, the loaded domain is kept and the error is passed to the optional handler (h),
+along with the domain name. The file is tried again only after it changes again.
+Files should be replaced in a single step, like renaming a temporary file, so they aren't read while partially written.
+Like Reload, the fallbacks created by NewLocaleWithFallback are watched as well.
+A non-positive interval doesn't watch the files at all, returning a stop function that does nothing.
+*/
+func (l *Locale) Watch(d time.Duration, h func(dom string, err error)) (stop func()) {
+	if d <= 0 {
+		return func() {}
+	}
+
+	done, finished := make(chan struct{}), make(chan struct{})
+	ticker := time.NewTicker(d)
+
+	// Current state of each file
+	state := make(map[watchKey]string)
+	for _, loc := range l.ownChain() {
+		for _, dom := range loc.fileDomains() {
+			state[watchKey{loc, dom}] = loc.fileState(dom)
+		}
+	}
+
+	go func() {
+		defer close(finished)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+
+			case <-ticker.C:
+				for _, loc := range l.ownChain() {
+					for _, dom := range loc.fileDomains() {
+						// Domains added after the first check are only watched from now on
+						k := watchKey{loc, dom}
+						current := loc.fileState(dom)
+						prev, ok := state[k]
+						state[k] = current
+
+						if !ok || prev == current {
+							continue
+						}
+
+						if err := loc.reloadFile(dom); err != nil && h != nil {
+							h(dom, err)
+						}
+					}
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-finished
+		})
+	}
+}
+
+// watchKey identifies a domain of a Locale watched by Watch.
+type watchKey struct {
+	l   *Locale
+	dom string
+}
+
+// fileDomains returns the sorted names of the domains loaded from a file.
+func (l *Locale) fileDomains() []string {
+	l.RLock()
+	defer l.RUnlock()
+
+	doms := make([]string, 0, len(l.files))
+	for dom := range l.files {
+		doms = append(doms, dom)
+	}
+	sort.Strings(doms)
+
+	return doms
+}
+
+// fileState returns a string describing the modification time and size of the file the given domain (dom) was loaded from,
+// or an empty string if it can't be read.
+func (l *Locale) fileState(dom string) string {
+	l.RLock()
+	filename := l.files[dom]
+	l.RUnlock()
+
+	info, err := l.stat(filename)
+	if err != nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%d:%d", info.ModTime().UnixNano(), info.Size())
+}
+
+// reloadFile parses again the file the given domain (dom) was loaded from and swaps the loaded domain with the new content.
+func (l *Locale) reloadFile(dom string) error {
+	l.RLock()
+	filename, ok := l.files[dom]
+	l.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	po, err := l.loadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	l.setDomain(dom, filename, po)
+
+	return nil
+}
+
+// reloadPo parses the given domain file (filename) into a new Po object for ReloadDomain.
+// PO files are rejected on any syntax error, while the other formats return their load errors.
+func (l *Locale) reloadPo(filename string) (*Po, error) {
+	if ext := path.Ext(filename); ext == ".mo" || ext == ".json" || ext == ".xlf" || ext == ".xliff" {
+		return l.loadFile(filename)
+	}
+
+	// Read file content
+	data, err := l.readFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check syntax
+	if err = checkSyntax(string(data)); err != nil {
+		return nil, fmt.Errorf("%s:%s", filename, err.Error())
+	}
+
+	// Parse into a new Po object
+	po := l.newPo()
+	if err = po.parse(string(data)); err != nil {
+		return nil, fmt.Errorf("%s: %s", filename, err.Error())
+	}
+
+	return po, nil
+}
+
+// checkSyntax looks for the lines of a PO formatted string (str) that Po.Parse would skip or misread
+// and returns an error describing the first one found, prefixed by its line number.
+func checkSyntax(str string) error {
+	// Last keyword read, used to check continuation lines and keywords order
+	field := ""
+
+	for n, l := range strings.Split(str, "\n") {
+		l = strings.TrimSpace(l)
+
+		// Skip empty lines and comments
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+
+		// Continuation lines need a previous keyword
+		if strings.HasPrefix(l, "\"") {
+			if field == "" {
+				return fmt.Errorf("%d: string without keyword", n+1)
+			}
+			if _, err := strconv.Unquote(l); err != nil {
+				return fmt.Errorf("%d: badly formatted string", n+1)
+			}
+
+			continue
+		}
+
+		// Split keyword and value
+		keyword, value := l, ""
+		if i := strings.IndexAny(l, " \t"); i != -1 {
+			keyword, value = l[:i], strings.TrimSpace(l[i:])
+		}
+
+		// Split msgstr index
+		if strings.HasPrefix(keyword, "msgstr[") {
+			in := strings.Index(l, "]")
+			if in == -1 {
+				return fmt.Errorf("%d: unclosed msgstr index", n+1)
+			}
+			if _, err := strconv.Atoi(l[len("msgstr["):in]); err != nil {
+				return fmt.Errorf("%d: invalid msgstr index", n+1)
+			}
+
+			keyword, value = "msgstr", strings.TrimSpace(l[in+1:])
+		}
+
+		// Check keywords order
+		switch keyword {
+		case "msgctxt":
+			if field == "msgctxt" || field == "msgid" || field == "msgid_plural" {
+				return fmt.Errorf("%d: msgctxt without msgstr on the previous entry", n+1)
+			}
+
+		case "msgid":
+			if field == "msgid" || field == "msgid_plural" {
+				return fmt.Errorf("%d: msgid without msgstr on the previous entry", n+1)
+			}
+
+		case "msgid_plural":
+			if field != "msgid" {
+				return fmt.Errorf("%d: msgid_plural without msgid", n+1)
+			}
+
+		case "msgstr":
+			if field == "" || field == "msgctxt" {
+				return fmt.Errorf("%d: msgstr without msgid", n+1)
+			}
+
+		default:
+			return fmt.Errorf("%d: unknown keyword %q", n+1, keyword)
+		}
+
+		if _, err := strconv.Unquote(value); err != nil {
+			return fmt.Errorf("%d: badly formatted string", n+1)
+		}
+
+		field = keyword
+	}
+
+	// Check last entry
+	if field == "msgctxt" || field == "msgid" || field == "msgid_plural" {
+		return fmt.Errorf("%d: %s without msgstr at end of file", strings.Count(str, "\n")+1, field)
+	}
+
+	return nil
+}
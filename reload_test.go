@@ -0,0 +1,384 @@
+package gotext
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocaleReloadDomain(t *testing.T) {
+	// Create Locale directory
+	dirname := path.Clean("/tmp" + string(os.PathSeparator) + "en_US")
+	err := os.MkdirAll(dirname, os.ModePerm)
+	if err != nil {
+		t.Fatalf("Can't create test directory: %s", err.Error())
+	}
+
+	filename := path.Clean(dirname + string(os.PathSeparator) + "reload.po")
+
+	write := func(str string) {
+		if err := ioutil.WriteFile(filename, []byte(str), 0644); err != nil {
+			t.Fatalf("Can't write to test file: %s", err.Error())
+		}
+	}
+
+	// Load initial content
+	write(`
+msgid "My text"
+msgstr "Translated text"
+`)
+
+	l := NewLocale("/tmp", "en_US")
+	l.AddDomain("reload")
+
+	// Deploy a broken file
+	write(`
+msgid "My text"
+msgstr "Broken text
+`)
+
+	err = l.ReloadDomain("reload", nil)
+	if err == nil {
+		t.Error("Expected error reloading a broken file")
+	} else if !strings.HasSuffix(err.Error(), ":3: badly formatted string") {
+		t.Errorf("Unexpected error message: %s", err.Error())
+	}
+
+	tr := l.GetD("reload", "My text")
+	if tr != "Translated text" {
+		t.Errorf("Expected 'Translated text' but got '%s'", tr)
+	}
+
+	// Deploy a valid file rejected by the validator
+	write(`
+msgid "My text"
+msgstr ""
+`)
+
+	err = l.ReloadDomain("reload", func(po *Po) error {
+		if po.Get("My text") == "" {
+			return errors.New("untranslated")
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "untranslated" {
+		t.Errorf("Expected validator error but got %v", err)
+	}
+
+	tr = l.GetD("reload", "My text")
+	if tr != "Translated text" {
+		t.Errorf("Expected 'Translated text' but got '%s'", tr)
+	}
+
+	// Deploy a valid file
+	write(`
+msgid "My text"
+msgstr "New translated text"
+`)
+
+	err = l.ReloadDomain("reload", nil)
+	if err != nil {
+		t.Errorf("Unexpected error reloading a valid file: %s", err.Error())
+	}
+
+	tr = l.GetD("reload", "My text")
+	if tr != "New translated text" {
+		t.Errorf("Expected 'New translated text' but got '%s'", tr)
+	}
+
+	// Remove the file
+	os.Remove(filename)
+
+	err = l.ReloadDomain("reload", nil)
+	if err == nil {
+		t.Error("Expected error reloading a missing file")
+	}
+
+	tr = l.GetD("reload", "My text")
+	if tr != "New translated text" {
+		t.Errorf("Expected 'New translated text' but got '%s'", tr)
+	}
+
+	// Domains are reloaded from the file they were loaded from
+	others := map[string]string{
+		"reload-json.json":               `{"My text": "JSON text"}`,
+		"LC_MESSAGES/reload-messages.po": "msgid \"My text\"\nmsgstr \"Messages text\"\n",
+	}
+	for name, str := range others {
+		os.MkdirAll(path.Dir(path.Join(dirname, name)), os.ModePerm)
+		if err := ioutil.WriteFile(path.Join(dirname, name), []byte(str), 0644); err != nil {
+			t.Fatalf("Can't write to test file: %s", err.Error())
+		}
+	}
+	os.Remove(path.Join(dirname, "reload-messages.po"))
+
+	l.AddDomain("reload-json")
+	if errs := l.AddDomainsGlob("LC_MESSAGES/reload-messages.po"); len(errs) != 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+
+	ioutil.WriteFile(path.Join(dirname, "reload-json.json"), []byte(`{"My text": "New JSON text"}`), 0644)
+	ioutil.WriteFile(path.Join(dirname, "LC_MESSAGES/reload-messages.po"), []byte("msgid \"My text\"\nmsgstr \"New messages text\"\n"), 0644)
+
+	for dom, expected := range map[string]string{"reload-json": "New JSON text", "reload-messages": "New messages text"} {
+		if err := l.ReloadDomain(dom, nil); err != nil {
+			t.Errorf("Unexpected error reloading %s: %s", dom, err.Error())
+		}
+		if tr := l.GetD(dom, "My text"); tr != expected {
+			t.Errorf("Expected '%s' but got '%s'", expected, tr)
+		}
+	}
+
+	// Broken files of other formats keep the loaded domain
+	ioutil.WriteFile(path.Join(dirname, "reload-json.json"), []byte(`{"My text": `), 0644)
+	if err := l.ReloadDomain("reload-json", nil); err == nil {
+		t.Error("Expected error reloading a broken JSON file")
+	}
+	if tr := l.GetD("reload-json", "My text"); tr != "New JSON text" {
+		t.Errorf("Expected 'New JSON text' but got '%s'", tr)
+	}
+
+	// Domains without file
+	l.AttachDomain("attached", new(Po))
+	if err := l.ReloadDomain("attached", nil); err == nil {
+		t.Error("Expected error reloading an attached domain")
+	}
+	if err := l.ReloadDomain("missing", nil); err == nil {
+		t.Error("Expected error reloading a missing domain")
+	}
+}
+
+func TestLocaleReload(t *testing.T) {
+	// Create Locale directory
+	dirname := path.Clean("/tmp" + string(os.PathSeparator) + "en_US")
+	err := os.MkdirAll(dirname, os.ModePerm)
+	if err != nil {
+		t.Fatalf("Can't create test directory: %s", err.Error())
+	}
+
+	write := func(dom, str string) {
+		filename := path.Clean(dirname + string(os.PathSeparator) + dom + ".po")
+		if err := ioutil.WriteFile(filename, []byte(str), 0644); err != nil {
+			t.Fatalf("Can't write to test file: %s", err.Error())
+		}
+	}
+
+	// Load initial content
+	write("reload_a", "msgid \"My text\"\nmsgstr \"Text A\"\n")
+	write("reload_b", "msgid \"My text\"\nmsgstr \"Text B\"\n")
+
+	l := NewLocale("/tmp", "en_US")
+	l.AddDomain("reload_a")
+	l.AddDomain("reload_b")
+
+	attached := new(Po)
+	attached.Parse("msgid \"My text\"\nmsgstr \"Attached text\"\n")
+	l.AttachDomain("attached", attached)
+
+	// Update files
+	write("reload_a", "msgid \"My text\"\nmsgstr \"New text A\"\n")
+	write("reload_b", "msgid \"My text\"\nmsgstr \"New text B\"\n")
+
+	// Read concurrently
+	done := make(chan bool)
+	go func() {
+		defer close(done)
+
+		for i := 0; i < 1000; i++ {
+			if tr := l.GetD("reload_a", "My text"); tr != "Text A" && tr != "New text A" {
+				t.Errorf("Unexpected translation during reload: '%s'", tr)
+				return
+			}
+		}
+	}()
+
+	if errs := l.Reload(); len(errs) != 0 {
+		t.Errorf("Unexpected errors: %v", errs)
+	}
+	<-done
+
+	for dom, expected := range map[string]string{"reload_a": "New text A", "reload_b": "New text B", "attached": "Attached text"} {
+		if tr := l.GetD(dom, "My text"); tr != expected {
+			t.Errorf("Expected '%s' but got '%s'", expected, tr)
+		}
+	}
+
+	// Missing files keep the loaded domain
+	os.Remove(path.Clean(dirname + string(os.PathSeparator) + "reload_b.po"))
+
+	if errs := l.Reload(); len(errs) != 1 {
+		t.Errorf("Expected 1 error but got %v", errs)
+	}
+
+	if tr := l.GetD("reload_b", "My text"); tr != "New text B" {
+		t.Errorf("Expected 'New text B' but got '%s'", tr)
+	}
+}
+
+func TestLocaleWatch(t *testing.T) {
+	// Create Locale directory
+	dirname := path.Clean("/tmp" + string(os.PathSeparator) + "en_US")
+	err := os.MkdirAll(dirname, os.ModePerm)
+	if err != nil {
+		t.Fatalf("Can't create test directory: %s", err.Error())
+	}
+
+	filename := path.Clean(dirname + string(os.PathSeparator) + "watch.po")
+
+	// Replace the file in a single step
+	write := func(str string, mtime time.Time) {
+		if err := ioutil.WriteFile(filename+".tmp", []byte(str), 0644); err != nil {
+			t.Fatalf("Can't write to test file: %s", err.Error())
+		}
+		if err := os.Chtimes(filename+".tmp", mtime, mtime); err != nil {
+			t.Fatalf("Can't change test file times: %s", err.Error())
+		}
+		if err := os.Rename(filename+".tmp", filename); err != nil {
+			t.Fatalf("Can't rename test file: %s", err.Error())
+		}
+	}
+
+	// Load initial content
+	start := time.Now().Add(-time.Hour)
+	write("msgid \"My text\"\nmsgstr \"Translated text\"\n", start)
+
+	l := NewLocale("/tmp", "en_US")
+	l.AddDomain("watch")
+
+	errs := make(chan error, 10)
+	stop := l.Watch(10*time.Millisecond, func(dom string, err error) {
+		errs <- err
+	})
+	defer stop()
+
+	// Update file
+	write("msgid \"My text\"\nmsgstr \"New translated text\"\n", start.Add(time.Minute))
+
+	tr := ""
+	for i := 0; i < 200 && tr != "New translated text"; i++ {
+		time.Sleep(10 * time.Millisecond)
+		tr = l.GetD("watch", "My text")
+	}
+
+	if tr != "New translated text" {
+		t.Errorf("Expected 'New translated text' but got '%s'", tr)
+	}
+
+	// Stop watching
+	stop()
+	stop()
+
+	write("msgid \"My text\"\nmsgstr \"Ignored text\"\n", start.Add(2*time.Minute))
+	time.Sleep(50 * time.Millisecond)
+
+	if tr = l.GetD("watch", "My text"); tr != "New translated text" {
+		t.Errorf("Expected 'New translated text' but got '%s'", tr)
+	}
+
+	select {
+	case err := <-errs:
+		t.Errorf("Unexpected error: %s", err.Error())
+	default:
+	}
+
+	// Non-positive intervals don't watch the files
+	for _, d := range []time.Duration{0, -time.Second} {
+		stop := l.Watch(d, nil)
+		stop()
+	}
+}
+
+func TestCheckSyntax(t *testing.T) {
+	for str, expected := range map[string]string{
+		"# Comment\nmsgid \"a\"\nmsgstr \"b\"\n":                             "",
+		"msgctxt \"c\"\nmsgid \"a\"\nmsgid_plural \"as\"\nmsgstr[0] \"b\"\n": "",
+		"msgid \"\"\nmsgstr \"\"\n\"Language: en\\n\"\n":                     "",
+		"msgid \"a\"\nmsgstr \"b\n":                                          "2: badly formatted string",
+		"\"a\"\n":                                                            "1: string without keyword",
+		"msgid \"a\"\nmsgstr[abc] \"b\"\n":                                   "2: invalid msgstr index",
+		"msgid \"a\"\nmsgstr[0 \"b\"\n":                                      "2: unclosed msgstr index",
+		"msgid \"a\"\nmsgid \"b\"\nmsgstr \"c\"\n":                           "2: msgid without msgstr on the previous entry",
+		"msgstr \"a\"\n":                                                     "1: msgstr without msgid",
+		"msgid_plural \"a\"\n":                                               "1: msgid_plural without msgid",
+		"msgid \"a\"\nmsgtxt \"b\"\n":                                        "2: unknown keyword \"msgtxt\"",
+		"msgid \"a\"\n":                                                      "2: msgid without msgstr at end of file",
+	} {
+		err := checkSyntax(str)
+
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+
+		if msg != expected {
+			t.Errorf("Expected '%s' for %q but got '%s'", expected, str, msg)
+		}
+	}
+}
+
+func TestLocaleReloadFallback(t *testing.T) {
+	// Create Locale directories
+	for _, lang := range []string{"es_AR", "fr"} {
+		dirname := path.Clean("/tmp" + string(os.PathSeparator) + lang)
+		if err := os.MkdirAll(dirname, os.ModePerm); err != nil {
+			t.Fatalf("Can't create test directory: %s", err.Error())
+		}
+	}
+
+	filename := path.Clean("/tmp" + string(os.PathSeparator) + "fr" + string(os.PathSeparator) + "reloadfb.po")
+
+	write := func(str string) {
+		if err := ioutil.WriteFile(filename, []byte(str), 0644); err != nil {
+			t.Fatalf("Can't write to test file: %s", err.Error())
+		}
+	}
+
+	write(`
+msgid "My text"
+msgstr "Texte traduit"
+`)
+
+	err := ioutil.WriteFile(path.Clean("/tmp"+string(os.PathSeparator)+"es_AR"+string(os.PathSeparator)+"reloadfb.po"), []byte(`
+msgid "Other text"
+msgstr "Otro texto"
+`), 0644)
+	if err != nil {
+		t.Fatalf("Can't write to test file: %s", err.Error())
+	}
+
+	l := NewLocaleWithFallback("/tmp", "es_AR", "fr")
+	l.AddDomain("reloadfb")
+
+	// Update the fallback file
+	write(`
+msgid "My text"
+msgstr "Nouveau texte traduit"
+`)
+
+	if errs := l.Reload(); len(errs) != 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+
+	tr := l.GetD("reloadfb", "My text")
+	if tr != "Nouveau texte traduit" {
+		t.Errorf("Expected 'Nouveau texte traduit' but got '%s'", tr)
+	}
+
+	// Remove the fallback file
+	if err := os.Remove(filename); err != nil {
+		t.Fatalf("Can't remove test file: %s", err.Error())
+	}
+
+	if errs := l.Reload(); len(errs) != 1 {
+		t.Errorf("Expected 1 error but got %v", errs)
+	}
+
+	tr = l.GetD("reloadfb", "My text")
+	if tr != "Nouveau texte traduit" {
+		t.Errorf("Expected 'Nouveau texte traduit' but got '%s'", tr)
+	}
+}
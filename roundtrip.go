@@ -0,0 +1,63 @@
+package gotext
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+)
+
+/*
+AssertRoundTrip writes the given catalog (po) with Po.Write, parses the written content again
+and compares both catalogs, returning an error describing every divergence, or nil if they are equivalent.
+
+It's intended as a testing helper for code editing catalogs, catching escaping and wrapping problems
+before the written files reach translators:
+
+    if err := gotext.AssertRoundTrip(po); err != nil {
+        t.Fatal(err)
+    }
+
+Entries are compared by context and msgid, including their msgid_plural, msgstr forms and flags.
+The error message lists the differences in the Diff format, followed by the entries whose flags changed.
+*/
+func AssertRoundTrip(po *Po) error {
+	var buf bytes.Buffer
+	if err := po.Write(&buf, WriteOptions{}); err != nil {
+		return err
+	}
+
+	// Parse written content with the same settings
+	parsed := new(Po)
+	po.RLock()
+	parsed.SetCollapseWhitespace(po.collapse)
+	po.RUnlock()
+	parsed.Parse(buf.String())
+
+	msg := Diff(po, parsed)
+
+	// Compare flags
+	a := po.snapshot()
+	b := parsed.snapshot()
+
+	keys := make([]entryKey, 0, len(a))
+	for k := range a {
+		if _, ok := b[k]; ok {
+			keys = append(keys, k)
+		}
+	}
+	sortEntryKeys(keys)
+
+	for _, k := range keys {
+		fa := strings.Join(a[k].Flags, ", ")
+		fb := strings.Join(b[k].Flags, ", ")
+		if fa != fb {
+			msg += "~ " + k.String() + "\n-     #, " + fa + "\n+     #, " + fb + "\n"
+		}
+	}
+
+	if msg == "" {
+		return nil
+	}
+
+	return errors.New("round trip changed the catalog:\n" + msg)
+}
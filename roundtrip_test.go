@@ -0,0 +1,53 @@
+package gotext
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAssertRoundTrip(t *testing.T) {
+	// Set PO content
+	str := `msgid ""
+msgstr ""
+"Language: es\n"
+
+#, fuzzy, c-format
+msgid "Line\twith \"quotes\"\n"
+"and more"
+msgstr "Línea\tcon \"comillas\"\n"
+"y más"
+
+msgctxt "Ctx"
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Un archivo"
+msgstr[1] "%d archivos"
+
+msgid "Control \a\b\v\f\\ chars"
+msgstr ""
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	if err := AssertRoundTrip(po); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+
+	// A catalog that can't be written back the same way
+	po = new(Po)
+	po.Parse(str)
+
+	t1 := po.find("Control \a\b\v\f\\ chars")
+	t1.Trs[0] = "Fine"
+	t1.Flags = []string{"bad\nflag"}
+
+	err := AssertRoundTrip(po)
+	if err == nil {
+		t.Fatal("Expected error for flags that can't be written back")
+	}
+
+	if !strings.Contains(err.Error(), `~ msgid "Control \a\b\v\f\\ chars"`) {
+		t.Errorf("Expected divergent entry on error but got: %s", err.Error())
+	}
+}
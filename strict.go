@@ -0,0 +1,313 @@
+package gotext
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SyntaxError describes a problem found on the content of a PO file by the strict parse methods, like ParseFileStrict.
+type SyntaxError struct {
+	// Name of the parsed file, or empty if the content wasn't read from a file.
+	File string
+
+	// Line where the problem was found, or 0 if it applies to the whole content.
+	Line int
+
+	// Description of the problem.
+	Message string
+}
+
+// Error returns the problem in the "file:line: message" form used by compilers and other tools.
+func (e *SyntaxError) Error() string {
+	pos := e.File
+	if e.Line > 0 {
+		if pos != "" {
+			pos += ":"
+		}
+		pos += strconv.Itoa(e.Line)
+	}
+
+	if pos == "" {
+		return e.Message
+	}
+
+	return pos + ": " + e.Message
+}
+
+/*
+ParseFileStrict reads the file by its provided path (f) and parses its content as a .po file, like ParseFile,
+reporting the problems ParseFile silently skips, so contributed catalogs can be validated before using them:
+
+    po := new(gotext.Po)
+    warnings, errs := po.ParseFileStrict("/path/to/po/file/translations.po")
+    for _, err := range errs {
+        log.Println(err)
+    }
+
+It returns the error if the file can't be read, or a *SyntaxError for each problem found on its content,
+with the file name and line, sorted by line, like badly quoted strings, unknown keywords, entries without msgstr,
+msgstr indexes that don't match the msgid_plural, duplicate entries and charsets that can't be converted to UTF-8.
+Content with errors isn't loaded at all. Otherwise, the non-fatal problems found while loading it are returned as warnings,
+the same ones reported to the load warning handler (see SetLoadWarnHandler), like untranslated and fuzzy entries.
+*/
+func (po *Po) ParseFileStrict(f string) ([]Warning, []error) {
+	data, err := ioutil.ReadFile(f)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	return po.parseStrict(f, string(data))
+}
+
+// ParseStrict loads the translations specified in the provided string (str), like Parse,
+// returning the problems found on its content, as described on ParseFileStrict.
+func (po *Po) ParseStrict(str string) ([]Warning, []error) {
+	return po.parseStrict("", str)
+}
+
+// parseStrict checks the given PO formatted string (str), read from the given file name (file) if any,
+// and loads it if no errors are found, returning the load warnings.
+func (po *Po) parseStrict(file, str string) ([]Warning, []error) {
+	converted, err := toUTF8(str)
+	if err != nil {
+		return nil, []error{&SyntaxError{File: file, Message: err.Error()}}
+	}
+
+	if errs := syntaxErrors(file, converted); len(errs) > 0 {
+		return nil, errs
+	}
+
+	// Collect the load warnings, still reporting them to the handler
+	var warnings []Warning
+
+	po.Lock()
+	h := po.warn
+	po.warn = func(w Warning) {
+		warnings = append(warnings, w)
+		if h != nil {
+			h(w)
+		}
+	}
+	po.Unlock()
+
+	po.parse(str)
+
+	po.Lock()
+	po.warn = h
+	po.Unlock()
+
+	return warnings, nil
+}
+
+// strictEntry is the entry being checked by syntaxErrors.
+type strictEntry struct {
+	ctx, id     string
+	line        int
+	hasID       bool
+	hasPlural   bool
+	hasStr      bool
+	indexedStrs map[int]bool
+}
+
+// syntaxErrors returns the problems found on the given UTF-8 PO formatted string (str), read from the given file name (file).
+// Obsolete entries aren't checked.
+func syntaxErrors(file, str string) []error {
+	var errs []error
+	fail := func(line int, format string, args ...interface{}) {
+		errs = append(errs, &SyntaxError{File: file, Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Lines where each entry was declared
+	declared := make(map[entryKey]int)
+
+	var e *strictEntry
+	finish := func() {
+		if e == nil || !e.hasID {
+			if e != nil {
+				fail(e.line, "msgctxt without msgid")
+			}
+			e = nil
+			return
+		}
+
+		if !e.hasStr {
+			fail(e.line, "msgid %q without msgstr", e.id)
+		}
+
+		k := entryKey{ctx: e.ctx, id: e.id}
+		if prev, ok := declared[k]; ok {
+			fail(e.line, "duplicate %s, first declared on line %d", k, prev)
+		} else {
+			declared[k] = e.line
+		}
+
+		e = nil
+	}
+
+	field := ""
+	for n, l := range strings.Split(str, "\n") {
+		line := n + 1
+		l = strings.TrimSpace(l)
+
+		// Skip empty lines and comments
+		if l == "" || strings.HasPrefix(l, "#") {
+			field = ""
+			continue
+		}
+
+		// Continuation lines
+		if strings.HasPrefix(l, "\"") {
+			s, err := strconv.Unquote(l)
+			if err != nil {
+				fail(line, "badly quoted string %s", l)
+			} else if field == "" {
+				fail(line, "string %s doesn't follow any keyword", l)
+			} else if field == "msgctxt" {
+				e.ctx += s
+			} else if field == "msgid" {
+				e.id += s
+			}
+			continue
+		}
+
+		keyword, value := l, ""
+		if i := strings.IndexAny(l, " \t"); i >= 0 {
+			keyword, value = l[:i], strings.TrimSpace(l[i+1:])
+		}
+
+		s, err := strconv.Unquote(value)
+		if err != nil {
+			fail(line, "badly quoted string after %s", keyword)
+		}
+		field = ""
+
+		switch {
+		case keyword == "msgctxt":
+			finish()
+			e = &strictEntry{ctx: s, line: line}
+			field = "msgctxt"
+
+		case keyword == "msgid":
+			if e == nil || e.hasID {
+				finish()
+				e = &strictEntry{}
+			}
+			e.id, e.hasID, e.line = s, true, line
+			field = "msgid"
+
+		case keyword == "msgid_plural":
+			if e == nil || !e.hasID || e.hasStr {
+				fail(line, "msgid_plural without msgid")
+				continue
+			}
+			if e.hasPlural {
+				fail(line, "duplicate msgid_plural")
+			}
+			e.hasPlural = true
+			field = "msgid_plural"
+
+		case keyword == "msgstr":
+			if e == nil || !e.hasID {
+				fail(line, "msgstr without msgid")
+				continue
+			}
+			if e.hasPlural {
+				fail(line, "msgstr without index on an entry with msgid_plural")
+			} else if e.hasStr {
+				fail(line, "duplicate msgstr")
+			}
+			e.hasStr = true
+			field = "msgstr"
+
+		case strings.HasPrefix(keyword, "msgstr["):
+			i, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(keyword, "msgstr["), "]"))
+			if e == nil || !e.hasID {
+				fail(line, "%s without msgid", keyword)
+				continue
+			}
+			if err != nil || !strings.HasSuffix(keyword, "]") || i < 0 {
+				fail(line, "invalid msgstr index %s", keyword)
+				e.hasStr = true
+				continue
+			}
+			if !e.hasPlural {
+				fail(line, "%s on an entry without msgid_plural", keyword)
+			} else if e.indexedStrs[i] {
+				fail(line, "duplicate %s", keyword)
+			} else if len(e.indexedStrs) != i {
+				fail(line, "%s out of order, expected msgstr[%d]", keyword, len(e.indexedStrs))
+			}
+			if e.indexedStrs == nil {
+				e.indexedStrs = make(map[int]bool)
+			}
+			e.indexedStrs[i] = true
+			e.hasStr = true
+			field = "msgstr"
+
+		default:
+			fail(line, "unknown keyword %q", keyword)
+		}
+	}
+
+	finish()
+
+	// Sort by line, as entries are checked once they end
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].(*SyntaxError).Line < errs[j].(*SyntaxError).Line
+	})
+
+	return errs
+}
+
+/*
+AddDomainE works like AddDomain, returning the problems found loading the domain file instead of ignoring them,
+so catalogs can be validated on CI before deploying them.
+The domain file is looked up like AddDomain does. PO files are checked as described on Po.ParseFileStrict,
+while MO, JSON and XLIFF files return their load errors.
+A missing file is reported with the path tried for the domain.
+
+The domain isn't loaded, nor replaced if it exists, when there are errors; the returned warnings are non-fatal.
+The fallbacks created along with this Locale load the domain like AddDomain, and their problems aren't reported.
+*/
+func (l *Locale) AddDomainE(dom string) ([]Warning, []error) {
+	filename := l.domainFile(dom, catalogExts...)
+
+	var warnings []Warning
+	var errs []error
+	var po *Po
+
+	if path.Ext(filename) == ".po" {
+		po = l.newPo()
+
+		data, err := l.readFile(filename)
+		if err != nil {
+			errs = []error{err}
+		} else {
+			warnings, errs = po.parseStrict(filename, string(data))
+		}
+	} else {
+		var err error
+		if po, err = l.loadFile(filename); err != nil {
+			errs = []error{err}
+		}
+	}
+
+	if len(errs) == 0 {
+		l.setDomain(dom, filename, po)
+	}
+
+	// Add domain to the fallbacks created along with this Locale
+	l.RLock()
+	fb, own := l.fallback, l.ownFallback
+	l.RUnlock()
+
+	if own {
+		fb.AddDomain(dom)
+	}
+
+	return warnings, errs
+}
@@ -0,0 +1,161 @@
+package gotext
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestPoParseStrict(t *testing.T) {
+	// Set PO content
+	str := `
+msgid ""
+msgstr "Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+msgid "My text"
+msgstr "Mi texto"
+
+#, fuzzy
+msgid "Fuzzy text"
+msgstr "Texto difuso"
+
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Un archivo"
+msgstr[1] ""
+
+#~ msgid "Obsolete"
+#~ msgstr "Obsoleto"
+`
+
+	// Write PO content to file
+	dirname := path.Clean("/tmp" + string(os.PathSeparator) + "strict")
+	if err := os.MkdirAll(dirname, os.ModePerm); err != nil {
+		t.Fatalf("Can't create test directory: %s", err.Error())
+	}
+
+	filename := path.Join(dirname, "valid.po")
+	if err := ioutil.WriteFile(filename, []byte(str), 0644); err != nil {
+		t.Fatalf("Can't write to test file: %s", err.Error())
+	}
+
+	var handled int
+	po := new(Po)
+	po.SetLoadWarnHandler(func(Warning) { handled++ })
+
+	warnings, errs := po.ParseFileStrict(filename)
+	if len(errs) != 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	if len(warnings) != 2 || handled != 2 {
+		t.Errorf("Expected 2 warnings but got %v", warnings)
+	}
+	if tr := po.Get("My text"); tr != "Mi texto" {
+		t.Errorf("Expected 'Mi texto' but got '%s'", tr)
+	}
+
+	// Missing file
+	if _, errs := new(Po).ParseFileStrict(path.Join(dirname, "missing.po")); len(errs) != 1 || !os.IsNotExist(errs[0]) {
+		t.Errorf("Expected missing file error but got %v", errs)
+	}
+
+	// Invalid content
+	invalid := map[string]string{
+		"msgid \"A\"\nmsgstr \"B\n":                                             `2: badly quoted string after msgstr`,
+		"msgid \"A\"\nmsgstr \"B\"\n\"C\n":                                      `3: badly quoted string "C`,
+		"\"A\"\nmsgid \"A\"\nmsgstr \"B\"\n":                                    `1: string "A" doesn't follow any keyword`,
+		"msgid \"A\"\nmsgstring \"B\"\n":                                        `2: unknown keyword "msgstring"`,
+		"msgid \"A\"\n\nmsgid \"B\"\nmsgstr \"C\"\n":                            `1: msgid "A" without msgstr`,
+		"msgstr \"B\"\n":                                                        `1: msgstr without msgid`,
+		"msgctxt \"Ctx\"\nmsgctxt \"Ctx\"\nmsgid \"A\"\nmsgstr \"\"":            `1: msgctxt without msgid`,
+		"msgid \"A\"\nmsgid_plural \"As\"\nmsgstr \"B\"\n":                      `3: msgstr without index on an entry with msgid_plural`,
+		"msgid \"A\"\nmsgstr[0] \"B\"\n":                                        `2: msgstr[0] on an entry without msgid_plural`,
+		"msgid \"A\"\nmsgid_plural \"As\"\nmsgstr[1] \"B\"\n":                   `3: msgstr[1] out of order, expected msgstr[0]`,
+		"msgid \"A\"\nmsgid_plural \"As\"\nmsgstr[x] \"B\"\n":                   `3: invalid msgstr index msgstr[x]`,
+		"msgid \"A\"\nmsgstr \"B\"\n\nmsgid \"A\"\nmsgstr \"C\"\n":              `4: duplicate msgid "A", first declared on line 1`,
+		"msgid \"\"\nmsgstr \"Content-Type: text/plain; charset=unknown\\n\"\n": `unsupported charset "unknown"`,
+	}
+	for content, expected := range invalid {
+		po := new(Po)
+		po.Set("My text", "Mi texto")
+
+		filename := path.Join(dirname, "invalid.po")
+		if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatalf("Can't write to test file: %s", err.Error())
+		}
+
+		_, errs := po.ParseFileStrict(filename)
+		if len(errs) == 0 || !strings.Contains(errs[len(errs)-1].Error(), expected) {
+			t.Errorf("Expected '%s' error for %q but got %v", expected, content, errs)
+			continue
+		}
+		if e, ok := errs[0].(*SyntaxError); !ok || e.File != filename {
+			t.Errorf("Expected syntax error on %s but got %v", filename, errs[0])
+		}
+
+		// Nothing is loaded
+		if entries := po.Translations(); len(entries) != 1 {
+			t.Errorf("Expected catalog unchanged for %q but got %v", content, entries)
+		}
+	}
+
+	// Content without file
+	if _, errs := new(Po).ParseStrict("msgstr \"B\"\n"); len(errs) != 1 || errs[0].Error() != "1: msgstr without msgid" {
+		t.Errorf("Unexpected errors: %v", errs)
+	}
+}
+
+func TestLocaleAddDomainE(t *testing.T) {
+	// Create Locale directory
+	dirname := path.Clean("/tmp" + string(os.PathSeparator) + "strict")
+	if err := os.MkdirAll(dirname, os.ModePerm); err != nil {
+		t.Fatalf("Can't create test directory: %s", err.Error())
+	}
+
+	write := func(name, str string) {
+		if err := ioutil.WriteFile(path.Join(dirname, name), []byte(str), 0644); err != nil {
+			t.Fatalf("Can't write to test file: %s", err.Error())
+		}
+	}
+
+	write("good.po", "msgid \"My text\"\nmsgstr \"\"\n")
+	write("bad.po", "msgid \"My text\"\nmsgstr \"Mi texto\"\nmsgid \"My text\"\nmsgstr \"Otro texto\"\n")
+	write("bad.json", `{"My text": 1}`)
+
+	l := NewLocale("/tmp", "strict")
+
+	warnings, errs := l.AddDomainE("good")
+	if len(errs) != 0 || len(warnings) != 1 {
+		t.Errorf("Expected 1 warning and no errors but got %v and %v", warnings, errs)
+	}
+
+	_, errs = l.AddDomainE("bad")
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), path.Join(dirname, "bad.po")+":3: duplicate") {
+		t.Errorf("Unexpected errors: %v", errs)
+	}
+
+	os.Remove(path.Join(dirname, "bad.po"))
+	if _, errs := l.AddDomainE("bad"); len(errs) != 1 {
+		t.Errorf("Expected JSON error but got %v", errs)
+	}
+
+	if _, errs := l.AddDomainE("missing"); len(errs) != 1 || !os.IsNotExist(errs[0]) {
+		t.Errorf("Expected missing file error but got %v", errs)
+	}
+
+	// XLIFF catalogs
+	write("xliff.xlf", `<xliff version="1.2"><file><body><trans-unit id="1"><source>My text</source><target>Mi texto</target></trans-unit></body></file></xliff>`)
+	if _, errs := l.AddDomainE("xliff"); len(errs) != 0 {
+		t.Errorf("Unexpected errors: %v", errs)
+	}
+	if tr := l.GetD("xliff", "My text"); tr != "Mi texto" {
+		t.Errorf("Expected 'Mi texto' but got '%s'", tr)
+	}
+
+	// Only valid domains are loaded
+	if doms := l.GetDomains(); len(doms) != 2 || doms[0] != "good" || doms[1] != "xliff" {
+		t.Errorf("Expected 'good' and 'xliff' domains but got %v", doms)
+	}
+}
@@ -0,0 +1,154 @@
+package gotext
+
+import (
+	"strings"
+)
+
+// SyncResult holds the catalog returned by Sync, along with the entries it added and made obsolete.
+type SyncResult struct {
+	// Updated catalog.
+	Catalog *Po
+
+	// Entries found on the extracted catalog only, added untranslated.
+	Added []ContextKey
+
+	// Translated entries missing on the extracted catalog, kept as obsolete entries.
+	Obsolete []ContextKey
+}
+
+/*
+Sync updates a translated catalog with the entries extracted from the source code (extracted),
+like a template produced by an extractor, and returns the updated catalog without modifying the given ones.
+It's the equivalent of running msgmerge, so catalogs can be kept current with the code in a single call:
+
+    res := gotext.Sync(catalog, template)
+    for _, k := range res.Added {
+        log.Printf("New string: %s", k)
+    }
+    res.Catalog.Write(f, gotext.WriteOptions{})
+
+The updated catalog has the entries of the extracted catalog, in its order:
+
+  - Entries existing on the catalog keep their translations and fuzzy flag, previously obsolete ones included.
+    Their msgid_plural, extracted comments, references and flags are taken from the extracted catalog,
+    while translator comments and the flags the extracted entry doesn't declare, like "no-c-format", are kept.
+  - New entries are added untranslated, with as many plural forms as the catalog Plural-Forms header declares (2 by default).
+
+Translated catalog entries missing on the extracted catalog are kept as obsolete entries,
+along with the previous obsolete entries, while untranslated ones are dropped.
+The catalog header is kept, or the extracted header is used if the catalog doesn't have one.
+*/
+func Sync(catalog, extracted *Po) SyncResult {
+	a := catalog.snapshot()
+	b := extracted.snapshot()
+
+	// Index previous obsolete entries
+	prev := make(map[entryKey]*Translation)
+	for _, t := range catalog.Obsolete() {
+		prev[entryKey{ctx: t.Context, id: t.ID}] = t
+	}
+
+	// Get catalog plural forms amount
+	nplurals := 0
+	if h, ok := a[entryKey{}]; ok {
+		nplurals = headerNPlurals(parseHeader(h.Trs[0]))
+	}
+	if nplurals == 0 {
+		nplurals = 2
+	}
+
+	res := SyncResult{Catalog: new(Po)}
+	catalog.RLock()
+	res.Catalog.collapse = catalog.collapse
+	catalog.RUnlock()
+	res.Catalog.init()
+
+	// Keep header
+	if h, ok := a[entryKey{}]; ok {
+		res.Catalog.store("", h.copy())
+	} else if h, ok := b[entryKey{}]; ok {
+		res.Catalog.store("", h.copy())
+	}
+
+	for _, k := range seqOrder(b) {
+		if k.id == "" && k.ctx == "" {
+			continue
+		}
+
+		src := b[k]
+
+		// Look for the existing translation
+		t, ok := a[k]
+		if !ok {
+			t, ok = prev[k]
+			delete(prev, k)
+		}
+
+		if !ok {
+			// Add untranslated entry
+			t = NewTranslation()
+			t.ID = src.ID
+			t.PluralID = src.PluralID
+			t.Flags = src.Flags
+			t.ExtractedComments = src.ExtractedComments
+			t.References = src.References
+
+			forms := 1
+			if src.PluralID != "" {
+				forms = nplurals
+			}
+			for i := 0; i < forms; i++ {
+				t.Trs[i] = ""
+			}
+
+			res.Catalog.store(k.ctx, t.copy())
+			res.Added = append(res.Added, ContextKey{Context: k.ctx, MsgID: k.id})
+			continue
+		}
+
+		// Update entry from the extracted one
+		t = t.copy()
+		t.PluralID = src.PluralID
+		t.ExtractedComments = append([]string(nil), src.ExtractedComments...)
+		t.References = append([]string(nil), src.References...)
+
+		// Use the template flags, keeping the ones only set on the catalog, fuzzy first
+		prevFlags := &Translation{Flags: t.Flags}
+		t.Flags = nil
+		if prevFlags.HasFlag("fuzzy") {
+			t.Flags = append(t.Flags, "fuzzy")
+		}
+		for _, flag := range src.Flags {
+			if flag != "fuzzy" {
+				t.Flags = append(t.Flags, flag)
+			}
+		}
+		for _, flag := range prevFlags.Flags {
+			name := strings.TrimSpace(strings.SplitN(flag, ":", 2)[0])
+			if name != "fuzzy" && !src.HasFlag(name) {
+				t.Flags = append(t.Flags, flag)
+			}
+		}
+
+		res.Catalog.store(k.ctx, t)
+	}
+
+	// Make missing entries obsolete
+	for _, k := range seqOrder(a) {
+		if _, ok := b[k]; ok || k.id == "" || !a[k].translated() {
+			continue
+		}
+
+		res.Catalog.obsolete = append(res.Catalog.obsolete, a[k].copy())
+		res.Obsolete = append(res.Obsolete, ContextKey{Context: k.ctx, MsgID: k.id})
+	}
+
+	// Keep previous obsolete entries
+	for _, t := range catalog.Obsolete() {
+		if _, ok := prev[entryKey{ctx: t.Context, id: t.ID}]; ok {
+			res.Catalog.obsolete = append(res.Catalog.obsolete, t)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,177 @@
+package gotext
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSync(t *testing.T) {
+	// Set translated catalog content
+	catalog := `msgid ""
+msgstr ""
+"Language: ru\n"
+"Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+#, fuzzy
+msgid "My text"
+msgstr "Мой текст"
+
+msgid "Removed text"
+msgstr "Удалённый текст"
+
+msgid "Removed untranslated"
+msgstr ""
+
+#~ msgid "Revived text"
+#~ msgstr "Возрождённый текст"
+
+#~ msgid "Old text"
+#~ msgstr "Старый текст"
+`
+
+	// Set extracted template content
+	extracted := `msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+
+#, c-format
+msgid "My text"
+msgstr ""
+
+msgid "Revived text"
+msgstr ""
+
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] ""
+msgstr[1] ""
+
+msgctxt "Ctx"
+msgid "New text"
+msgstr ""
+`
+
+	a := new(Po)
+	a.Parse(catalog)
+
+	b := new(Po)
+	b.Parse(extracted)
+
+	res := Sync(a, b)
+
+	expectedAdded := []ContextKey{{MsgID: "One file"}, {Context: "Ctx", MsgID: "New text"}}
+	if len(res.Added) != len(expectedAdded) {
+		t.Fatalf("Expected added %v but got %v", expectedAdded, res.Added)
+	}
+	for i, k := range res.Added {
+		if k != expectedAdded[i] {
+			t.Errorf("Expected added entry %v but got %v", expectedAdded[i], k)
+		}
+	}
+
+	if len(res.Obsolete) != 1 || res.Obsolete[0] != (ContextKey{MsgID: "Removed text"}) {
+		t.Errorf("Expected 'Removed text' to be obsolete but got %v", res.Obsolete)
+	}
+
+	// Write updated catalog
+	var buf bytes.Buffer
+	if err := res.Catalog.Write(&buf, WriteOptions{}); err != nil {
+		t.Fatalf("Unexpected error writing catalog: %s", err.Error())
+	}
+
+	expected := `msgid ""
+msgstr ""
+"Language: ru\n"
+"Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+#, fuzzy, c-format
+msgid "My text"
+msgstr "Мой текст"
+
+msgid "Revived text"
+msgstr "Возрождённый текст"
+
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] ""
+msgstr[1] ""
+msgstr[2] ""
+
+msgctxt "Ctx"
+msgid "New text"
+msgstr ""
+
+#~ msgid "Removed text"
+#~ msgstr "Удалённый текст"
+
+#~ msgid "Old text"
+#~ msgstr "Старый текст"
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected synced catalog:\n%s", buf.String())
+	}
+
+	// Obsolete entries aren't translated
+	if tr := res.Catalog.Get("Removed text"); tr != "Removed text" {
+		t.Errorf("Expected 'Removed text' but got '%s'", tr)
+	}
+
+	// The written catalog is stable
+	if err := AssertRoundTrip(res.Catalog); err != nil {
+		t.Errorf("Unexpected round trip error: %s", err.Error())
+	}
+
+	// Original catalogs aren't modified
+	if len(a.Obsolete()) != 2 || a.Get("Removed text") != "Удалённый текст" {
+		t.Error("Expected the original catalog to be unchanged")
+	}
+}
+
+func TestSyncFlags(t *testing.T) {
+	// Set translated catalog content
+	catalog := `
+#, fuzzy, no-wrap, range: 1..5
+msgid "%d stars"
+msgstr "%d estrellas"
+
+#, reviewed
+msgid "My text"
+msgstr "Mi texto"
+`
+
+	// Set extracted template content
+	extracted := `
+#, c-format, range: 1..10
+msgid "%d stars"
+msgstr ""
+
+#, c-format
+msgid "My text"
+msgstr ""
+`
+
+	a := new(Po)
+	a.Parse(catalog)
+
+	b := new(Po)
+	b.Parse(extracted)
+
+	res := Sync(a, b)
+
+	// Catalog flags are kept, unless the extracted entry declares them
+	expected := map[string][]string{
+		"%d stars": {"fuzzy", "c-format", "range: 1..10", "no-wrap"},
+		"My text":  {"c-format", "reviewed"},
+	}
+	for id, flags := range expected {
+		e := res.Catalog.GetEntry(id)
+		if e == nil {
+			t.Fatalf("Expected entry '%s'", id)
+		}
+
+		if strings.Join(e.Flags, ", ") != strings.Join(flags, ", ") {
+			t.Errorf("Expected flags %v for '%s' but got %v", flags, id, e.Flags)
+		}
+	}
+}
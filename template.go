@@ -0,0 +1,57 @@
+package gotext
+
+import (
+	"text/template"
+)
+
+/*
+TemplateFuncs returns the functions to translate strings from text/template and html/template templates
+using this Locale, so the Locale doesn't need to be passed to every template as data:
+
+    tpl := template.Must(template.New("page").Funcs(l.TemplateFuncs()).Parse(
+        `<h1>{{ T "Welcome, %s" .Name }}</h1><p>{{ TN "One message" "%d messages" .Count .Count }}</p>`,
+    ))
+
+The functions follow the Locale methods parameters:
+
+  - T: Get
+  - TN: GetN
+  - TC: GetC
+  - TNC: GetNC
+  - TD: GetD
+  - TND: GetND
+  - TDC: GetDC
+  - TNDC: GetNDC
+
+Translations are returned as plain strings, so html/template escapes them, along with the inserted variables,
+according to the context they are used on. Markup on the translated strings is escaped as well,
+keeping translators from injecting HTML into the pages.
+*/
+func (l *Locale) TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"T": func(str string, vars ...interface{}) string {
+			return l.Get(str, vars...)
+		},
+		"TN": func(str, plural string, n int, vars ...interface{}) string {
+			return l.GetN(str, plural, n, vars...)
+		},
+		"TC": func(str, ctx string, vars ...interface{}) string {
+			return l.GetC(str, ctx, vars...)
+		},
+		"TNC": func(str, plural string, n int, ctx string, vars ...interface{}) string {
+			return l.GetNC(str, plural, n, ctx, vars...)
+		},
+		"TD": func(dom, str string, vars ...interface{}) string {
+			return l.GetD(dom, str, vars...)
+		},
+		"TND": func(dom, str, plural string, n int, vars ...interface{}) string {
+			return l.GetND(dom, str, plural, n, vars...)
+		},
+		"TDC": func(dom, str, ctx string, vars ...interface{}) string {
+			return l.GetDC(dom, str, ctx, vars...)
+		},
+		"TNDC": func(dom, str, plural string, n int, ctx string, vars ...interface{}) string {
+			return l.GetNDC(dom, str, plural, n, ctx, vars...)
+		},
+	}
+}
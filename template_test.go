@@ -0,0 +1,101 @@
+package gotext
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"testing"
+	"text/template"
+)
+
+func TestLocaleTemplateFuncs(t *testing.T) {
+	// Set PO content
+	str := `
+msgid ""
+msgstr "Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+msgid "Welcome, %s"
+msgstr "Bienvenido, %s"
+
+msgid "One message"
+msgid_plural "%d messages"
+msgstr[0] "Un mensaje"
+msgstr[1] "%d mensajes"
+
+msgctxt "Menu"
+msgid "Open"
+msgstr "Abrir"
+
+msgctxt "Menu"
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Un archivo"
+msgstr[1] "%d archivos"
+
+msgid "Bold"
+msgstr "<b>Negrita</b>"
+`
+
+	extras := `
+msgid "Extras text"
+msgstr "Texto extra"
+
+msgid "One item"
+msgid_plural "%d items"
+msgstr[0] "Un elemento"
+msgstr[1] "%d elementos"
+
+msgctxt "Menu"
+msgid "Close"
+msgstr "Cerrar"
+
+msgctxt "Menu"
+msgid "One tab"
+msgid_plural "%d tabs"
+msgstr[0] "Una pestaña"
+msgstr[1] "%d pestañas"
+`
+
+	l := NewLocale("/tmp", "es")
+
+	po := new(Po)
+	po.Parse(str)
+	l.AttachDomain("default", po)
+
+	po = new(Po)
+	po.Parse(extras)
+	l.AttachDomain("extras", po)
+
+	// Text templates
+	tpl := template.Must(template.New("text").Funcs(l.TemplateFuncs()).Parse(
+		`{{ T "Welcome, %s" .Name }}|{{ TN "One message" "%d messages" .Count .Count }}|{{ TC "Open" "Menu" }}|` +
+			`{{ TNC "One file" "%d files" 1 "Menu" }}|{{ TD "extras" "Extras text" }}|{{ TND "extras" "One item" "%d items" 3 3 }}|` +
+			`{{ TDC "extras" "Close" "Menu" }}|{{ TNDC "extras" "One tab" "%d tabs" 2 "Menu" 2 }}|{{ T "Bold" }}`,
+	))
+
+	data := map[string]interface{}{"Name": "<Ana>", "Count": 5}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	expected := "Bienvenido, <Ana>|5 mensajes|Abrir|Un archivo|Texto extra|3 elementos|Cerrar|2 pestañas|<b>Negrita</b>"
+	if buf.String() != expected {
+		t.Errorf("Expected '%s' but got '%s'", expected, buf.String())
+	}
+
+	// HTML templates escape translations and variables
+	html := htmltemplate.Must(htmltemplate.New("html").Funcs(l.TemplateFuncs()).Parse(
+		`<p title="{{ T "Welcome, %s" .Name }}">{{ T "Bold" }}</p>`,
+	))
+
+	buf.Reset()
+	if err := html.Execute(&buf, data); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	expected = `<p title="Bienvenido, &lt;Ana&gt;">&lt;b&gt;Negrita&lt;/b&gt;</p>`
+	if buf.String() != expected {
+		t.Errorf("Expected '%s' but got '%s'", expected, buf.String())
+	}
+}
@@ -0,0 +1,74 @@
+package gotext
+
+// Translator is implemented by the objects providing translations for a single language, like Po and Locale.
+// It allows to swap the source of translations, for example with a KeysLocale while auditing keys.
+type Translator interface {
+	Get(str string, vars ...interface{}) string
+	GetN(str, plural string, n int, vars ...interface{}) string
+	GetC(str, ctx string, vars ...interface{}) string
+	GetNC(str, plural string, n int, ctx string, vars ...interface{}) string
+}
+
+/*
+KeysLocale is a pseudo-locale that returns the msgid being looked up instead of its translation,
+so developers can see which key backs each string on a UI built with key-based catalogs.
+
+Unlike an empty Locale, which returns the untranslated strings formatted with their vars,
+it always returns the raw msgid, without inserting the vars or choosing a plural form.
+Strings requested in a context are returned as "context|msgid". The domain isn't included.
+
+It implements Translator, and it has the domain variants of Locale too, so it's a drop-in replacement:
+
+    var tr gotext.Translator = gotext.NewKeysLocale()
+    if !debugKeys {
+        tr = locale
+    }
+
+    println(tr.Get("home.title"))
+*/
+type KeysLocale struct{}
+
+// NewKeysLocale creates a new KeysLocale object.
+func NewKeysLocale() *KeysLocale {
+	return &KeysLocale{}
+}
+
+// Get returns the given string (str).
+func (k *KeysLocale) Get(str string, vars ...interface{}) string {
+	return str
+}
+
+// GetN returns the given singular string (str).
+func (k *KeysLocale) GetN(str, plural string, n int, vars ...interface{}) string {
+	return str
+}
+
+// GetD returns the given string (str).
+func (k *KeysLocale) GetD(dom, str string, vars ...interface{}) string {
+	return str
+}
+
+// GetND returns the given singular string (str).
+func (k *KeysLocale) GetND(dom, str, plural string, n int, vars ...interface{}) string {
+	return str
+}
+
+// GetC returns the given string (str) prefixed by its context (ctx).
+func (k *KeysLocale) GetC(str, ctx string, vars ...interface{}) string {
+	return ctx + "|" + str
+}
+
+// GetNC returns the given singular string (str) prefixed by its context (ctx).
+func (k *KeysLocale) GetNC(str, plural string, n int, ctx string, vars ...interface{}) string {
+	return ctx + "|" + str
+}
+
+// GetDC returns the given string (str) prefixed by its context (ctx).
+func (k *KeysLocale) GetDC(dom, str, ctx string, vars ...interface{}) string {
+	return ctx + "|" + str
+}
+
+// GetNDC returns the given singular string (str) prefixed by its context (ctx).
+func (k *KeysLocale) GetNDC(dom, str, plural string, n int, ctx string, vars ...interface{}) string {
+	return ctx + "|" + str
+}
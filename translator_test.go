@@ -0,0 +1,70 @@
+package gotext
+
+import (
+	"testing"
+)
+
+// Implementation checks
+var (
+	_ Translator = new(Po)
+	_ Translator = new(Locale)
+	_ Translator = new(KeysLocale)
+)
+
+func TestKeysLocale(t *testing.T) {
+	// Set PO content
+	str := `
+msgid "home.welcome %s"
+msgstr "Welcome %s"
+
+msgctxt "menu"
+msgid "home.title"
+msgstr "Home"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	var tr Translator = po
+	if s := tr.Get("home.welcome %s", "Ana"); s != "Welcome Ana" {
+		t.Errorf("Expected 'Welcome Ana' but got '%s'", s)
+	}
+
+	// Keys are returned even if translations exist
+	tr = NewKeysLocale()
+
+	if s := tr.Get("home.welcome %s", "Ana"); s != "home.welcome %s" {
+		t.Errorf("Expected 'home.welcome %%s' but got '%s'", s)
+	}
+
+	if s := tr.GetN("files.count", "files.count.plural", 5); s != "files.count" {
+		t.Errorf("Expected 'files.count' but got '%s'", s)
+	}
+
+	if s := tr.GetC("home.title", "menu"); s != "menu|home.title" {
+		t.Errorf("Expected 'menu|home.title' but got '%s'", s)
+	}
+
+	if s := tr.GetNC("files.count", "files.count.plural", 1, "menu"); s != "menu|files.count" {
+		t.Errorf("Expected 'menu|files.count' but got '%s'", s)
+	}
+
+	// Domain variants
+	k := NewKeysLocale()
+
+	if s := k.GetD("extras", "home.title"); s != "home.title" {
+		t.Errorf("Expected 'home.title' but got '%s'", s)
+	}
+
+	if s := k.GetND("extras", "files.count", "files.count.plural", 2); s != "files.count" {
+		t.Errorf("Expected 'files.count' but got '%s'", s)
+	}
+
+	if s := k.GetDC("extras", "home.title", "menu"); s != "menu|home.title" {
+		t.Errorf("Expected 'menu|home.title' but got '%s'", s)
+	}
+
+	if s := k.GetNDC("extras", "files.count", "files.count.plural", 2, "menu"); s != "menu|files.count" {
+		t.Errorf("Expected 'menu|files.count' but got '%s'", s)
+	}
+}
@@ -0,0 +1,135 @@
+package gotext
+
+// poView is an immutable copy of the lookup state of a Po object.
+// It's published after the catalog changes, so lookups read it without locking the catalog.
+type poView struct {
+	translations   map[string]*Translation
+	contexts       map[string]map[string]*Translation
+	defaultContext string
+	collapse       bool
+	fuzzy          bool
+	plural         pluralRule
+}
+
+// key returns the storage key for the given string (str) following the view normalization settings.
+func (v *poView) key(str string) string {
+	if v.collapse {
+		return collapseWhitespace(str)
+	}
+
+	return str
+}
+
+// view returns the published lookup state of the catalog, building it if the catalog changed since it was published.
+// Views are built while holding the read lock, so a view is never published after a later change.
+func (po *Po) view() *poView {
+	if v, _ := po.published.Load().(*poView); v != nil {
+		return v
+	}
+
+	po.RLock()
+	defer po.RUnlock()
+
+	v := &poView{
+		translations:   make(map[string]*Translation, len(po.translations)),
+		contexts:       make(map[string]map[string]*Translation, len(po.contexts)),
+		defaultContext: po.defaultContext,
+		collapse:       po.collapse,
+		fuzzy:          po.fuzzy,
+		plural:         po.plural,
+	}
+
+	for k, t := range po.translations {
+		v.translations[k] = t
+	}
+
+	for ctx, entries := range po.contexts {
+		v.contexts[ctx] = make(map[string]*Translation, len(entries))
+		for k, t := range entries {
+			v.contexts[ctx][k] = t
+		}
+	}
+
+	po.published.Store(v)
+
+	return v
+}
+
+// changed discards the published lookup state, so the next lookup builds it again.
+// It has to be called while holding the lock, after changing any state used by lookups.
+func (po *Po) changed() {
+	po.published.Store((*poView)(nil))
+}
+
+// localeView is an immutable copy of the lookup state of a Locale object.
+// It's published after the Locale changes, so lookups read it without locking the Locale.
+type localeView struct {
+	domain           string
+	domains          map[string]*Po
+	overrides        map[string]*Po
+	pending          map[string]*lazyDomain
+	zeroForms        map[zeroKey]string
+	contextVars      map[string]map[string]interface{}
+	fallback         *Locale
+	verifyArgs       bool
+	autoCount        bool
+	rawWithoutVars   bool
+	lenientPlural    bool
+	missingFormat    string
+	missingHandler   func(dom, ctx, id string, n int)
+	missingCollector *MissingCollector
+}
+
+// view returns the published lookup state of the Locale, building it if the Locale changed since it was published.
+// Views are built while holding the read lock, so a view is never published after a later change.
+func (l *Locale) view() *localeView {
+	if v, _ := l.published.Load().(*localeView); v != nil {
+		return v
+	}
+
+	l.RLock()
+	defer l.RUnlock()
+
+	v := &localeView{
+		domain:           l.domain,
+		domains:          make(map[string]*Po, len(l.domains)),
+		overrides:        make(map[string]*Po, len(l.overrides)),
+		pending:          make(map[string]*lazyDomain, len(l.pending)),
+		zeroForms:        make(map[zeroKey]string, len(l.zeroForms)),
+		contextVars:      make(map[string]map[string]interface{}, len(l.contextVars)),
+		fallback:         l.fallback,
+		verifyArgs:       l.verifyArgs,
+		autoCount:        l.autoCount,
+		rawWithoutVars:   l.rawWithoutVars,
+		lenientPlural:    l.lenientPlural,
+		missingFormat:    l.missingFormat,
+		missingHandler:   l.missingHandler,
+		missingCollector: l.missingCollector,
+	}
+
+	for dom, po := range l.domains {
+		v.domains[dom] = po
+	}
+	for dom, po := range l.overrides {
+		v.overrides[dom] = po
+	}
+	for dom, d := range l.pending {
+		v.pending[dom] = d
+	}
+	for k, text := range l.zeroForms {
+		v.zeroForms[k] = text
+	}
+	for ctx, vars := range l.contextVars {
+		v.contextVars[ctx] = vars
+	}
+
+	l.published.Store(v)
+
+	return v
+}
+
+// changed discards the published lookup state, so the next lookup builds it again.
+// It has to be called while holding the lock, after changing any state used by lookups.
+func (l *Locale) changed() {
+	l.published.Store((*localeView)(nil))
+}
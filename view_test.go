@@ -0,0 +1,118 @@
+package gotext
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// viewLocale returns a Locale with a "default" domain holding the given amount of entries (n).
+func viewLocale(n int) *Locale {
+	po := new(Po)
+	for i := 0; i < n; i++ {
+		po.Set(fmt.Sprintf("Text %d", i), fmt.Sprintf("Texto %d", i))
+	}
+	po.SetN("One file", "%d files", 0, "Un archivo")
+	po.SetN("One file", "%d files", 1, "%d archivos")
+
+	l := NewLocale("/tmp", "es")
+	l.AttachDomain("default", po)
+
+	return l
+}
+
+func TestLocaleViewAllocs(t *testing.T) {
+	l := viewLocale(10)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if tr, po := l.findD("default", "One file"); tr == nil || po.pluralForm(2) != 1 {
+			t.Fatal("Expected translation")
+		}
+		l.GetDomain()
+	})
+	if allocs != 0 {
+		t.Errorf("Expected lookups without allocations but got %v", allocs)
+	}
+}
+
+func TestLocaleViewChanges(t *testing.T) {
+	l := viewLocale(10)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 200; j++ {
+				l.Get("Text 1")
+				l.GetN("One file", "%d files", j, j)
+			}
+		}()
+	}
+
+	// Change the catalog and settings while reading
+	for i := 0; i < 50; i++ {
+		l.Set("default", "Text 1", fmt.Sprintf("Texto cambiado %d", i))
+		l.SetMissingFormat("[%s]")
+		l.SetMissingFormat("")
+	}
+	wg.Wait()
+
+	// Published views follow every change
+	if tr := l.Get("Text 1"); tr != "Texto cambiado 49" {
+		t.Errorf("Expected 'Texto cambiado 49' but got '%s'", tr)
+	}
+
+	po := new(Po)
+	l.AttachDomain("extras", po)
+	if tr := l.GetD("extras", "Text 1"); tr != "Text 1" {
+		t.Errorf("Expected 'Text 1' but got '%s'", tr)
+	}
+
+	po.Set("Text 1", "Texto extra")
+	if tr := l.GetD("extras", "Text 1"); tr != "Texto extra" {
+		t.Errorf("Expected 'Texto extra' but got '%s'", tr)
+	}
+
+	l.SetDomain("extras")
+	if tr := l.Get("Text 1"); tr != "Texto extra" {
+		t.Errorf("Expected 'Texto extra' but got '%s'", tr)
+	}
+
+	po.SetCollapseWhitespace(true)
+	if tr := l.Get("Text  1"); tr != "Texto extra" {
+		t.Errorf("Expected 'Texto extra' but got '%s'", tr)
+	}
+}
+
+func BenchmarkLocaleGetD(b *testing.B) {
+	l := viewLocale(1000)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		l.GetD("default", "Text 500")
+	}
+}
+
+func BenchmarkLocaleGetNDParallel(b *testing.B) {
+	l := viewLocale(1000)
+	b.ReportAllocs()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.GetND("default", "One file", "%d files", 2, 2)
+		}
+	})
+}
+
+func BenchmarkLocaleGetNDCParallel(b *testing.B) {
+	l := viewLocale(1000)
+	b.ReportAllocs()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.GetNDC("default", "Open", "Open", 1, "Menu")
+		}
+	})
+}
@@ -0,0 +1,364 @@
+package gotext
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WriteOptions configures how a catalog is written in PO format.
+type WriteOptions struct {
+	// Sort entries by msgid, and by context for entries with the same msgid, like "msgcat --sort-output" does.
+	// Otherwise entries are written in the order they were parsed.
+	// The header entry is always written first.
+	Sort bool
+}
+
+/*
+Write writes the catalog in PO format to the given writer (w), using the given options.
+
+Strings are quoted and escaped following the PO format conventions.
+Strings containing newlines are written as multiline strings, breaking the lines after each newline.
+
+Example:
+
+    po := new(gotext.Po)
+    po.ParseFile("/path/to/po/file/translations.po")
+
+    f, _ := os.Create("/path/to/po/file/sorted.po")
+    defer f.Close()
+
+    po.Write(f, gotext.WriteOptions{Sort: true})
+
+*/
+func (po *Po) Write(w io.Writer, opts WriteOptions) error {
+	entries := po.snapshot()
+
+	// Sort entries
+	keys := make([]entryKey, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+
+		// Header first
+		if (a.id == "" && a.ctx == "") != (b.id == "" && b.ctx == "") {
+			return a.id == "" && a.ctx == ""
+		}
+
+		if opts.Sort {
+			if a.id != b.id {
+				return a.id < b.id
+			}
+			return a.ctx < b.ctx
+		}
+
+		return entries[a].seq < entries[b].seq
+	})
+
+	bw := bufio.NewWriter(w)
+
+	for i, k := range keys {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+
+		writeEntry(bw, k.ctx, entries[k])
+	}
+
+	// Obsolete entries go last
+	obsolete := po.Obsolete()
+	if opts.Sort {
+		sort.SliceStable(obsolete, func(i, j int) bool {
+			if obsolete[i].ID != obsolete[j].ID {
+				return obsolete[i].ID < obsolete[j].ID
+			}
+			return obsolete[i].Context < obsolete[j].Context
+		})
+	}
+
+	for i, t := range obsolete {
+		if i > 0 || len(keys) > 0 {
+			bw.WriteString("\n")
+		}
+
+		writeObsolete(bw, t)
+	}
+
+	return bw.Flush()
+}
+
+// MarshalText returns the catalog in PO format, written in the order the entries were parsed or added,
+// so it can be used wherever an encoding.TextMarshaler is accepted.
+// Parsing the returned content results in an equivalent catalog.
+func (po *Po) MarshalText() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := po.Write(&buf, WriteOptions{}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// DumpFile writes the catalog in PO format to the file at the given path (f), like MarshalText,
+// creating the file or truncating it if it exists. It returns the error if the file can't be written.
+func (po *Po) DumpFile(f string) error {
+	data, err := po.MarshalText()
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(f, data, 0644)
+}
+
+// NewPoTemplate returns an empty catalog holding the usual header entry of the PO templates (.pot files),
+// flagged as fuzzy and with placeholder values for the fields translators fill in, to be written with Write or DumpFile.
+func NewPoTemplate() *Po {
+	header := NewTranslation()
+	header.Flags = []string{"fuzzy"}
+	header.Trs[0] = Header{
+		"Project-Id-Version":        "PACKAGE VERSION",
+		"MIME-Version":              "1.0",
+		"Content-Type":              "text/plain; charset=UTF-8",
+		"Content-Transfer-Encoding": "8bit",
+		"Plural-Forms":              "nplurals=INTEGER; plural=EXPRESSION;",
+	}.String()
+
+	pot := new(Po)
+	pot.SetEntry(header)
+
+	return pot
+}
+
+// writeObsolete writes an obsolete catalog entry, with every line but the comments and flags commented out with "#~".
+func writeObsolete(w *bufio.Writer, t *Translation) {
+	var buf bytes.Buffer
+
+	bw := bufio.NewWriter(&buf)
+	writeEntry(bw, t.Context, t)
+	bw.Flush()
+
+	for _, l := range strings.SplitAfter(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if !strings.HasPrefix(l, "#") {
+			w.WriteString("#~ ")
+		}
+		w.WriteString(l)
+	}
+	w.WriteString("\n")
+}
+
+// writeEntry writes a single catalog entry in PO format, including its comments, references and flags.
+func writeEntry(w *bufio.Writer, ctx string, t *Translation) {
+	// Comments
+	for _, c := range t.Comments {
+		w.WriteString(strings.TrimSuffix("# "+c, " ") + "\n")
+	}
+	for _, c := range t.ExtractedComments {
+		w.WriteString(strings.TrimSuffix("#. "+c, " ") + "\n")
+	}
+
+	// References
+	if len(t.References) > 0 {
+		w.WriteString("#: " + strings.Join(t.References, " ") + "\n")
+	}
+
+	// Flags
+	if len(t.Flags) > 0 {
+		w.WriteString("#, " + strings.Join(t.Flags, ", ") + "\n")
+	}
+
+	if ctx != "" {
+		writeString(w, "msgctxt", ctx)
+	}
+
+	writeString(w, "msgid", t.ID)
+
+	if t.PluralID == "" {
+		writeString(w, "msgstr", t.Trs[0])
+
+		// Extra forms without plural id
+		for _, i := range sortedIndexes(t.Trs) {
+			if i != 0 {
+				writeString(w, "msgstr["+strconv.Itoa(i)+"]", t.Trs[i])
+			}
+		}
+
+		return
+	}
+
+	writeString(w, "msgid_plural", t.PluralID)
+
+	indexes := sortedIndexes(t.Trs)
+	if len(indexes) == 0 {
+		// Untranslated plural entries still need a msgstr
+		writeString(w, "msgstr[0]", "")
+	}
+
+	for _, i := range indexes {
+		writeString(w, "msgstr["+strconv.Itoa(i)+"]", t.Trs[i])
+	}
+}
+
+// writeString writes a keyword line with its quoted string (str).
+// Strings containing newlines (besides a trailing one) are split into continuation lines after each newline.
+func writeString(w *bufio.Writer, keyword, str string) {
+	w.WriteString(keyword + " ")
+
+	lines := strings.SplitAfter(str, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if len(lines) <= 1 {
+		w.WriteString(quote(str) + "\n")
+		return
+	}
+
+	w.WriteString("\"\"\n")
+	for _, l := range lines {
+		w.WriteString(quote(l) + "\n")
+	}
+}
+
+// quote returns the given string double-quoted and escaped following the PO format (C string) conventions.
+// Unlike strconv.Quote, printable and non-ASCII characters are never escaped.
+func quote(str string) string {
+	buf := make([]byte, 0, len(str)+2)
+	buf = append(buf, '"')
+
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+
+		switch c {
+		case '"':
+			buf = append(buf, '\\', '"')
+		case '\\':
+			buf = append(buf, '\\', '\\')
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\t':
+			buf = append(buf, '\\', 't')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case '\a':
+			buf = append(buf, '\\', 'a')
+		case '\b':
+			buf = append(buf, '\\', 'b')
+		case '\f':
+			buf = append(buf, '\\', 'f')
+		case '\v':
+			buf = append(buf, '\\', 'v')
+		default:
+			if c < 0x20 || c == 0x7f {
+				// Octal escape for other control characters
+				buf = append(buf, '\\', '0'+c>>6, '0'+(c>>3)&7, '0'+c&7)
+				continue
+			}
+			buf = append(buf, c)
+		}
+	}
+
+	return string(append(buf, '"'))
+}
+
+/*
+PoWriter writes catalog entries in PO format one at a time,
+so large catalogs can be generated without holding all their entries in memory.
+It uses the same quoting, escaping and multiline rules as Po.Write.
+
+Example:
+
+    w := gotext.NewPoWriter(f, gotext.Header{
+        "Language":     "es",
+        "Content-Type": "text/plain; charset=UTF-8",
+    })
+
+    for _, t := range entries {
+        if err := w.Write(t); err != nil {
+            return err
+        }
+    }
+
+    return w.Close()
+
+*/
+type PoWriter struct {
+	ew *errWriter
+	bw *bufio.Writer
+
+	// Amount of entries written, including the header.
+	count int
+}
+
+// NewPoWriter creates a PoWriter that writes to w, starting with the given header entry.
+// No header entry is written when the header is nil.
+// Errors writing the header are returned by the following Write or Close calls.
+func NewPoWriter(w io.Writer, header Header) *PoWriter {
+	ew := &errWriter{w: w}
+	pw := &PoWriter{
+		ew: ew,
+		bw: bufio.NewWriter(ew),
+	}
+
+	if header != nil {
+		t := NewTranslation()
+		t.Trs[0] = header.String()
+
+		writeEntry(pw.bw, "", t)
+		pw.count++
+	}
+
+	return pw
+}
+
+// Write writes a single entry (t), using its Context field as msgctxt.
+// Entries are buffered, so errors from the underlying writer may be returned by a later Write or Close call.
+func (pw *PoWriter) Write(t *Translation) error {
+	if pw.ew.err != nil {
+		return pw.ew.err
+	}
+
+	if pw.count > 0 {
+		pw.bw.WriteString("\n")
+	}
+
+	writeEntry(pw.bw, t.Context, t)
+	pw.count++
+
+	return pw.ew.err
+}
+
+// Close flushes the buffered entries to the underlying writer. It doesn't close the underlying writer.
+func (pw *PoWriter) Close() error {
+	if err := pw.bw.Flush(); err != nil {
+		return err
+	}
+
+	return pw.ew.err
+}
+
+// errWriter keeps the first error returned by the underlying writer (w).
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+// Write writes p to the underlying writer unless a previous write failed.
+func (ew *errWriter) Write(p []byte) (int, error) {
+	if ew.err != nil {
+		return 0, ew.err
+	}
+
+	n, err := ew.w.Write(p)
+	if err != nil {
+		ew.err = err
+	}
+
+	return n, err
+}
@@ -0,0 +1,443 @@
+package gotext
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestPoWrite(t *testing.T) {
+	// Set PO content
+	str := `msgid ""
+msgstr ""
+"Language: es\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+
+msgid "Zeta"
+msgstr "Último"
+
+#, args:1
+msgid "One with var: %s"
+msgid_plural "Several with vars: %s"
+msgstr[0] "This one is the singular: %s"
+msgstr[1] "This one is the plural: %s"
+
+msgctxt "Ctx"
+msgid "Alpha"
+msgstr "Primero en un contexto"
+
+msgid "Alpha"
+msgstr "Primero"
+
+msgid "Escaped \"quotes\"\tand tabs"
+msgstr "Comillas \"escapadas\"\ty tabs\\"
+
+msgid "Multiline\ntext"
+msgstr ""
+"Texto\n"
+"en varias\n"
+"lineas"
+
+msgid "Untranslated"
+msgstr ""
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	// Test original order
+	var buf bytes.Buffer
+	err := po.Write(&buf, WriteOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	expected := `msgid ""
+msgstr ""
+"Language: es\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+
+msgid "Zeta"
+msgstr "Último"
+
+#, args:1
+msgid "One with var: %s"
+msgid_plural "Several with vars: %s"
+msgstr[0] "This one is the singular: %s"
+msgstr[1] "This one is the plural: %s"
+
+msgctxt "Ctx"
+msgid "Alpha"
+msgstr "Primero en un contexto"
+
+msgid "Alpha"
+msgstr "Primero"
+
+msgid "Escaped \"quotes\"\tand tabs"
+msgstr "Comillas \"escapadas\"\ty tabs\\"
+
+msgid ""
+"Multiline\n"
+"text"
+msgstr ""
+"Texto\n"
+"en varias\n"
+"lineas"
+
+msgid "Untranslated"
+msgstr ""
+`
+
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, buf.String())
+	}
+
+	// Test round trip
+	parsed := new(Po)
+	parsed.Parse(buf.String())
+
+	if d := Diff(po, parsed); d != "" {
+		t.Errorf("Expected written catalog to be equivalent, but got diff:\n%s", d)
+	}
+
+	// Test sorted output
+	buf.Reset()
+	err = po.Write(&buf, WriteOptions{Sort: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	expected = `msgid ""
+msgstr ""
+"Language: es\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+
+msgid "Alpha"
+msgstr "Primero"
+
+msgctxt "Ctx"
+msgid "Alpha"
+msgstr "Primero en un contexto"
+
+msgid "Escaped \"quotes\"\tand tabs"
+msgstr "Comillas \"escapadas\"\ty tabs\\"
+
+msgid ""
+"Multiline\n"
+"text"
+msgstr ""
+"Texto\n"
+"en varias\n"
+"lineas"
+
+#, args:1
+msgid "One with var: %s"
+msgid_plural "Several with vars: %s"
+msgstr[0] "This one is the singular: %s"
+msgstr[1] "This one is the plural: %s"
+
+msgid "Untranslated"
+msgstr ""
+
+msgid "Zeta"
+msgstr "Último"
+`
+
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, buf.String())
+	}
+}
+
+func TestPoMarshalText(t *testing.T) {
+	// Set PO content
+	str := `msgid ""
+msgstr ""
+"Language: es\n"
+
+msgid "My text"
+msgstr "Mi texto"
+
+msgid "Old text"
+msgstr "Texto viejo"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	// Update an entry and add new ones
+	e := po.GetEntry("My text")
+	e.Trs[0] = "Mi \"nuevo\" texto"
+	po.SetEntry(e)
+
+	e = NewTranslation()
+	e.Context = "Ctx"
+	e.ID = "One file"
+	e.PluralID = "%d files"
+	e.Trs[0] = "Un archivo"
+	e.Trs[1] = "%d archivos\n"
+	po.SetEntry(e)
+
+	// Later changes don't affect the catalog
+	e.Trs[0] = "Changed"
+
+	data, err := po.MarshalText()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	expected := `msgid ""
+msgstr "Language: es\n"
+
+msgid "My text"
+msgstr "Mi \"nuevo\" texto"
+
+msgid "Old text"
+msgstr "Texto viejo"
+
+msgctxt "Ctx"
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Un archivo"
+msgstr[1] "%d archivos\n"
+`
+	if string(data) != expected {
+		t.Errorf("Unexpected content:\n%s", data)
+	}
+
+	if err := AssertRoundTrip(po); err != nil {
+		t.Errorf("Unexpected round trip error: %s", err.Error())
+	}
+}
+
+func TestPoWriteComments(t *testing.T) {
+	// Set PO content
+	str := `# Translator comment
+#
+#. Extracted comment
+#: main.go:12 util.go:30
+#: templates/index.html:4
+#, fuzzy
+msgid "My text"
+msgstr "Mi texto"
+
+# Obsolete comment
+#~ msgid "Old text"
+#~ msgstr "Texto viejo"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	data, err := po.MarshalText()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	expected := `# Translator comment
+#
+#. Extracted comment
+#: main.go:12 util.go:30 templates/index.html:4
+#, fuzzy
+msgid "My text"
+msgstr "Mi texto"
+
+# Obsolete comment
+#~ msgid "Old text"
+#~ msgstr "Texto viejo"
+`
+	if string(data) != expected {
+		t.Errorf("Unexpected content:\n%s", data)
+	}
+}
+
+func TestPoDumpFile(t *testing.T) {
+	// Set PO content
+	str := `msgid ""
+msgstr "Language: es\n"
+
+# Translator comment
+#: main.go:12
+#, c-format
+msgid "My text"
+msgstr "Mi texto"
+
+msgid "Old text"
+msgstr "Texto viejo"
+`
+
+	po := new(Po)
+	po.Parse(str)
+
+	// Update entries and add new ones
+	po.Set("My text", "Mi nuevo texto")
+	po.SetN("One file", "%d files", 0, "Un archivo")
+	po.SetN("One file", "%d files", 1, "%d archivos")
+	po.SetC("Open", "Menu", "Abrir")
+	po.SetNC("Open", "", 0, "Menu", "Abrir archivo")
+
+	filename := path.Clean("/tmp" + string(os.PathSeparator) + "dump.po")
+	if err := po.DumpFile(filename); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Can't read test file: %s", err.Error())
+	}
+
+	expected := `msgid ""
+msgstr "Language: es\n"
+
+# Translator comment
+#: main.go:12
+#, c-format
+msgid "My text"
+msgstr "Mi nuevo texto"
+
+msgid "Old text"
+msgstr "Texto viejo"
+
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Un archivo"
+msgstr[1] "%d archivos"
+
+msgctxt "Menu"
+msgid "Open"
+msgstr "Abrir archivo"
+`
+	if string(data) != expected {
+		t.Errorf("Unexpected content:\n%s", data)
+	}
+
+	// Read it back
+	dumped := new(Po)
+	dumped.ParseFile(filename)
+
+	if tr := dumped.GetN("One file", "%d files", 3, 3); tr != "3 archivos" {
+		t.Errorf("Expected '3 archivos' but got '%s'", tr)
+	}
+
+	// Unwritable path
+	if err := po.DumpFile("/tmp/missing/dir/dump.po"); err == nil {
+		t.Error("Expected error writing to a missing directory")
+	}
+}
+
+func TestQuote(t *testing.T) {
+	for str, expected := range map[string]string{
+		"":               `""`,
+		"Plain":          `"Plain"`,
+		"ñandú":          `"ñandú"`,
+		"Say \"hi\"":     `"Say \"hi\""`,
+		"Back\\slash":    `"Back\\slash"`,
+		"Bell\a\x01\x7f": `"Bell\a\001\177"`,
+		"Line\r\n":       `"Line\r\n"`,
+		"Form\f\v\b":     `"Form\f\v\b"`,
+		"Zero​ width":    "\"Zero​ width\"",
+	} {
+		q := quote(str)
+		if q != expected {
+			t.Errorf("Expected %s but got %s", expected, q)
+		}
+
+		// Test it can be parsed back
+		po := new(Po)
+		po.Parse("msgid \"Test\"\nmsgstr " + q)
+		if tr := po.Get("Test"); tr != str {
+			t.Errorf("Expected '%s' to be parsed back, but got '%s'", str, tr)
+		}
+	}
+}
+
+func TestPoWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	w := NewPoWriter(&buf, Header{
+		"X-Generator":  "gotext",
+		"Content-Type": "text/plain; charset=UTF-8",
+		"Language":     "es",
+	})
+
+	// Write entries
+	tr := NewTranslation()
+	tr.ID = "My text"
+	tr.Trs[0] = "Translated text"
+	if err := w.Write(tr); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	tr = NewTranslation()
+	tr.Context = "Ctx"
+	tr.ID = "%d file"
+	tr.PluralID = "%d files"
+	tr.Flags = []string{"c-format"}
+	tr.Trs[0] = "%d archivo"
+	tr.Trs[1] = "%d archivos"
+	if err := w.Write(tr); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	expected := `msgid ""
+msgstr ""
+"Language: es\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+"X-Generator: gotext\n"
+
+msgid "My text"
+msgstr "Translated text"
+
+#, c-format
+msgctxt "Ctx"
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d archivo"
+msgstr[1] "%d archivos"
+`
+
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, buf.String())
+	}
+
+	// Test it parses back
+	po := new(Po)
+	po.Parse(buf.String())
+
+	if s := po.GetNC("%d file", "%d files", 2, "Ctx", 2); s != "2 archivos" {
+		t.Errorf("Expected '2 archivos' but got '%s'", s)
+	}
+
+	// Test without header
+	buf.Reset()
+	w = NewPoWriter(&buf, nil)
+	tr = NewTranslation()
+	tr.ID = "My text"
+	w.Write(tr)
+	w.Close()
+
+	if buf.String() != "msgid \"My text\"\nmsgstr \"\"\n" {
+		t.Errorf("Unexpected output without header:\n%s", buf.String())
+	}
+
+	// Test writer errors
+	w = NewPoWriter(failingWriter{}, Header{"Language": "es"})
+	if err := w.Close(); err == nil {
+		t.Error("Expected an error from a failing writer")
+	}
+	if err := w.Write(tr); err == nil {
+		t.Error("Expected an error writing after a failure")
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
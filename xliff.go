@@ -0,0 +1,313 @@
+package gotext
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"strings"
+)
+
+/*
+XLIFF parses the content of XLIFF 1.2 and 2.0 catalogs, the XML exchange format used by translation vendors
+and computer-assisted translation tools, and provides the same translation methods as Po.
+
+Example:
+
+    import "github.com/leonelquinteros/gotext"
+
+    func main() {
+        // Create xliff object
+        x := new(gotext.XLIFF)
+
+        // Parse .xlf file
+        x.ParseFile("/path/to/xliff/file/translations.xlf")
+
+        // Get translation
+        println(x.Get("Translate this"))
+    }
+
+Each translation unit ("trans-unit" on XLIFF 1.2, "unit" on 2.0) is an entry whose source is the msgid
+and whose target is the translation. Units without a target, or with an empty one, aren't loaded,
+so lookups return the source strings.
+The segments of XLIFF 2.0 units are joined, and the text of inline elements is kept, without their markup.
+
+Contexts are read from the notes with a "msgctxt" category (2.0) or origin ("from" attribute on 1.2),
+and from the XLIFF 1.2 "x-gettext-msgctxt" context elements. Other notes are loaded as extracted comments.
+Plural entries are groups of units with the "x-gettext-plurals" restype (1.2) or the "x-gettext:plurals" type (2.0),
+holding a unit for each plural form, in order: the sources of the first two units are the msgid and msgid_plural,
+and their targets the translated forms, as written by the gettext to XLIFF converters:
+
+    <group restype="x-gettext-plurals">
+        <trans-unit id="1[0]"><source>One file</source><target>Un archivo</target></trans-unit>
+        <trans-unit id="1[1]"><source>%d files</source><target>%d archivos</target></trans-unit>
+    </group>
+
+A unit with an empty source holds the header, in the same format as the PO header entry, to set the Plural-Forms rule.
+
+Like Po, badly formatted content is ignored: content that isn't a valid XLIFF catalog loads no translations at all.
+*/
+type XLIFF struct {
+	Po
+}
+
+// ParseFile tries to read the file by its provided path (f) and parse its content as a .xlf file.
+func (x *XLIFF) ParseFile(f string) {
+	data, err := ioutil.ReadFile(f)
+	if err != nil {
+		return
+	}
+
+	x.Parse(data)
+}
+
+// Parse loads the translations specified in the provided XLIFF content (buf).
+func (x *XLIFF) Parse(buf []byte) {
+	x.Po.parseXLIFF(buf)
+}
+
+// ParseReader reads the XLIFF content from the provided reader (r) and parses it, like ParseFile.
+// The content is parsed only if it's read completely, otherwise the read error is returned.
+func (x *XLIFF) ParseReader(r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	x.Parse(data)
+
+	return nil
+}
+
+// ParseFS reads the file at the given path (name) of the given file system (fsys) and parses it as a .xlf file,
+// like Po.ParseFS. It returns the error if the file can't be read or isn't a valid XLIFF catalog.
+func (x *XLIFF) ParseFS(fsys fs.FS, name string) error {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+
+	return x.Po.parseXLIFF(data)
+}
+
+// xliffUnit is a translation unit of an XLIFF catalog.
+type xliffUnit struct {
+	ctx       string
+	source    string
+	target    string
+	hasTarget bool
+	notes     []string
+}
+
+// translation returns the translation for the unit, or nil if it doesn't have a target.
+func (u *xliffUnit) translation() *Translation {
+	if !u.hasTarget {
+		return nil
+	}
+
+	tr := NewTranslation()
+	tr.Context = u.ctx
+	tr.ID = u.source
+	tr.Trs[0] = u.target
+	tr.ExtractedComments = u.notes
+
+	return tr
+}
+
+// xliffPlural returns the translation for the given units of a plural group, or nil if none of them has a target.
+func xliffPlural(units []*xliffUnit) *Translation {
+	if len(units) == 0 {
+		return nil
+	}
+
+	tr := NewTranslation()
+	tr.Context = units[0].ctx
+	tr.ID = units[0].source
+	tr.PluralID = units[0].source
+	if len(units) > 1 {
+		tr.PluralID = units[1].source
+	}
+
+	for i, u := range units {
+		tr.ExtractedComments = append(tr.ExtractedComments, u.notes...)
+		if u.hasTarget {
+			tr.Trs[i] = u.target
+		}
+	}
+
+	if len(tr.Trs) == 0 {
+		return nil
+	}
+
+	return tr
+}
+
+// xliffAttr returns the value of the attribute with the given local name on the element (e).
+func xliffAttr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+
+	return ""
+}
+
+// parseXLIFF loads the translations from the given XLIFF 1.2 or 2.0 catalog content (buf).
+// The whole content is checked before storing any translation, so it returns an error without changes
+// if it isn't a valid XLIFF catalog.
+func (po *Po) parseXLIFF(buf []byte) error {
+	var trs []*Translation
+
+	// Open groups, whether they hold plural forms, and their units
+	var plural []bool
+	var groups [][]*xliffUnit
+
+	var unit *xliffUnit
+
+	// Element whose text is being read, its depth counting inline elements, and its text
+	field, depth := "", 0
+	var text strings.Builder
+
+	root := false
+	dec := xml.NewDecoder(bytes.NewReader(buf))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("xliff: %s", err.Error())
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			// Inline elements
+			if depth > 0 {
+				depth++
+				continue
+			}
+
+			if !root {
+				if t.Name.Local != "xliff" {
+					return errors.New("xliff: catalog root must be an xliff element")
+				}
+				if v := xliffAttr(t, "version"); v != "1.2" && !strings.HasPrefix(v, "2.") {
+					return fmt.Errorf("xliff: unsupported version %q", v)
+				}
+				root = true
+				continue
+			}
+
+			switch t.Name.Local {
+			case "group":
+				kind := xliffAttr(t, "restype") + xliffAttr(t, "type")
+				plural = append(plural, kind == "x-gettext-plurals" || kind == "x-gettext:plurals")
+				groups = append(groups, nil)
+
+			case "trans-unit", "unit":
+				if unit != nil {
+					return errors.New("xliff: nested unit")
+				}
+				unit = &xliffUnit{}
+
+			case "source", "target":
+				if unit != nil {
+					field, depth = t.Name.Local, 1
+				}
+
+			case "note":
+				if unit != nil {
+					field, depth = "note", 1
+					if xliffAttr(t, "category") == "msgctxt" || xliffAttr(t, "from") == "msgctxt" {
+						field = "msgctxt"
+					}
+				}
+
+			case "context":
+				if unit != nil && xliffAttr(t, "context-type") == "x-gettext-msgctxt" {
+					field, depth = "msgctxt", 1
+				}
+
+			// Alternative translations and segmented sources aren't part of the unit
+			case "alt-trans", "seg-source", "originalData":
+				if err := dec.Skip(); err != nil {
+					return fmt.Errorf("xliff: %s", err.Error())
+				}
+			}
+
+		case xml.CharData:
+			if depth > 0 {
+				text.Write(t)
+			}
+
+		case xml.EndElement:
+			if depth > 1 {
+				depth--
+				continue
+			}
+
+			if depth == 1 {
+				str := text.String()
+				switch field {
+				case "source":
+					unit.source += str
+				case "target":
+					unit.target += str
+					unit.hasTarget = unit.target != ""
+				case "msgctxt":
+					unit.ctx = str
+				case "note":
+					unit.notes = append(unit.notes, strings.TrimSpace(str))
+				}
+
+				field, depth = "", 0
+				text.Reset()
+				continue
+			}
+
+			switch t.Name.Local {
+			case "trans-unit", "unit":
+				if unit == nil {
+					continue
+				}
+				if n := len(plural); n > 0 && plural[n-1] {
+					groups[n-1] = append(groups[n-1], unit)
+				} else if tr := unit.translation(); tr != nil {
+					trs = append(trs, tr)
+				}
+				unit = nil
+
+			case "group":
+				n := len(plural)
+				if plural[n-1] {
+					if tr := xliffPlural(groups[n-1]); tr != nil {
+						trs = append(trs, tr)
+					}
+				}
+				plural, groups = plural[:n-1], groups[:n-1]
+			}
+		}
+	}
+
+	if !root {
+		return errors.New("xliff: catalog root must be an xliff element")
+	}
+
+	// Init storage
+	po.initParse()
+
+	// Keep track of the parsed content
+	po.Lock()
+	po.fingerprint = SourceFingerprint(po.fingerprint + string(buf))
+	po.Unlock()
+
+	for _, tr := range trs {
+		po.save(tr.Context, tr)
+	}
+
+	return nil
+}
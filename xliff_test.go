@@ -0,0 +1,233 @@
+package gotext
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"testing/fstest"
+)
+
+func TestXLIFF12(t *testing.T) {
+	// Set XLIFF content
+	str := `<?xml version="1.0" encoding="UTF-8"?>
+<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">
+  <file original="messages.po" source-language="en" target-language="pl" datatype="po">
+    <header>
+      <note>Header notes aren't entries</note>
+    </header>
+    <body>
+      <trans-unit id="0">
+        <source></source>
+        <target>Plural-Forms: nplurals=3; plural=(n==1 ? 0 : n%10>=2 &amp;&amp; n%10&lt;=4 &amp;&amp; (n%100&lt;10 || n%100>=20) ? 1 : 2);\n</target>
+      </trans-unit>
+      <trans-unit id="1" resname="greeting">
+        <source>Hello, %s</source>
+        <target state="translated">Cześć, %s</target>
+        <note from="developer">Shown on the home page</note>
+        <alt-trans><source>Hello, %s</source><target>Witaj, %s</target></alt-trans>
+      </trans-unit>
+      <trans-unit id="2">
+        <source>Open</source>
+        <target>Otwórz</target>
+        <context-group name="po-entry" purpose="information">
+          <context context-type="x-gettext-msgctxt">Menu</context>
+        </context-group>
+      </trans-unit>
+      <trans-unit id="3">
+        <source>Close</source>
+        <target>Zamknij</target>
+        <note from="msgctxt">Menu</note>
+      </trans-unit>
+      <trans-unit id="4">
+        <source>Click <g id="1">here</g></source>
+        <target>Kliknij <g id="1">tutaj</g></target>
+      </trans-unit>
+      <trans-unit id="5">
+        <source>Untranslated</source>
+        <target/>
+      </trans-unit>
+      <group id="6" restype="x-gettext-plurals">
+        <trans-unit id="6[0]"><source>%d file</source><target>%d plik</target></trans-unit>
+        <trans-unit id="6[1]"><source>%d files</source><target>%d pliki</target></trans-unit>
+        <trans-unit id="6[2]"><source>%d files</source><target>%d plików</target></trans-unit>
+      </group>
+    </body>
+  </file>
+</xliff>`
+
+	x := new(XLIFF)
+	x.Parse([]byte(str))
+
+	if tr := x.Get("Hello, %s", "Ana"); tr != "Cześć, Ana" {
+		t.Errorf("Expected 'Cześć, Ana' but got '%s'", tr)
+	}
+	if tr := x.GetEntry("Hello, %s"); tr == nil || len(tr.ExtractedComments) != 1 || tr.ExtractedComments[0] != "Shown on the home page" {
+		t.Errorf("Unexpected entry: %v", tr)
+	}
+	if tr := x.GetC("Open", "Menu"); tr != "Otwórz" {
+		t.Errorf("Expected 'Otwórz' but got '%s'", tr)
+	}
+	if tr := x.GetC("Close", "Menu"); tr != "Zamknij" {
+		t.Errorf("Expected 'Zamknij' but got '%s'", tr)
+	}
+	if x.GetEntry("Open") != nil {
+		t.Error("Unexpected context-less 'Open' entry")
+	}
+	if tr := x.Get("Click here"); tr != "Kliknij tutaj" {
+		t.Errorf("Expected 'Kliknij tutaj' but got '%s'", tr)
+	}
+	if x.GetEntry("Untranslated") != nil {
+		t.Error("Unexpected entry without target")
+	}
+
+	// Plural forms use the header rule
+	plurals := map[int]string{1: "1 plik", 3: "3 pliki", 5: "5 plików"}
+	for n, expected := range plurals {
+		if tr := x.GetN("%d file", "%d files", n, n); tr != expected {
+			t.Errorf("Expected '%s' but got '%s'", expected, tr)
+		}
+	}
+	if tr := x.GetEntry("%d file"); tr == nil || tr.PluralID != "%d files" {
+		t.Errorf("Unexpected plural entry: %v", tr)
+	}
+
+	// Invalid content is ignored
+	invalid := []string{
+		`<po><trans-unit><source>Open</source><target>Otwórz</target></trans-unit></po>`,
+		`<xliff version="3.0"><file><body><trans-unit><source>Open</source><target>Otwórz</target></trans-unit></body></file></xliff>`,
+		`<xliff version="1.2"><file><body><trans-unit><source>Open</source><target>Otwórz</target></trans-unit></body></file>`,
+		`<xliff version="1.2"><file><body><trans-unit id="a"><trans-unit id="b"/><target>x</target></trans-unit></body></file></xliff>`,
+		`<xliff version="1.2"><file><body><group restype="x-gettext-plurals"><trans-unit id="a"><trans-unit id="b"/><target>x</target></trans-unit></group></body></file></xliff>`,
+		``,
+	}
+	for _, str := range invalid {
+		x := new(XLIFF)
+		x.Parse([]byte(str))
+
+		if tr := x.Get("Open"); tr != "Open" {
+			t.Errorf("Expected no translations for %s but got '%s'", str, tr)
+		}
+
+		if err := x.ParseFS(fstest.MapFS{"pl.xlf": {Data: []byte(str)}}, "pl.xlf"); err == nil {
+			t.Errorf("Expected error parsing %s", str)
+		}
+	}
+}
+
+func TestXLIFF20(t *testing.T) {
+	// Set XLIFF content
+	str := `<?xml version="1.0" encoding="UTF-8"?>
+<xliff version="2.0" xmlns="urn:oasis:names:tc:xliff:document:2.0" srcLang="en" trgLang="es">
+  <file id="f1">
+    <unit id="1">
+      <notes>
+        <note category="msgctxt">Menu</note>
+        <note>Menu entry</note>
+      </notes>
+      <segment><source>Open</source><target>Abrir</target></segment>
+    </unit>
+    <unit id="2">
+      <segment><source>First sentence. </source><target>Primera frase. </target></segment>
+      <segment><source>Second <pc id="1">one</pc>.</source><target>Segunda <pc id="1">frase</pc>.</target></segment>
+      <originalData><data id="d1">&lt;b&gt;</data></originalData>
+    </unit>
+    <group id="g1" type="x-gettext:plurals">
+      <unit id="3[0]"><segment><source>One file</source><target>Un archivo</target></segment></unit>
+      <unit id="3[1]"><segment><source>%d files</source><target>%d archivos</target></segment></unit>
+    </group>
+    <group id="g2">
+      <unit id="4"><segment><source>Save</source><target>Guardar</target></segment></unit>
+    </group>
+  </file>
+</xliff>`
+
+	x := new(XLIFF)
+	if err := x.ParseFS(fstest.MapFS{"es.xlf": {Data: []byte(str)}}, "es.xlf"); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	tests := []struct{ tr, expected string }{
+		{x.GetC("Open", "Menu"), "Abrir"},
+		{x.Get("First sentence. Second one."), "Primera frase. Segunda frase."},
+		{x.GetN("One file", "%d files", 1), "Un archivo"},
+		{x.GetN("One file", "%d files", 4, 4), "4 archivos"},
+		{x.Get("Save"), "Guardar"},
+	}
+	for _, test := range tests {
+		if test.tr != test.expected {
+			t.Errorf("Expected '%s' but got '%s'", test.expected, test.tr)
+		}
+	}
+
+	if tr := x.GetEntryC("Open", "Menu"); tr == nil || len(tr.ExtractedComments) != 1 || tr.ExtractedComments[0] != "Menu entry" {
+		t.Errorf("Unexpected entry: %v", tr)
+	}
+}
+
+func TestLocaleAddDomainXLIFF(t *testing.T) {
+	// Create Locale directory
+	dirname := path.Clean("/tmp" + string(os.PathSeparator) + "it")
+	err := os.MkdirAll(dirname, os.ModePerm)
+	if err != nil {
+		t.Fatalf("Can't create test directory: %s", err.Error())
+	}
+
+	write := func(name, str string) {
+		if err := ioutil.WriteFile(path.Join(dirname, name), []byte(str), 0644); err != nil {
+			t.Fatalf("Can't write to test file: %s", err.Error())
+		}
+	}
+
+	xliff := func(target string) string {
+		return `<xliff version="1.2"><file><body><trans-unit id="1"><source>My text</source><target>` + target + `</target></trans-unit></body></file></xliff>`
+	}
+
+	write("vendor.xlf", xliff("Il mio testo"))
+	write("delivery.xliff", xliff("Consegna"))
+	write("both.po", "msgid \"My text\"\nmsgstr \"Testo PO\"\n")
+	write("both.xlf", xliff("Testo XLIFF"))
+
+	l := NewLocale("/tmp", "it")
+	l.AddDomain("vendor")
+	l.AddDomain("delivery")
+	l.AddDomain("both")
+
+	tests := map[string]string{
+		"vendor":   "Il mio testo",
+		"delivery": "Consegna",
+		"both":     "Testo PO",
+	}
+	for dom, expected := range tests {
+		if tr := l.GetD(dom, "My text"); tr != expected {
+			t.Errorf("Expected '%s' for '%s' but got '%s'", expected, dom, tr)
+		}
+	}
+
+	// Explicit format
+	if err := l.AddDomainFormat("both", "xliff"); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if tr := l.GetD("both", "My text"); tr != "Testo XLIFF" {
+		t.Errorf("Expected 'Testo XLIFF' but got '%s'", tr)
+	}
+
+	if err := l.AddDomainFormat("both", "yaml"); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+	if err := l.AddDomainFormat("vendor", "json"); err == nil {
+		t.Error("Expected error for missing file")
+	}
+	if tr := l.GetD("vendor", "My text"); tr != "Il mio testo" {
+		t.Errorf("Expected 'Il mio testo' but got '%s'", tr)
+	}
+
+	// Reload XLIFF files
+	write("both.xlf", xliff("Nuovo testo"))
+	if errs := l.Reload(); len(errs) != 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	if tr := l.GetD("both", "My text"); tr != "Nuovo testo" {
+		t.Errorf("Expected 'Nuovo testo' but got '%s'", tr)
+	}
+}